@@ -0,0 +1,253 @@
+// Package bundle exports MCP server definitions to a portable file and
+// imports them into another configuration.
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// Version is the current version of the bundle format.
+const Version = 1
+
+// Server is a portable server definition.
+type Server struct {
+	Name    string            `json:"name"`
+	Scope   string            `json:"scope"`
+	Project string            `json:"project,omitempty"`
+	Type    string            `json:"type,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// Bundle is the content of an export file.
+type Bundle struct {
+	Version int      `json:"version"`
+	Servers []Server `json:"servers"`
+}
+
+// ExportOptions control how secrets are handled on export.
+type ExportOptions struct {
+	// KeepEnv lists env variable names whose values are exported as they are.
+	KeepEnv []string
+}
+
+// placeholderPattern matches ${NAME} placeholders.
+var placeholderPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// secretNamePattern matches names of arguments and query parameters that usually hold secrets.
+var secretNamePattern = regexp.MustCompile(`(?i)(token|secret|password|passwd|api[-_]?key|apikey|authorization|credential|access[-_]?key)`)
+
+// Export converts servers into a bundle. Env and header values are replaced
+// by ${NAME} placeholders, as are secret-looking URL query parameters and
+// --token style arguments, so the bundle can be shared without secrets.
+func Export(servers []types.MCPServer, opts ExportOptions) *Bundle {
+	keep := make(map[string]bool, len(opts.KeepEnv))
+	for _, name := range opts.KeepEnv {
+		keep[name] = true
+	}
+
+	b := &Bundle{Version: Version, Servers: make([]Server, 0, len(servers))}
+	for i := range servers {
+		s := &servers[i]
+		out := Server{
+			Name:    s.Name,
+			Scope:   s.Scope.String(),
+			Project: s.ProjectPath,
+			Type:    s.TypeStr,
+			Command: s.Command,
+			Args:    redactArgs(s.Args),
+			URL:     redactURL(s.URL),
+		}
+		if len(s.Env) > 0 {
+			out.Env = make(map[string]string, len(s.Env))
+			for name, value := range s.Env {
+				if keep[name] || isPlaceholder(value) {
+					out.Env[name] = value
+				} else {
+					out.Env[name] = placeholder(name)
+				}
+			}
+		}
+		if len(s.Headers) > 0 {
+			out.Headers = make(map[string]string, len(s.Headers))
+			for name, value := range s.Headers {
+				if isPlaceholder(value) {
+					out.Headers[name] = value
+				} else {
+					out.Headers[name] = placeholder(name)
+				}
+			}
+		}
+		b.Servers = append(b.Servers, out)
+	}
+	return b
+}
+
+// Write writes a bundle as indented JSON.
+func Write(path string, b *Bundle) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle: %w", err)
+	}
+	data = append(data, '\n')
+
+	if path == "" || path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	// The bundle holds no secrets, but may still reveal project paths
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// Load reads and validates a bundle file.
+func Load(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	b := &Bundle{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("unsupported bundle version %d in %s (expected %d)", b.Version, path, Version)
+	}
+
+	for i := range b.Servers {
+		s := &b.Servers[i]
+		switch {
+		case s.Name == "":
+			return nil, fmt.Errorf("invalid bundle %s: servers[%d]: name is required", path, i)
+		case s.Scope != types.ScopeGlobal.String() && s.Scope != types.ScopeProject.String():
+			return nil, fmt.Errorf("invalid bundle %s: servers[%d] (%s): invalid scope %q", path, i, s.Name, s.Scope)
+		case s.Scope == types.ScopeProject.String() && s.Project == "":
+			return nil, fmt.Errorf("invalid bundle %s: servers[%d] (%s): project is required for project scope", path, i, s.Name)
+		}
+	}
+	return b, nil
+}
+
+// toMCPServer converts a bundle entry into a server configuration.
+func (s *Server) toMCPServer() types.MCPServer {
+	server := types.MCPServer{
+		Name:    s.Name,
+		Type:    types.ParseServerType(s.Type),
+		TypeStr: s.Type,
+		Command: s.Command,
+		Args:    s.Args,
+		Env:     s.Env,
+		URL:     s.URL,
+		Headers: s.Headers,
+		Scope:   types.ScopeGlobal,
+	}
+	if s.Scope == types.ScopeProject.String() {
+		server.Scope = types.ScopeProject
+		server.ProjectPath = s.Project
+	}
+	return server
+}
+
+// placeholder returns the ${NAME} placeholder for a variable or header name.
+func placeholder(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(name) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return "${" + b.String() + "}"
+}
+
+// isPlaceholder reports whether value is a single ${NAME} placeholder.
+func isPlaceholder(value string) bool {
+	loc := placeholderPattern.FindStringIndex(value)
+	return loc != nil && loc[0] == 0 && loc[1] == len(value)
+}
+
+// redactArgs replaces the values of secret-looking arguments
+// (--api-key=VALUE, --token VALUE) and URLs with secret query parameters.
+func redactArgs(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	out := make([]string, 0, len(args))
+	pending := "" // placeholder for the value of the previous flag
+	for _, arg := range args {
+		isFlag := strings.HasPrefix(arg, "-")
+		if pending != "" && !isFlag && !isPlaceholder(arg) {
+			out = append(out, pending)
+			pending = ""
+			continue
+		}
+		pending = ""
+
+		if !isFlag {
+			out = append(out, redactURL(arg))
+			continue
+		}
+		flag, value, hasValue := strings.Cut(arg, "=")
+		if !secretNamePattern.MatchString(flag) {
+			out = append(out, arg)
+			continue
+		}
+		name := placeholder(strings.TrimLeft(flag, "-"))
+		switch {
+		case !hasValue:
+			out = append(out, arg)
+			pending = name
+		case isPlaceholder(value):
+			out = append(out, arg)
+		default:
+			out = append(out, flag+"="+name)
+		}
+	}
+	return out
+}
+
+// redactURL replaces secret-looking query parameters of an http(s) URL.
+func redactURL(raw string) string {
+	if !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
+		return raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return raw
+	}
+
+	query := u.Query()
+	changed := false
+	for name, values := range query {
+		if !secretNamePattern.MatchString(name) {
+			continue
+		}
+		for i := range values {
+			if !isPlaceholder(values[i]) {
+				values[i] = placeholder(name)
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return raw
+	}
+
+	// Keep placeholders readable instead of percent-encoding the braces
+	u.RawQuery = strings.NewReplacer("%24%7B", "${", "%7D", "}").Replace(query.Encode())
+	return u.String()
+}
@@ -0,0 +1,141 @@
+package bundle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestExport(t *testing.T) {
+	servers := []types.MCPServer{
+		{
+			Name:    "github",
+			TypeStr: "stdio",
+			Command: "npx",
+			Args:    []string{"-y", "github-mcp", "--token", "ghp_secret", "--api-key=abc", "--verbose"},
+			Env:     map[string]string{"GITHUB_TOKEN": "ghp_secret", "LOG_LEVEL": "debug", "HOST": "${HOST}"},
+			Scope:   types.ScopeGlobal,
+		},
+		{
+			Name:        "context7",
+			Type:        types.ServerTypeHTTP,
+			TypeStr:     "http",
+			URL:         "https://mcp.context7.com/mcp?api_key=abc&region=eu",
+			Headers:     map[string]string{"Authorization": "Bearer abc"},
+			Scope:       types.ScopeProject,
+			ProjectPath: "/work/app",
+		},
+	}
+
+	got := Export(servers, ExportOptions{KeepEnv: []string{"LOG_LEVEL"}})
+
+	want := &Bundle{
+		Version: Version,
+		Servers: []Server{
+			{
+				Name:    "github",
+				Scope:   "global",
+				Type:    "stdio",
+				Command: "npx",
+				Args:    []string{"-y", "github-mcp", "--token", "${TOKEN}", "--api-key=${API_KEY}", "--verbose"},
+				Env:     map[string]string{"GITHUB_TOKEN": "${GITHUB_TOKEN}", "LOG_LEVEL": "debug", "HOST": "${HOST}"},
+			},
+			{
+				Name:    "context7",
+				Scope:   "project",
+				Project: "/work/app",
+				Type:    "http",
+				URL:     "https://mcp.context7.com/mcp?api_key=${API_KEY}&region=eu",
+				Headers: map[string]string{"Authorization": "${AUTHORIZATION}"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Export() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "no secrets",
+			args: []string{"-y", "@upstash/context7-mcp"},
+			want: []string{"-y", "@upstash/context7-mcp"},
+		},
+		{
+			name: "secret flag followed by another flag",
+			args: []string{"--token", "--verbose"},
+			want: []string{"--token", "--verbose"},
+		},
+		{
+			name: "placeholder is kept",
+			args: []string{"--password", "${DB_PASSWORD}"},
+			want: []string{"--password", "${DB_PASSWORD}"},
+		},
+		{
+			name: "url argument",
+			args: []string{"mcp-remote", "https://example.com/sse?token=abc"},
+			want: []string{"mcp-remote", "https://example.com/sse?token=${TOKEN}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactArgs(tt.args)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("redactArgs() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	want := &Bundle{Version: Version, Servers: []Server{
+		{Name: "context7", Scope: "global", Type: "http", URL: "https://mcp.context7.com/mcp"},
+		{Name: "serena", Scope: "project", Project: "/work/app", Command: "uvx", Args: []string{"serena"}},
+	}}
+
+	if err := Write(path, want); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "invalid json", content: `{`},
+		{name: "unsupported version", content: `{"version": 2, "servers": []}`},
+		{name: "missing name", content: `{"version": 1, "servers": [{"scope": "global"}]}`},
+		{name: "invalid scope", content: `{"version": 1, "servers": [{"name": "a", "scope": "user"}]}`},
+		{name: "project scope without project", content: `{"version": 1, "servers": [{"name": "a", "scope": "project"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bundle.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("failed to write bundle: %v", err)
+			}
+			if _, err := Load(path); err == nil {
+				t.Error("Load() expected an error")
+			}
+		})
+	}
+}
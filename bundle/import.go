@@ -0,0 +1,335 @@
+package bundle
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// ConflictPolicy decides what happens when an imported server already exists.
+type ConflictPolicy int
+
+const (
+	// ConflictSkip keeps the existing server.
+	ConflictSkip ConflictPolicy = iota
+	// ConflictOverwrite replaces the existing server.
+	ConflictOverwrite
+	// ConflictRename imports the server under a new name (name-2, name-3, ...).
+	ConflictRename
+)
+
+// ParseConflictPolicy parses "skip", "overwrite" or "rename".
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch s {
+	case "skip":
+		return ConflictSkip, nil
+	case "overwrite":
+		return ConflictOverwrite, nil
+	case "rename":
+		return ConflictRename, nil
+	default:
+		return ConflictSkip, fmt.Errorf("invalid conflict policy %q (expected skip, overwrite or rename)", s)
+	}
+}
+
+// Action is what an import does with a server.
+type Action int
+
+const (
+	// ActionAdd adds a new server.
+	ActionAdd Action = iota
+	// ActionOverwrite replaces an existing server.
+	ActionOverwrite
+	// ActionRename adds the server under a new name because the name is taken.
+	ActionRename
+	// ActionSkip keeps a conflicting existing server.
+	ActionSkip
+	// ActionUnchanged skips a server that is already configured identically.
+	ActionUnchanged
+	// ActionDenied skips a server that ImportOptions.Allow rejects.
+	ActionDenied
+)
+
+// String returns the string representation of the action.
+func (a Action) String() string {
+	switch a {
+	case ActionAdd:
+		return "add"
+	case ActionOverwrite:
+		return "overwrite"
+	case ActionRename:
+		return "rename"
+	case ActionSkip:
+		return "skip"
+	case ActionUnchanged:
+		return "unchanged"
+	case ActionDenied:
+		return "denied"
+	default:
+		return "unknown"
+	}
+}
+
+// Writes reports whether the action changes the config.
+func (a Action) Writes() bool {
+	return a == ActionAdd || a == ActionOverwrite || a == ActionRename
+}
+
+// PathMapping maps project paths under From to paths under To.
+type PathMapping struct {
+	From string
+	To   string
+}
+
+// ParsePathMapping parses "OLD=NEW".
+func ParsePathMapping(s string) (PathMapping, error) {
+	from, to, ok := strings.Cut(s, "=")
+	if !ok || from == "" || to == "" {
+		return PathMapping{}, fmt.Errorf("invalid path mapping %q (expected OLD=NEW)", s)
+	}
+	return PathMapping{From: filepath.Clean(from), To: filepath.Clean(to)}, nil
+}
+
+// ImportOptions control how a bundle is merged into a config.
+type ImportOptions struct {
+	OnConflict ConflictPolicy
+	// PathMappings rewrite project paths, and arguments pointing into them.
+	// The longest matching prefix wins.
+	PathMappings []PathMapping
+	// LookupEnv resolves ${NAME} placeholders; nil leaves them as they are.
+	LookupEnv func(string) (string, bool)
+	// Allow rejects servers that must not be written, such as servers the
+	// managed policy denies; nil allows every server.
+	Allow func(*types.MCPServer) error
+}
+
+// PlannedServer is the planned import of a single server.
+type PlannedServer struct {
+	Action Action
+	// Server is the server to write (for skipped servers, the imported definition).
+	Server types.MCPServer
+	// OriginalName is the name in the bundle, which differs from Server.Name when renamed.
+	OriginalName string
+	// Existing is the configured server the import conflicts with, if any.
+	Existing *types.MCPServer
+	// Changes describes how an overwritten server changes (without secret values).
+	Changes []string
+	// Unresolved lists placeholders that could not be resolved.
+	Unresolved []string
+	// Denied explains why Allow rejected the server.
+	Denied string
+}
+
+// Plan decides for every server in the bundle whether it is added,
+// overwritten, renamed, skipped or denied when imported into the existing servers.
+func Plan(b *Bundle, existing []types.MCPServer, opts ImportOptions) []PlannedServer {
+	configured := make(map[string]*types.MCPServer, len(existing))
+	for i := range existing {
+		configured[existing[i].Key()] = &existing[i]
+	}
+	taken := func(server *types.MCPServer) bool {
+		_, ok := configured[server.Key()]
+		return ok
+	}
+
+	plan := make([]PlannedServer, 0, len(b.Servers))
+	for i := range b.Servers {
+		server := b.Servers[i].toMCPServer()
+		if server.Scope == types.ScopeProject {
+			server.ProjectPath = mapPath(server.ProjectPath, opts.PathMappings)
+		}
+		server.Args = mapArgs(server.Args, opts.PathMappings)
+
+		current := configured[server.Key()]
+		unresolved := resolvePlaceholders(&server, current, opts.LookupEnv)
+		planned := PlannedServer{Server: server, OriginalName: server.Name, Existing: current, Unresolved: unresolved}
+
+		switch {
+		case current == nil:
+			planned.Action = ActionAdd
+		case sameServer(current, &server):
+			planned.Action = ActionUnchanged
+		case opts.OnConflict == ConflictOverwrite:
+			planned.Action = ActionOverwrite
+			planned.Changes = describeChanges(current, &server)
+		case opts.OnConflict == ConflictRename:
+			planned.Action = ActionRename
+			planned.Existing = nil
+			planned.Server.Name = freeName(&planned.Server, taken)
+		default:
+			planned.Action = ActionSkip
+			planned.Changes = describeChanges(current, &server)
+		}
+
+		if planned.Action.Writes() && opts.Allow != nil {
+			if err := opts.Allow(&planned.Server); err != nil {
+				planned.Action = ActionDenied
+				planned.Denied = err.Error()
+			}
+		}
+
+		if planned.Action.Writes() {
+			s := planned.Server
+			configured[s.Key()] = &s
+		}
+		plan = append(plan, planned)
+	}
+	return plan
+}
+
+// Upserts returns the servers the plan writes to the config.
+func Upserts(plan []PlannedServer) []types.MCPServer {
+	var servers []types.MCPServer
+	for i := range plan {
+		if plan[i].Action.Writes() {
+			servers = append(servers, plan[i].Server)
+		}
+	}
+	return servers
+}
+
+// mapPath rewrites p with the longest mapping whose From is p or a parent of p.
+func mapPath(p string, mappings []PathMapping) string {
+	best := -1
+	for i, m := range mappings {
+		if (p == m.From || strings.HasPrefix(p, m.From+string(filepath.Separator))) &&
+			(best < 0 || len(m.From) > len(mappings[best].From)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return p
+	}
+	return mappings[best].To + strings.TrimPrefix(p, mappings[best].From)
+}
+
+// mapArgs rewrites arguments that are paths under a mapped project path.
+func mapArgs(args []string, mappings []PathMapping) []string {
+	if len(mappings) == 0 || len(args) == 0 {
+		return args
+	}
+	out := make([]string, len(args))
+	for i, arg := range args {
+		out[i] = mapPath(arg, mappings)
+	}
+	return out
+}
+
+// resolvePlaceholders expands ${NAME} placeholders in env and header values,
+// args and the URL. Env and header placeholders that cannot be resolved keep
+// the value of the existing server if it has one. Placeholders that remain
+// are returned.
+func resolvePlaceholders(server, existing *types.MCPServer, lookup func(string) (string, bool)) []string {
+	seen := make(map[string]bool)
+	expand := func(value string, fallback func() (string, bool)) string {
+		return placeholderPattern.ReplaceAllStringFunc(value, func(ph string) string {
+			name := placeholderPattern.FindStringSubmatch(ph)[1]
+			if lookup != nil {
+				if v, ok := lookup(name); ok {
+					return v
+				}
+			}
+			if fallback != nil && isPlaceholder(value) {
+				if v, ok := fallback(); ok {
+					return v
+				}
+			}
+			seen[name] = true
+			return ph
+		})
+	}
+	expandMap := func(values, current map[string]string) map[string]string {
+		if len(values) == 0 {
+			return values
+		}
+		out := make(map[string]string, len(values))
+		for key, value := range values {
+			out[key] = expand(value, func() (string, bool) {
+				v, ok := current[key]
+				return v, ok
+			})
+		}
+		return out
+	}
+
+	var currentEnv, currentHeaders map[string]string
+	if existing != nil {
+		currentEnv, currentHeaders = existing.Env, existing.Headers
+	}
+	server.Env = expandMap(server.Env, currentEnv)
+	server.Headers = expandMap(server.Headers, currentHeaders)
+	server.URL = expand(server.URL, nil)
+	if len(server.Args) > 0 {
+		args := make([]string, len(server.Args))
+		for i, arg := range server.Args {
+			args[i] = expand(arg, nil)
+		}
+		server.Args = args
+	}
+
+	unresolved := make([]string, 0, len(seen))
+	for name := range seen {
+		unresolved = append(unresolved, name)
+	}
+	sort.Strings(unresolved)
+	return unresolved
+}
+
+// sameServer reports whether two servers have the same definition.
+func sameServer(a, b *types.MCPServer) bool {
+	return len(describeChanges(a, b)) == 0
+}
+
+// describeChanges describes how from changes when replaced by to. Env and
+// header values are compared but never shown.
+func describeChanges(from, to *types.MCPServer) []string {
+	changes := from.ConnectionChanges(to)
+	changes = append(changes, mapChanges("env", from.Env, to.Env)...)
+	changes = append(changes, mapChanges("headers", from.Headers, to.Headers)...)
+	return changes
+}
+
+// mapChanges describes added, removed and changed keys of a map.
+func mapChanges(field string, from, to map[string]string) []string {
+	var added, removed, changed []string
+	for key, value := range to {
+		old, ok := from[key]
+		switch {
+		case !ok:
+			added = append(added, key)
+		case old != value:
+			changed = append(changed, key)
+		}
+	}
+	for key := range from {
+		if _, ok := to[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+
+	var changes []string
+	for _, c := range []struct {
+		verb string
+		keys []string
+	}{{"added", added}, {"removed", removed}, {"changed", changed}} {
+		if len(c.keys) > 0 {
+			sort.Strings(c.keys)
+			changes = append(changes, fmt.Sprintf("%s %s: %s", field, c.verb, strings.Join(c.keys, ", ")))
+		}
+	}
+	return changes
+}
+
+// freeName returns the first name-N (N >= 2) not taken in the server's scope.
+func freeName(server *types.MCPServer, taken func(*types.MCPServer) bool) string {
+	candidate := *server
+	for n := 2; ; n++ {
+		candidate.Name = fmt.Sprintf("%s-%d", server.Name, n)
+		if !taken(&candidate) {
+			return candidate.Name
+		}
+	}
+}
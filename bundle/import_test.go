@@ -0,0 +1,213 @@
+package bundle
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestPlan(t *testing.T) {
+	existing := []types.MCPServer{
+		{Name: "context7", TypeStr: "http", Type: types.ServerTypeHTTP, URL: "https://mcp.context7.com/mcp", Scope: types.ScopeGlobal},
+		{Name: "github", Command: "npx", Args: []string{"github-mcp@1"}, Env: map[string]string{"GITHUB_TOKEN": "local"}, Scope: types.ScopeGlobal},
+	}
+	b := &Bundle{Version: Version, Servers: []Server{
+		{Name: "context7", Scope: "global", Type: "http", URL: "https://mcp.context7.com/mcp"},
+		{Name: "github", Scope: "global", Command: "npx", Args: []string{"github-mcp@2"}, Env: map[string]string{"GITHUB_TOKEN": "${GITHUB_TOKEN}"}},
+		{Name: "serena", Scope: "project", Project: "/old/app", Command: "uvx", Args: []string{"serena", "/old/app/config.yml"}},
+	}}
+
+	tests := []struct {
+		name    string
+		opts    ImportOptions
+		actions []Action
+		names   []string
+	}{
+		{
+			name:    "skip conflicts",
+			opts:    ImportOptions{OnConflict: ConflictSkip},
+			actions: []Action{ActionUnchanged, ActionSkip, ActionAdd},
+			names:   []string{"context7", "github", "serena"},
+		},
+		{
+			name:    "overwrite conflicts",
+			opts:    ImportOptions{OnConflict: ConflictOverwrite},
+			actions: []Action{ActionUnchanged, ActionOverwrite, ActionAdd},
+			names:   []string{"context7", "github", "serena"},
+		},
+		{
+			name:    "rename conflicts",
+			opts:    ImportOptions{OnConflict: ConflictRename},
+			actions: []Action{ActionUnchanged, ActionRename, ActionAdd},
+			names:   []string{"context7", "github-2", "serena"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := Plan(b, existing, tt.opts)
+			var actions []Action
+			var names []string
+			for i := range plan {
+				actions = append(actions, plan[i].Action)
+				names = append(names, plan[i].Server.Name)
+			}
+			if diff := cmp.Diff(tt.actions, actions); diff != "" {
+				t.Errorf("Plan() actions mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.names, names); diff != "" {
+				t.Errorf("Plan() names mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPlan_Overwrite(t *testing.T) {
+	existing := []types.MCPServer{
+		{Name: "github", Command: "npx", Args: []string{"github-mcp@1"}, Env: map[string]string{"GITHUB_TOKEN": "local", "DEBUG": "1"}, Scope: types.ScopeGlobal},
+	}
+	b := &Bundle{Version: Version, Servers: []Server{
+		{Name: "github", Scope: "global", Command: "npx", Args: []string{"github-mcp@2"}, Env: map[string]string{"GITHUB_TOKEN": "${GITHUB_TOKEN}", "OWNER": "${OWNER}"}},
+	}}
+
+	plan := Plan(b, existing, ImportOptions{OnConflict: ConflictOverwrite})
+
+	want := []PlannedServer{{
+		Action: ActionOverwrite,
+		Server: types.MCPServer{
+			Name: "github", Command: "npx", Args: []string{"github-mcp@2"},
+			Env:   map[string]string{"GITHUB_TOKEN": "local", "OWNER": "${OWNER}"},
+			Scope: types.ScopeGlobal,
+		},
+		OriginalName: "github",
+		Existing:     &existing[0],
+		Changes:      []string{"args: [github-mcp@1] → [github-mcp@2]", "env added: OWNER", "env removed: DEBUG"},
+		Unresolved:   []string{"OWNER"},
+	}}
+	if diff := cmp.Diff(want, plan); diff != "" {
+		t.Errorf("Plan() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlan_PathMappingAndLookup(t *testing.T) {
+	b := &Bundle{Version: Version, Servers: []Server{{
+		Name: "serena", Scope: "project", Project: "/old/work/app",
+		Command: "uvx", Args: []string{"serena", "--project", "/old/work/app/src", "--token", "${TOKEN}"},
+		Env: map[string]string{"API_KEY": "${API_KEY}"},
+	}}}
+	env := map[string]string{"API_KEY": "from-env", "TOKEN": "tok"}
+	opts := ImportOptions{
+		PathMappings: []PathMapping{{From: "/old", To: "/elsewhere"}, {From: "/old/work", To: "/new/work"}},
+		LookupEnv: func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		},
+	}
+
+	plan := Plan(b, nil, opts)
+
+	want := types.MCPServer{
+		Name: "serena", Command: "uvx", Args: []string{"serena", "--project", "/new/work/app/src", "--token", "tok"},
+		Env:   map[string]string{"API_KEY": "from-env"},
+		Scope: types.ScopeProject, ProjectPath: "/new/work/app",
+	}
+	if diff := cmp.Diff(want, plan[0].Server); diff != "" {
+		t.Errorf("Plan() server mismatch (-want +got):\n%s", diff)
+	}
+	if len(plan[0].Unresolved) != 0 {
+		t.Errorf("Plan() unresolved = %v, want none", plan[0].Unresolved)
+	}
+}
+
+func TestPlan_Allow(t *testing.T) {
+	b := &Bundle{Version: Version, Servers: []Server{
+		{Name: "github", Scope: "global", Type: "http", URL: "https://api.github.com/mcp"},
+		{Name: "puppeteer", Scope: "global", Command: "npx"},
+	}}
+	opts := ImportOptions{
+		Allow: func(server *types.MCPServer) error {
+			if server.Name == "puppeteer" {
+				return errors.New("denied by test")
+			}
+			return nil
+		},
+	}
+
+	plan := Plan(b, nil, opts)
+
+	got := []string{plan[0].Action.String(), plan[1].Action.String(), plan[1].Denied}
+	want := []string{"add", "denied", "denied by test"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Plan() mismatch (-want +got):\n%s", diff)
+	}
+	if upserts := Upserts(plan); len(upserts) != 1 || upserts[0].Name != "github" {
+		t.Errorf("Upserts() = %v, want only github", upserts)
+	}
+}
+
+func TestUpserts(t *testing.T) {
+	plan := []PlannedServer{
+		{Action: ActionAdd, Server: types.MCPServer{Name: "a"}},
+		{Action: ActionSkip, Server: types.MCPServer{Name: "b"}},
+		{Action: ActionUnchanged, Server: types.MCPServer{Name: "c"}},
+		{Action: ActionRename, Server: types.MCPServer{Name: "d-2"}},
+	}
+
+	got := Upserts(plan)
+
+	want := []types.MCPServer{{Name: "a"}, {Name: "d-2"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Upserts() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ConflictPolicy
+		wantErr bool
+	}{
+		{input: "skip", want: ConflictSkip},
+		{input: "overwrite", want: ConflictOverwrite},
+		{input: "rename", want: ConflictRename},
+		{input: "merge", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseConflictPolicy(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseConflictPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseConflictPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePathMapping(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    PathMapping
+		wantErr bool
+	}{
+		{input: "/Users/alice/src=/home/bob/code/", want: PathMapping{From: "/Users/alice/src", To: "/home/bob/code"}},
+		{input: "/only-old", wantErr: true},
+		{input: "=/new", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePathMapping(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePathMapping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParsePathMapping() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
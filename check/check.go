@@ -0,0 +1,277 @@
+// Package check validates MCP server configurations against team rules.
+package check
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// Rule names, as used in violations and reports.
+const (
+	RuleMaxServers    = "max-servers"
+	RuleMaxPerScope   = "max-per-scope"
+	RuleBannedCommand = "banned-command"
+	RuleBannedHost    = "banned-host"
+	RuleRequirePins   = "require-pins"
+	RuleMaxUnused     = "max-unused"
+)
+
+// Rules are the limits a configuration is checked against.
+// Unset rules are not checked.
+type Rules struct {
+	// MaxServers is the maximum number of servers in the configuration.
+	MaxServers *int `yaml:"maxServers,omitempty"`
+	// MaxPerScope holds the maximum number of servers keyed by "global",
+	// "project" (applied to each project separately) or a project path.
+	MaxPerScope map[string]int `yaml:"maxPerScope,omitempty"`
+	// BannedCommands holds patterns matched against the command name and the
+	// full command line of stdio servers; '*' matches any text.
+	BannedCommands []string `yaml:"bannedCommands,omitempty"`
+	// BannedHosts holds glob patterns matched against the hosts of server URLs.
+	BannedHosts []string `yaml:"bannedHosts,omitempty"`
+	// RequirePins requires package runners (npx, uvx, docker, ...) to pin a version.
+	RequirePins bool `yaml:"requirePins,omitempty"`
+	// MaxUnused is the maximum number of servers flagged as unused.
+	MaxUnused *int `yaml:"maxUnused,omitempty"`
+}
+
+// Violation is a single rule violation.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Server  string `json:"server,omitempty"`
+	Scope   string `json:"scope,omitempty"`
+	Project string `json:"project,omitempty"`
+	Message string `json:"message"`
+}
+
+// Result is the outcome of checking a configuration.
+type Result struct {
+	// Rules lists the rules that were checked.
+	Rules      []string
+	Servers    int
+	Violations []Violation
+}
+
+// OK reports whether no rule was violated.
+func (r *Result) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// Empty reports whether no rule is set.
+func (r *Rules) Empty() bool {
+	return r.MaxServers == nil && len(r.MaxPerScope) == 0 && len(r.BannedCommands) == 0 &&
+		len(r.BannedHosts) == 0 && !r.RequirePins && r.MaxUnused == nil
+}
+
+// NeedsUsage reports whether checking the rules requires usage verdicts.
+func (r *Rules) NeedsUsage() bool {
+	return r.MaxUnused != nil
+}
+
+// Validate checks limits and patterns.
+func (r *Rules) Validate() error {
+	if r.MaxServers != nil && *r.MaxServers < 0 {
+		return fmt.Errorf("maxServers must not be negative")
+	}
+	if r.MaxUnused != nil && *r.MaxUnused < 0 {
+		return fmt.Errorf("maxUnused must not be negative")
+	}
+	for key, limit := range r.MaxPerScope {
+		if limit < 0 {
+			return fmt.Errorf("maxPerScope %s must not be negative", key)
+		}
+	}
+	for _, pattern := range r.BannedHosts {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid host pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// Run checks the servers of cfg against rules. verdicts (keyed by
+// MCPServer.Key()) are only used for the max-unused rule.
+func Run(cfg *config.Config, rules *Rules, verdicts map[string]types.UnusedVerdict) Result {
+	// Sort servers so violations are reported in a stable order
+	servers := append([]types.MCPServer(nil), cfg.Servers()...)
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Key() < servers[j].Key()
+	})
+	result := Result{Servers: len(servers)}
+
+	add := func(rule string, violations []Violation) {
+		result.Rules = append(result.Rules, rule)
+		result.Violations = append(result.Violations, violations...)
+	}
+
+	if rules.MaxServers != nil {
+		add(RuleMaxServers, checkMaxServers(servers, *rules.MaxServers))
+	}
+	if len(rules.MaxPerScope) > 0 {
+		add(RuleMaxPerScope, checkMaxPerScope(servers, rules.MaxPerScope))
+	}
+	if len(rules.BannedCommands) > 0 {
+		add(RuleBannedCommand, checkServers(servers, func(s *types.MCPServer) string {
+			return bannedCommand(s, rules.BannedCommands)
+		}, RuleBannedCommand))
+	}
+	if len(rules.BannedHosts) > 0 {
+		add(RuleBannedHost, checkServers(servers, func(s *types.MCPServer) string {
+			return bannedHost(s, rules.BannedHosts)
+		}, RuleBannedHost))
+	}
+	if rules.RequirePins {
+		add(RuleRequirePins, checkServers(servers, unpinned, RuleRequirePins))
+	}
+	if rules.MaxUnused != nil {
+		add(RuleMaxUnused, checkMaxUnused(servers, *rules.MaxUnused, verdicts))
+	}
+
+	return result
+}
+
+func checkMaxServers(servers []types.MCPServer, limit int) []Violation {
+	if len(servers) <= limit {
+		return nil
+	}
+	return []Violation{{
+		Rule:    RuleMaxServers,
+		Message: fmt.Sprintf("%d servers configured, maximum is %d", len(servers), limit),
+	}}
+}
+
+func checkMaxPerScope(servers []types.MCPServer, limits map[string]int) []Violation {
+	global := 0
+	projects := make(map[string]int)
+	for i := range servers {
+		if servers[i].Scope == types.ScopeProject {
+			projects[servers[i].ProjectPath]++
+		} else {
+			global++
+		}
+	}
+
+	var violations []Violation
+	if limit, ok := limits[types.ScopeGlobal.String()]; ok && global > limit {
+		violations = append(violations, Violation{
+			Rule:    RuleMaxPerScope,
+			Scope:   types.ScopeGlobal.String(),
+			Message: fmt.Sprintf("%d global servers, maximum is %d", global, limit),
+		})
+	}
+
+	paths := make([]string, 0, len(projects))
+	for p := range projects {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		limit, ok := limits[p]
+		if !ok {
+			limit, ok = limits[types.ScopeProject.String()]
+		}
+		if ok && projects[p] > limit {
+			violations = append(violations, Violation{
+				Rule:    RuleMaxPerScope,
+				Scope:   types.ScopeProject.String(),
+				Project: p,
+				Message: fmt.Sprintf("%d servers in project %s, maximum is %d", projects[p], p, limit),
+			})
+		}
+	}
+	return violations
+}
+
+// checkServers reports a violation for every server for which problem
+// returns a non-empty message.
+func checkServers(servers []types.MCPServer, problem func(*types.MCPServer) string, rule string) []Violation {
+	var violations []Violation
+	for i := range servers {
+		if msg := problem(&servers[i]); msg != "" {
+			violations = append(violations, newViolation(rule, &servers[i], msg))
+		}
+	}
+	return violations
+}
+
+func checkMaxUnused(servers []types.MCPServer, limit int, verdicts map[string]types.UnusedVerdict) []Violation {
+	var unused []string
+	for i := range servers {
+		if verdicts[servers[i].Key()].Flagged {
+			unused = append(unused, servers[i].Name)
+		}
+	}
+	if len(unused) <= limit {
+		return nil
+	}
+	sort.Strings(unused)
+	return []Violation{{
+		Rule:    RuleMaxUnused,
+		Message: fmt.Sprintf("%d unused servers, maximum is %d: %s", len(unused), limit, strings.Join(unused, ", ")),
+	}}
+}
+
+func newViolation(rule string, server *types.MCPServer, msg string) Violation {
+	return Violation{
+		Rule:    rule,
+		Server:  server.Name,
+		Scope:   server.Scope.String(),
+		Project: server.ProjectPath,
+		Message: msg,
+	}
+}
+
+// bannedCommand returns a message if the server's command matches a banned pattern.
+func bannedCommand(server *types.MCPServer, patterns []string) string {
+	if server.Command == "" {
+		return ""
+	}
+	name := filepath.Base(server.Command)
+	line := strings.TrimSpace(name + " " + strings.Join(server.Args, " "))
+	for _, pattern := range patterns {
+		if matchWildcard(pattern, name) || matchWildcard(pattern, server.Command) || matchWildcard(pattern, line) {
+			return fmt.Sprintf("command %q is banned (%s)", line, pattern)
+		}
+	}
+	return ""
+}
+
+// bannedHost returns a message if the server connects to a banned host,
+// either through its URL or a URL passed as an argument (e.g. mcp-remote).
+func bannedHost(server *types.MCPServer, patterns []string) string {
+	urls := append([]string{server.URL}, server.Args...)
+	for _, raw := range urls {
+		if !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(strings.ToLower(pattern), host); ok {
+				return fmt.Sprintf("host %q is banned (%s)", host, pattern)
+			}
+		}
+	}
+	return ""
+}
+
+// matchWildcard matches text against a pattern in which '*' matches any
+// text (including '/') and '?' matches a single character.
+func matchWildcard(pattern, text string) bool {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	ok, err := regexp.MatchString("^"+expr+"$", text)
+	return err == nil && ok
+}
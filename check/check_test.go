@@ -0,0 +1,202 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func intPtr(n int) *int { return &n }
+
+func loadConfig(t *testing.T, content string) *config.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".mcp.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	return cfg
+}
+
+func TestRun(t *testing.T) {
+	cfg := loadConfig(t, `{
+  "mcpServers": {
+    "context7": {"type": "http", "url": "https://mcp.context7.com/mcp"},
+    "puppeteer": {"type": "stdio", "command": "npx", "args": ["-y", "@anthropic/server-puppeteer"]},
+    "fetch": {"type": "stdio", "command": "/usr/bin/curl", "args": ["-s", "https://example.com"]}
+  },
+  "projects": {
+    "/work/app": {
+      "mcpServers": {
+        "serena": {"type": "stdio", "command": "uvx", "args": ["--from", "git+https://github.com/oraios/serena@v0.1.4", "serena"]},
+        "internal": {"type": "stdio", "command": "npx", "args": ["mcp-remote", "https://mcp.corp.example.com/sse"]}
+      }
+    }
+  }
+}`)
+
+	tests := []struct {
+		name     string
+		rules    Rules
+		verdicts map[string]types.UnusedVerdict
+		want     []Violation
+	}{
+		{
+			name:  "within limits",
+			rules: Rules{MaxServers: intPtr(5), MaxPerScope: map[string]int{"global": 3, "project": 2}},
+		},
+		{
+			name:  "too many servers",
+			rules: Rules{MaxServers: intPtr(4)},
+			want: []Violation{
+				{Rule: RuleMaxServers, Message: "5 servers configured, maximum is 4"},
+			},
+		},
+		{
+			name:  "project path limit overrides the project limit",
+			rules: Rules{MaxPerScope: map[string]int{"global": 2, "project": 5, "/work/app": 1}},
+			want: []Violation{
+				{Rule: RuleMaxPerScope, Scope: "global", Message: "3 global servers, maximum is 2"},
+				{Rule: RuleMaxPerScope, Scope: "project", Project: "/work/app", Message: "2 servers in project /work/app, maximum is 1"},
+			},
+		},
+		{
+			name:  "banned command matches the command name",
+			rules: Rules{BannedCommands: []string{"curl"}},
+			want: []Violation{
+				{Rule: RuleBannedCommand, Server: "fetch", Scope: "global", Message: `command "curl -s https://example.com" is banned (curl)`},
+			},
+		},
+		{
+			name:  "banned command matches the command line",
+			rules: Rules{BannedCommands: []string{"npx *puppeteer*"}},
+			want: []Violation{
+				{Rule: RuleBannedCommand, Server: "puppeteer", Scope: "global", Message: `command "npx -y @anthropic/server-puppeteer" is banned (npx *puppeteer*)`},
+			},
+		},
+		{
+			name:  "banned host in url arguments",
+			rules: Rules{BannedHosts: []string{"*.example.com"}},
+			want: []Violation{
+				{Rule: RuleBannedHost, Server: "internal", Scope: "project", Project: "/work/app", Message: `host "mcp.corp.example.com" is banned (*.example.com)`},
+			},
+		},
+		{
+			name:  "unpinned packages",
+			rules: Rules{RequirePins: true},
+			want: []Violation{
+				{Rule: RuleRequirePins, Server: "puppeteer", Scope: "global", Message: `npx runs "@anthropic/server-puppeteer" without a pinned version`},
+				{Rule: RuleRequirePins, Server: "internal", Scope: "project", Project: "/work/app", Message: `npx runs "mcp-remote" without a pinned version`},
+			},
+		},
+		{
+			name:  "too many unused servers",
+			rules: Rules{MaxUnused: intPtr(1)},
+			verdicts: map[string]types.UnusedVerdict{
+				"global:puppeteer":           {Flagged: true},
+				"global:fetch":               {Flagged: true},
+				"project:/work/app:internal": {Protected: true},
+			},
+			want: []Violation{
+				{Rule: RuleMaxUnused, Message: "2 unused servers, maximum is 1: fetch, puppeteer"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Run(cfg, &tt.rules, tt.verdicts)
+			got := sortViolations(result.Violations)
+			if diff := cmp.Diff(sortViolations(tt.want), got); diff != "" {
+				t.Errorf("Run() violations mismatch (-want +got):\n%s", diff)
+			}
+			if result.OK() != (len(tt.want) == 0) {
+				t.Errorf("Run() OK = %v, want %v", result.OK(), len(tt.want) == 0)
+			}
+		})
+	}
+}
+
+// sortViolations orders violations by rule and server, since servers are
+// loaded from a map.
+func sortViolations(violations []Violation) []Violation {
+	sorted := append([]Violation(nil), violations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Rule != sorted[j].Rule {
+			return sorted[i].Rule < sorted[j].Rule
+		}
+		return sorted[i].Server < sorted[j].Server
+	})
+	return sorted
+}
+
+func TestRun_RulesChecked(t *testing.T) {
+	cfg := loadConfig(t, `{"mcpServers": {}}`)
+
+	result := Run(cfg, &Rules{MaxServers: intPtr(1), RequirePins: true}, nil)
+
+	if diff := cmp.Diff([]string{RuleMaxServers, RuleRequirePins}, result.Rules); diff != "" {
+		t.Errorf("Run() rules mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnpinned(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+		want    bool
+	}{
+		{name: "npx pinned", command: "npx", args: []string{"-y", "@upstash/context7-mcp@1.0.6"}, want: false},
+		{name: "npx unpinned", command: "npx", args: []string{"-y", "@upstash/context7-mcp"}, want: true},
+		{name: "npx dist-tag", command: "npx", args: []string{"-y", "server@latest"}, want: true},
+		{name: "npx package flag", command: "npx", args: []string{"-p", "tool@2.1.0", "tool-cli"}, want: false},
+		{name: "pnpm dlx unpinned", command: "pnpm", args: []string{"dlx", "server"}, want: true},
+		{name: "uvx pinned", command: "uvx", args: []string{"mcp-server-fetch==2025.1.17"}, want: false},
+		{name: "uvx unpinned", command: "uvx", args: []string{"mcp-server-fetch"}, want: true},
+		{name: "uvx git ref", command: "uvx", args: []string{"--from", "git+https://github.com/oraios/serena@v0.1.4", "serena"}, want: false},
+		{name: "uvx git without ref", command: "uvx", args: []string{"--from", "git+https://github.com/oraios/serena", "serena"}, want: true},
+		{name: "docker tag", command: "docker", args: []string{"run", "-i", "--rm", "-e", "TOKEN", "ghcr.io/github/github-mcp-server:v0.5.0"}, want: false},
+		{name: "docker latest", command: "docker", args: []string{"run", "-i", "--rm", "ghcr.io/github/github-mcp-server"}, want: true},
+		{name: "docker digest", command: "docker", args: []string{"run", "mcp/fetch@sha256:abc"}, want: false},
+		{name: "other commands are not checked", command: "/usr/local/bin/my-server", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &types.MCPServer{Name: "s", Type: types.ServerTypeStdio, Command: tt.command, Args: tt.args}
+			if got := unpinned(server) != ""; got != tt.want {
+				t.Errorf("unpinned() = %q, want violation %v", unpinned(server), tt.want)
+			}
+		})
+	}
+}
+
+func TestRules_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   Rules
+		wantErr bool
+	}{
+		{name: "valid", rules: Rules{MaxServers: intPtr(0), BannedHosts: []string{"*.example.com"}}},
+		{name: "negative maxServers", rules: Rules{MaxServers: intPtr(-1)}, wantErr: true},
+		{name: "negative scope limit", rules: Rules{MaxPerScope: map[string]int{"global": -1}}, wantErr: true},
+		{name: "invalid host pattern", rules: Rules{BannedHosts: []string{"["}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
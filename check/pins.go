@@ -0,0 +1,143 @@
+package check
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// dockerValueFlags are docker run flags that take a value as the next argument.
+var dockerValueFlags = map[string]bool{
+	"-e": true, "--env": true, "--env-file": true,
+	"-v": true, "--volume": true, "--mount": true,
+	"-p": true, "--publish": true, "--name": true, "--network": true,
+	"-w": true, "--workdir": true, "-u": true, "--user": true,
+	"--platform": true, "--entrypoint": true, "-l": true, "--label": true,
+}
+
+// unpinned returns a message if the server runs a package through a package
+// runner without pinning its version. Other commands are not checked.
+func unpinned(server *types.MCPServer) string {
+	if server.Type != types.ServerTypeStdio || server.Command == "" {
+		return ""
+	}
+
+	runner := filepath.Base(server.Command)
+	args := server.Args
+	if (runner == "pnpm" || runner == "yarn") && len(args) > 0 && args[0] == "dlx" {
+		runner, args = runner+" dlx", args[1:]
+	}
+
+	var spec string
+	var pinned func(string) bool
+	switch runner {
+	case "npx", "bunx", "pnpm dlx", "yarn dlx":
+		spec, pinned = npmPackage(args), npmPinned
+	case "uvx", "pipx":
+		spec, pinned = pythonPackage(args), pythonPinned
+	case "docker", "podman":
+		spec, pinned = dockerImage(args), imagePinned
+	default:
+		return ""
+	}
+
+	if spec == "" || pinned(spec) {
+		return ""
+	}
+	return fmt.Sprintf("%s runs %q without a pinned version", runner, spec)
+}
+
+// npmPackage returns the package run by npx and similar runners.
+func npmPackage(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-p" || arg == "--package":
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+		case strings.HasPrefix(arg, "--package="):
+			return strings.TrimPrefix(arg, "--package=")
+		case !strings.HasPrefix(arg, "-"):
+			return arg
+		}
+	}
+	return ""
+}
+
+// npmPinned reports whether an npm package spec names a version
+// (name@1.2.3 or @scope/name@1.2.3); dist-tags such as latest don't count.
+func npmPinned(spec string) bool {
+	at := strings.LastIndex(spec, "@")
+	if at <= 0 {
+		return false
+	}
+	version := spec[at+1:]
+	return version != "" && version[0] >= '0' && version[0] <= '9'
+}
+
+// pythonPackage returns the package run by uvx or pipx (--from or --spec
+// take precedence over the command name).
+func pythonPackage(args []string) string {
+	first := ""
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--from" || arg == "--spec":
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+		case strings.HasPrefix(arg, "--from=") || strings.HasPrefix(arg, "--spec="):
+			_, value, _ := strings.Cut(arg, "=")
+			return value
+		case arg == "run":
+			// pipx run <package>
+		case first == "" && !strings.HasPrefix(arg, "-"):
+			first = arg
+		}
+	}
+	return first
+}
+
+// pythonPinned reports whether a Python package spec pins a version
+// (name==1.2.3, name@1.2.3) or a git reference (git+https://...@v1.2.3).
+func pythonPinned(spec string) bool {
+	if strings.Contains(spec, "==") {
+		return true
+	}
+	if strings.HasPrefix(spec, "git+") {
+		rest := spec[strings.LastIndex(spec, "/")+1:]
+		return strings.Contains(rest, "@")
+	}
+	_, version, ok := strings.Cut(spec, "@")
+	return ok && version != "" && version != "latest"
+}
+
+// dockerImage returns the image of a "docker run" command.
+func dockerImage(args []string) string {
+	if len(args) == 0 || args[0] != "run" {
+		return ""
+	}
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case dockerValueFlags[arg]:
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			return arg
+		}
+	}
+	return ""
+}
+
+// imagePinned reports whether an image reference has a digest or a tag other than latest.
+func imagePinned(image string) bool {
+	if strings.Contains(image, "@sha256:") {
+		return true
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, ok := strings.Cut(name, ":")
+	return ok && tag != "" && tag != "latest"
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/nnnkkk7/mcp-tidy/check"
+	"github.com/nnnkkk7/mcp-tidy/mcptidy"
+	"github.com/nnnkkk7/mcp-tidy/policy"
+	"github.com/nnnkkk7/mcp-tidy/types"
+	"github.com/nnnkkk7/mcp-tidy/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkFormat         string
+	checkRulesPath      string
+	checkPeriod         string
+	checkMaxServers     int
+	checkMaxPerScope    map[string]int
+	checkBannedCommands []string
+	checkBannedHosts    []string
+	checkRequirePins    bool
+	checkMaxUnused      int
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check [file]",
+	Short: "Check MCP servers against team rules",
+	Long: `Check the MCP server configuration against rules, for use in CI or
+pre-commit hooks.
+
+Checks ~/.claude.json by default, or the given file (e.g. a repository's
+.mcp.json). Rules are read from the 'check:' section of .mcp-tidy.yaml: the
+file given with --rules, else the .mcp-tidy.yaml next to the checked file,
+else the user policy. Flags override individual rules.
+
+  check:
+    maxServers: 10
+    maxPerScope: {global: 5, project: 8}
+    bannedCommands: ["curl *"]
+    bannedHosts: ["*.internal.example.com"]
+    requirePins: true
+    maxUnused: 0
+
+Exit codes: 0 when no rule is violated, 3 on violations, 1 on errors.`,
+	Example: `  mcp-tidy check .mcp.json --require-pins
+  mcp-tidy check --max-servers 10 --format junit > mcp-check.xml`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCheck,
+}
+
+func init() {
+	checkCmd.Flags().StringVar(&checkFormat, "format", "text", "Output format (text, json, junit)")
+	checkCmd.Flags().StringVar(&checkRulesPath, "rules", "", "Read rules from this .mcp-tidy.yaml")
+	checkCmd.Flags().StringVar(&checkPeriod, "period", "30d", "Period for determining 'unused' (7d, 30d, 90d, all, or e.g. 12w)")
+	checkCmd.Flags().IntVar(&checkMaxServers, "max-servers", 0, "Maximum number of servers")
+	checkCmd.Flags().StringToIntVar(&checkMaxPerScope, "max-per-scope", nil, "Maximum servers per scope (global=5,project=8,/path=3)")
+	checkCmd.Flags().StringSliceVar(&checkBannedCommands, "ban-command", nil, "Banned command pattern (repeatable)")
+	checkCmd.Flags().StringSliceVar(&checkBannedHosts, "ban-host", nil, "Banned host pattern (repeatable)")
+	checkCmd.Flags().BoolVar(&checkRequirePins, "require-pins", false, "Require pinned package versions")
+	checkCmd.Flags().IntVar(&checkMaxUnused, "max-unused", 0, "Maximum number of unused servers")
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	switch checkFormat {
+	case "text", "json", "junit":
+	default:
+		return fmt.Errorf("invalid --format %q (expected text, json or junit)", checkFormat)
+	}
+
+	loc, err := resolveLocation()
+	if err != nil {
+		return err
+	}
+	configPath := loc.ConfigPath
+	if len(args) > 0 {
+		configPath = args[0]
+		if _, err := os.Stat(configPath); err != nil {
+			return fmt.Errorf("failed to read %s: %w", configPath, err)
+		}
+	}
+
+	cfg, err := loadConfig(loc.Client, configPath)
+	if err != nil {
+		return err
+	}
+
+	rules, err := loadCheckRules(cmd, len(args) > 0, configPath)
+	if err != nil {
+		return err
+	}
+	if rules.Empty() {
+		return fmt.Errorf("no check rules configured (add a 'check:' section to .mcp-tidy.yaml or use flags)")
+	}
+
+	var verdicts map[string]types.UnusedVerdict
+	if rules.NeedsUsage() {
+		usage, err := mcptidy.CollectUsage(cmd.Context(), loc, cfg.Servers(), mcptidy.UsageOptions{Period: periodFlag(cmd, checkPeriod)})
+		if err != nil {
+			return err
+		}
+		verdicts = usage.Verdicts
+	}
+
+	result := check.Run(cfg, rules, verdicts)
+
+	switch checkFormat {
+	case "json":
+		err = outputCheckJSON(os.Stdout, configPath, &result)
+	case "junit":
+		err = outputCheckJUnit(os.Stdout, configPath, &result)
+	default:
+		ui.RenderCheckResult(os.Stdout, configPath, &result)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !result.OK() {
+		return &exitError{code: exitCodeFindings}
+	}
+	return nil
+}
+
+// loadCheckRules reads the rules from the policy file and applies the flags on top.
+func loadCheckRules(cmd *cobra.Command, explicitFile bool, configPath string) (*check.Rules, error) {
+	rulesPath := checkRulesPath
+	if rulesPath == "" {
+		rulesPath = policy.DefaultUserPath()
+		if explicitFile {
+			local := filepath.Join(filepath.Dir(configPath), policy.FileName)
+			if _, err := os.Stat(local); err == nil {
+				rulesPath = local
+			}
+		}
+	}
+
+	p, err := policy.LoadFile(rulesPath)
+	if err != nil {
+		return nil, err
+	}
+	rules := p.Check
+
+	flags := cmd.Flags()
+	if flags.Changed("max-servers") {
+		rules.MaxServers = &checkMaxServers
+	}
+	if flags.Changed("max-per-scope") {
+		rules.MaxPerScope = checkMaxPerScope
+	}
+	if flags.Changed("ban-command") {
+		rules.BannedCommands = checkBannedCommands
+	}
+	if flags.Changed("ban-host") {
+		rules.BannedHosts = checkBannedHosts
+	}
+	if flags.Changed("require-pins") {
+		rules.RequirePins = checkRequirePins
+	}
+	if flags.Changed("max-unused") {
+		rules.MaxUnused = &checkMaxUnused
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+type checkOutput struct {
+	Path       string            `json:"path"`
+	OK         bool              `json:"ok"`
+	Servers    int               `json:"servers"`
+	Rules      []string          `json:"rules"`
+	Violations []check.Violation `json:"violations"`
+}
+
+func outputCheckJSON(w io.Writer, path string, result *check.Result) error {
+	output := checkOutput{
+		Path:       path,
+		OK:         result.OK(),
+		Servers:    result.Servers,
+		Rules:      result.Rules,
+		Violations: result.Violations,
+	}
+	if output.Violations == nil {
+		output.Violations = []check.Violation{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// outputCheckJUnit writes the result as JUnit XML: one test case per
+// violation, and a passing test case for every rule without violations.
+func outputCheckJUnit(w io.Writer, path string, result *check.Result) error {
+	suite := junitTestSuite{Name: "mcp-tidy check " + path}
+
+	for _, rule := range result.Rules {
+		violated := false
+		for _, v := range result.Violations {
+			if v.Rule != rule {
+				continue
+			}
+			violated = true
+			name := rule
+			if v.Server != "" {
+				name = fmt.Sprintf("%s: %s", rule, v.Server)
+			} else if v.Project != "" {
+				name = fmt.Sprintf("%s: %s", rule, v.Project)
+			}
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      name,
+				ClassName: "mcp-tidy." + rule,
+				Failure:   &junitFailure{Message: v.Message, Type: rule, Text: v.Message},
+			})
+			suite.Failures++
+		}
+		if !violated {
+			suite.Cases = append(suite.Cases, junitTestCase{Name: rule, ClassName: "mcp-tidy." + rule})
+		}
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/mcptidy"
+	"github.com/nnnkkk7/mcp-tidy/ui"
+)
+
+// configuredServers loads the servers of the location's config, and, unless
+// another config was chosen, those of the client's config file in the
+// current directory. For Claude Code, the managed servers and the .mcp.json
+// servers of the config's projects and of the current directory are added,
+// and so are the servers of the other clients when otherClients is set.
+// Files that can't be read are reported and skipped.
+func configuredServers(ctx context.Context, loc *mcptidy.Source, otherClients bool) (*mcptidy.Inventory, error) {
+	opts := mcptidy.LoadOptions{
+		ProjectFiles: loc.Client.Name() == config.ClientClaudeCode || rootConfigPath == "",
+		OtherClients: otherClients,
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		opts.WorkDir = cwd
+	}
+	return loadServers(ctx, loc, opts)
+}
+
+// loadServers loads the servers of the location and reports the files that
+// were skipped or read in part on stderr.
+func loadServers(ctx context.Context, loc *mcptidy.Source, opts mcptidy.LoadOptions) (*mcptidy.Inventory, error) {
+	inv, err := mcptidy.LoadServers(ctx, loc, opts)
+	if err != nil {
+		return nil, withValidateHint(err)
+	}
+	for i := range inv.Warnings {
+		if inv.Warnings[i].Err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", withValidateHint(inv.Warnings[i].Err))
+			continue
+		}
+		ui.RenderLoadIssues(os.Stderr, inv.Warnings[i].Path, inv.Warnings[i].Issues)
+	}
+	return inv, nil
+}
+
+// includeOtherClients reports whether the servers of the other clients are
+// shown next to Claude Code's: only when no client, config or profile was chosen.
+func includeOtherClients(loc *mcptidy.Source) bool {
+	return rootClient == "" && rootConfigPath == "" && loc.Profile == ""
+}
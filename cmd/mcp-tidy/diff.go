@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/diff"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// diffContext is the number of context lines around each change.
+const diffContext = 3
+
+// configPatch returns a unified diff of the mcpServers objects that adding
+// or updating upsert and removing remove would change in the config.
+func configPatch(configPath string, upsert, remove []types.MCPServer) (string, error) {
+	changes, err := config.PreviewChanges(configPath, upsert, remove)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i := range changes {
+		label := configPath + ": " + changes[i].Path
+		b.WriteString(diff.Unified(label, label, changes[i].Before, changes[i].After, diffContext))
+	}
+	return b.String(), nil
+}
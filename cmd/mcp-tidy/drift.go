@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/manifest"
+	"github.com/nnnkkk7/mcp-tidy/policy"
+	"github.com/nnnkkk7/mcp-tidy/types"
+	"github.com/nnnkkk7/mcp-tidy/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	driftApply     bool
+	driftKeepExtra bool
+	driftYes       bool
+	driftJSON      bool
+	driftDiffOnly  bool
+)
+
+var driftCmd = &cobra.Command{
+	Use:   "drift [manifest]",
+	Short: "Compare MCP servers with a team manifest",
+	Long: `Compare ~/.claude.json with a manifest of the servers your team has agreed
+on, and report missing, extra and changed servers.
+
+The manifest (default: ` + manifest.FileName + ` in the current directory) lists
+the expected servers:
+
+  servers:
+    - name: context7
+      url: https://mcp.context7.com/mcp
+    - name: serena
+      project: .          # relative to the manifest
+      command: uvx
+      args: [--from, "git+https://github.com/oraios/serena", serena, start-mcp-server]
+
+Global servers are always compared; project servers only for the projects
+listed in the manifest.
+
+With --apply, the config is changed to match: missing servers are added,
+changed servers are updated (keeping their env and headers) and extra servers
+are removed, unless --keep-extra is given or the server is protected in
+.mcp-tidy.yaml. Servers that the administrator's managed-settings.json
+doesn't allow are not added. A backup is created first, and a diff of the
+mcpServers entries that change is shown. --diff-only prints just that patch
+without changing anything.
+
+Exit codes: 0 when the config matches (or was changed to match with --apply),
+3 when differences were found, 1 on errors.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDrift,
+}
+
+func init() {
+	driftCmd.Flags().BoolVar(&driftApply, "apply", false, "Change the config to match the manifest")
+	driftCmd.Flags().BoolVar(&driftKeepExtra, "keep-extra", false, "Don't remove servers missing from the manifest with --apply")
+	driftCmd.Flags().BoolVarP(&driftYes, "yes", "y", false, "Apply without confirmation")
+	driftCmd.Flags().BoolVar(&driftJSON, "json", false, "Output in JSON format")
+	driftCmd.Flags().BoolVar(&driftDiffOnly, "diff-only", false, "Print the changes --apply would make as a unified diff")
+}
+
+func runDrift(_ *cobra.Command, args []string) error {
+	manifestPath := manifest.FileName
+	if len(args) > 0 {
+		manifestPath = args[0]
+	}
+	if driftJSON && (driftApply || driftDiffOnly) {
+		return fmt.Errorf("--json cannot be combined with --apply or --diff-only")
+	}
+
+	m, err := manifest.Load(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	loc, err := resolveLocation()
+	if err != nil {
+		return err
+	}
+	configPath := loc.ConfigPath
+	cfg, err := loadConfig(loc.Client, configPath)
+	if err != nil {
+		return err
+	}
+
+	// The expected servers are written in the schema of the compared client
+	if loc.Client.Name() != config.ClientClaudeCode {
+		m.Client = loc.Client.Name()
+	}
+	drifts := m.Compare(cfg.Servers())
+
+	if driftDiffOnly {
+		return outputDriftPatch(configPath, cfg.Servers(), drifts)
+	}
+	if err := outputDrift(manifestPath, drifts); err != nil {
+		return err
+	}
+	if len(drifts) == 0 {
+		return nil
+	}
+	if !driftApply {
+		return &exitError{code: exitCodeFindings}
+	}
+	return applyDrift(configPath, cfg.Servers(), drifts)
+}
+
+// outputDrift reports the drifts as a table, or as JSON with --json.
+func outputDrift(manifestPath string, drifts []manifest.Drift) error {
+	if driftJSON {
+		return outputDriftJSON(os.Stdout, manifestPath, drifts)
+	}
+	ui.RenderDrift(os.Stdout, manifestPath, drifts)
+	return nil
+}
+
+// applyDrift changes the config to match the manifest after showing the
+// patch and asking for confirmation.
+func applyDrift(configPath string, configured []types.MCPServer, drifts []manifest.Drift) error {
+	set, err := policy.Load(policy.DefaultUserPath(), configured)
+	if err != nil {
+		return err
+	}
+	upsert, remove := planDriftChanges(os.Stdout, drifts, driftKeepExtra, set)
+	upsert, err = allowedByManagedPolicy(os.Stdout, upsert)
+	if err != nil {
+		return err
+	}
+	if len(upsert) == 0 && len(remove) == 0 {
+		fmt.Println("\nNothing to apply.")
+		return &exitError{code: exitCodeFindings}
+	}
+
+	patch, err := configPatch(configPath, upsert, remove)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	ui.RenderDiff(os.Stdout, patch)
+	ui.RenderClaudeWarning(os.Stdout, configPath, config.ClaudeProcesses(configPath))
+
+	prompt := fmt.Sprintf("\nApply %d change(s) to %s?", len(upsert)+len(remove), configPath)
+	if !driftYes && !ui.ConfirmPrompt(prompt, false) {
+		fmt.Println("Canceled.")
+		return &exitError{code: exitCodeFindings}
+	}
+
+	result, err := config.ApplyChanges(configPath, upsert, remove)
+	if err != nil {
+		return err
+	}
+	recordOperation(configPath, result, 0)
+	ui.RenderApplySummary(os.Stdout, upsert, remove)
+	return nil
+}
+
+// outputDriftPatch prints the changes --apply would make as a unified diff.
+func outputDriftPatch(configPath string, configured []types.MCPServer, drifts []manifest.Drift) error {
+	if len(drifts) == 0 {
+		return nil
+	}
+
+	set, err := policy.Load(policy.DefaultUserPath(), configured)
+	if err != nil {
+		return err
+	}
+	// Notes go to stderr so stdout is a clean patch
+	upsert, remove := planDriftChanges(os.Stderr, drifts, driftKeepExtra, set)
+	upsert, err = allowedByManagedPolicy(os.Stderr, upsert)
+	if err != nil {
+		return err
+	}
+	patch, err := configPatch(configPath, upsert, remove)
+	if err != nil {
+		return err
+	}
+	fmt.Print(patch)
+	return &exitError{code: exitCodeFindings}
+}
+
+// planDriftChanges returns the servers to add or update and the servers to
+// remove so the config matches the manifest. Protected servers are kept,
+// which is reported to w.
+func planDriftChanges(w io.Writer, drifts []manifest.Drift, keepExtra bool, set *policy.Set) ([]types.MCPServer, []types.MCPServer) {
+	var upsert, remove []types.MCPServer
+	var kept []string
+	for i := range drifts {
+		d := &drifts[i]
+		switch d.Kind {
+		case manifest.KindMissing, manifest.KindChanged:
+			upsert = append(upsert, d.Server)
+		case manifest.KindExtra:
+			if keepExtra {
+				continue
+			}
+			if _, ok := set.Protected(&d.Server); ok {
+				kept = append(kept, d.Server.Name)
+				continue
+			}
+			remove = append(remove, d.Server)
+		}
+	}
+
+	if len(kept) > 0 {
+		fmt.Fprintf(w, "\nKeeping protected server(s): %s\n", strings.Join(kept, ", "))
+	}
+	return upsert, remove
+}
+
+type driftOutput struct {
+	Manifest string             `json:"manifest"`
+	InSync   bool               `json:"inSync"`
+	Drift    []driftEntryOutput `json:"drift"`
+}
+
+type driftEntryOutput struct {
+	Kind    string   `json:"kind"`
+	Name    string   `json:"name"`
+	Scope   string   `json:"scope"`
+	Project string   `json:"project,omitempty"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+func outputDriftJSON(w io.Writer, manifestPath string, drifts []manifest.Drift) error {
+	output := driftOutput{
+		Manifest: manifestPath,
+		InSync:   len(drifts) == 0,
+		Drift:    make([]driftEntryOutput, 0, len(drifts)),
+	}
+	for i := range drifts {
+		output.Drift = append(output.Drift, driftEntryOutput{
+			Kind:    drifts[i].Kind.String(),
+			Name:    drifts[i].Server.Name,
+			Scope:   drifts[i].Server.Scope.String(),
+			Project: drifts[i].Server.ProjectPath,
+			Changes: drifts[i].Changes,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
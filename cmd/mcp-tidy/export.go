@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/bundle"
+	"github.com/nnnkkk7/mcp-tidy/selection"
+	"github.com/nnnkkk7/mcp-tidy/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutput  string
+	exportKeepEnv []string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [selector...]",
+	Short: "Export MCP server definitions to a portable file",
+	Long: `Export MCP server definitions from ~/.claude.json, from all scopes and with
+their project paths, to a file that can be imported on another machine.
+
+Secrets are not exported: env and header values are replaced by ${NAME}
+placeholders, as are secret-looking URL query parameters and arguments such
+as --api-key. 'mcp-tidy import' fills placeholders in from the environment.
+
+Selectors use the same syntax as 'remove' (names, globs, 'global',
+'project:/path', exclusions with '!'); without selectors every server is
+exported.`,
+	Example: `  mcp-tidy export -o mcp-servers.json
+  mcp-tidy export global 'project:/Users/me/work/*' --keep-env NODE_ENV -o mcp-servers.json`,
+	RunE: runExport,
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "-", "Write to this file instead of stdout")
+	exportCmd.Flags().StringSliceVar(&exportKeepEnv, "keep-env", nil, "Env variables whose values are exported as they are")
+}
+
+func runExport(_ *cobra.Command, args []string) error {
+	loc, err := resolveLocation()
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(loc.Client, loc.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	servers, err := selectServers(cfg.Servers(), args)
+	if err != nil {
+		return err
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("no MCP servers to export")
+	}
+
+	b := bundle.Export(servers, bundle.ExportOptions{KeepEnv: exportKeepEnv})
+	if err := bundle.Write(exportOutput, b); err != nil {
+		return err
+	}
+	if exportOutput != "-" {
+		fmt.Fprintf(os.Stderr, "Exported %d server(s) to %s\n", len(b.Servers), exportOutput)
+	}
+	return nil
+}
+
+// selectServers returns the servers matched by selector arguments, or all
+// servers without arguments, ordered by scope and name.
+func selectServers(servers []types.MCPServer, args []string) ([]types.MCPServer, error) {
+	sorted := make([]types.MCPServer, len(servers))
+	copy(sorted, servers)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Key() < sorted[j].Key()
+	})
+	if len(args) == 0 {
+		return sorted, nil
+	}
+
+	selectedIdx, err := selection.Parse(strings.Join(args, " "), sorted, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]types.MCPServer, 0, len(selectedIdx))
+	for _, idx := range selectedIdx {
+		selected = append(selected, sorted[idx])
+	}
+	return selected, nil
+}
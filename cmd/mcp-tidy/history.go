@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/journal"
+	"github.com/nnnkkk7/mcp-tidy/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyLimit int
+	historyJSON  bool
+	undoYes      bool
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the changes mcp-tidy made to the config",
+	Long: `List the operations recorded in the journal: when they ran, the command
+line, and which servers or settings they added, changed or removed.
+
+Every command that writes the config (remove, drift --apply, import, undo)
+or a settings file (disable, enable, install-hook) records an entry,
+including the full previous definition of each server or setting, so it can
+be reverted with 'mcp-tidy undo'.`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "Revert the last (or nth) operation",
+	Long: `Revert an operation from 'mcp-tidy history': the last one that is not
+undone yet, or operation n.
+
+Only the servers or settings the operation touched are restored, so changes
+Claude Code made to the rest of the file since then are kept. A server or
+setting that was changed again since the operation is skipped, to not lose
+that change.
+
+Exit codes: 0 when the operation was reverted, 2 when nothing was reverted,
+1 on errors.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runUndo,
+}
+
+func init() {
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Show at most this many recent operations (0 for all)")
+	historyCmd.Flags().BoolVar(&historyJSON, "json", false, "Output in JSON format")
+	undoCmd.Flags().BoolVarP(&undoYes, "yes", "y", false, "Undo without confirmation")
+}
+
+func runHistory(_ *cobra.Command, _ []string) error {
+	entries, err := journal.Load(journal.DefaultPath())
+	if err != nil {
+		return err
+	}
+	undoneBy := journal.UndoneBy(entries)
+	if historyLimit > 0 && len(entries) > historyLimit {
+		entries = entries[len(entries)-historyLimit:]
+	}
+
+	if historyJSON {
+		return outputHistoryJSON(os.Stdout, entries, undoneBy)
+	}
+	ui.RenderHistory(os.Stdout, entries, undoneBy)
+	return nil
+}
+
+func runUndo(_ *cobra.Command, args []string) error {
+	entries, err := journal.Load(journal.DefaultPath())
+	if err != nil {
+		return err
+	}
+
+	target, err := findUndoTarget(entries, args)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		fmt.Println("No operations to undo.")
+		return errNothingChanged
+	}
+
+	fmt.Printf("Undo #%d (%s) in %s:\n", target.ID, target.Command, target.Config)
+	ui.RenderServerChanges(os.Stdout, target.Changes)
+	if !undoYes && !ui.ConfirmPrompt("\nRevert these changes?", false) {
+		fmt.Println("Canceled.")
+		return errNothingChanged
+	}
+
+	result, conflicts, err := config.Revert(target.Config, target.Changes)
+	if err != nil {
+		return err
+	}
+	for i := range conflicts {
+		fmt.Printf("Skipped %s: it was changed again after #%d\n", conflicts[i].Name, target.ID)
+	}
+	if len(result.Changes) == 0 {
+		fmt.Println("Nothing was reverted.")
+		return errNothingChanged
+	}
+
+	recordOperation(target.Config, result, target.ID)
+	fmt.Printf("\nReverted #%d:\n", target.ID)
+	ui.RenderServerChanges(os.Stdout, result.Changes)
+	return nil
+}
+
+// findUndoTarget returns the entry to undo: the one given as argument, or
+// the last one not undone yet. It returns nil when there is nothing to undo.
+func findUndoTarget(entries []journal.Entry, args []string) (*journal.Entry, error) {
+	if len(args) == 0 {
+		return journal.LastUndoable(entries), nil
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil || id < 1 || id > len(entries) {
+		return nil, fmt.Errorf("invalid operation %q (see 'mcp-tidy history')", args[0])
+	}
+	if by := journal.UndoneBy(entries)[id]; by > 0 {
+		return nil, fmt.Errorf("operation #%d was already undone by #%d", id, by)
+	}
+	return &entries[id-1], nil
+}
+
+// recordOperation names the backup taken by a write and appends the write to
+// the journal. undoOf is the ID of the operation it reverted, or 0. Failing
+// to record doesn't fail the command, as the config has already been written.
+func recordOperation(configPath string, result *config.WriteResult, undoOf int) {
+	if result == nil || len(result.Changes) == 0 {
+		return
+	}
+	if result.Backup != "" {
+		fmt.Printf("Backup created: %s\n", result.Backup)
+	}
+
+	entry := &journal.Entry{
+		Time:    time.Now(),
+		Command: "mcp-tidy " + strings.Join(os.Args[1:], " "),
+		Config:  configPath,
+		Backup:  result.Backup,
+		Changes: result.Changes,
+		UndoOf:  undoOf,
+	}
+	if err := journal.Append(journal.DefaultPath(), entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record the operation for undo: %v\n", err)
+	}
+}
+
+type historyEntryOutput struct {
+	ID int `json:"id"`
+	journal.Entry
+	UndoneBy int `json:"undoneBy,omitempty"`
+}
+
+func outputHistoryJSON(w io.Writer, entries []journal.Entry, undoneBy map[int]int) error {
+	output := make([]historyEntryOutput, 0, len(entries))
+	for i := range entries {
+		output = append(output, historyEntryOutput{ID: entries[i].ID, Entry: entries[i], UndoneBy: undoneBy[entries[i].ID]})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/usagelog"
+	"github.com/spf13/cobra"
+)
+
+// hookEvents are the Claude Code hook events install-hook registers for:
+// calls that succeeded and calls that failed.
+var hookEvents = []string{"PostToolUse", "PostToolUseFailure"}
+
+// hookMatcher limits the hook to MCP tools, so other tool calls don't start
+// mcp-tidy.
+const hookMatcher = "mcp__.*"
+
+var installHookCommand string
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Record an MCP tool call (run by Claude Code as a PostToolUse hook)",
+	Long: `Read the JSON Claude Code passes to a PostToolUse or PostToolUseFailure
+hook from stdin and record the MCP tool call in the usage log: server, tool,
+project, session, duration (when Claude Code reports it) and whether the call
+failed. Other tools are ignored.
+
+The usage log keeps calls after their transcripts are deleted, and 'stats',
+'remove --unused' and 'check' read it together with the transcripts. A call
+found in both is counted once. Register the hook with 'mcp-tidy install-hook'.`,
+	Args: cobra.NoArgs,
+	RunE: runHook,
+}
+
+var installHookCmd = &cobra.Command{
+	Use:   "install-hook",
+	Short: "Register 'mcp-tidy hook' in the Claude Code user settings",
+	Long: `Add 'mcp-tidy hook' as a PostToolUse and PostToolUseFailure hook for MCP
+tools to the Claude Code user settings (~/.claude/settings.json,
+$CLAUDE_CONFIG_DIR/settings.json or the settings of --profile), so every MCP
+tool call is recorded as it happens, including the calls that fail.
+
+The rest of the settings, including other hooks, is kept. The settings are
+backed up first and the change can be reverted with 'mcp-tidy undo'. By
+default the hook runs the absolute path of this mcp-tidy binary; use
+--command to register a different command line.
+
+Exit codes: 0 when the hook was added, 2 when it was already registered,
+1 on errors.`,
+	Args: cobra.NoArgs,
+	RunE: runInstallHook,
+}
+
+func init() {
+	installHookCmd.Flags().StringVar(&installHookCommand, "command", "", "Command line to register (default: this binary followed by 'hook')")
+}
+
+func runHook(cmd *cobra.Command, _ []string) error {
+	in, err := usagelog.ReadHookInput(cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+	record, ok := in.Record(time.Now())
+	if !ok {
+		return nil
+	}
+	return usagelog.Append(usagelog.DefaultPath(), record)
+}
+
+func runInstallHook(_ *cobra.Command, _ []string) error {
+	loc, err := resolveLocation()
+	if err != nil {
+		return err
+	}
+	if !loc.Client.HasTranscripts() {
+		return fmt.Errorf("%s has no hooks; install-hook works with %s", loc.Client.Title(), config.ClaudeCode{}.Title())
+	}
+
+	command := installHookCommand
+	if command == "" {
+		if command, err = hookCommandLine(); err != nil {
+			return err
+		}
+	}
+
+	result, err := config.AddCommandHook(loc.SettingsPath, hookEvents, hookMatcher, command)
+	if err != nil {
+		return err
+	}
+	if len(result.Changes) == 0 {
+		fmt.Printf("The hook is already registered in %s\n", loc.SettingsPath)
+		return errNothingChanged
+	}
+	fmt.Printf("Registered %s as a %s hook in %s\n", command, strings.Join(hookEvents, " and "), loc.SettingsPath)
+	recordOperation(loc.SettingsPath, result, 0)
+	fmt.Printf("MCP tool calls of new Claude Code sessions are recorded in %s\n", usagelog.DefaultPath())
+	return nil
+}
+
+// hookCommandLine returns the command line running this binary's hook
+// command, quoted for the POSIX shell Claude Code runs hooks in.
+func hookCommandLine() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the mcp-tidy binary: %w", err)
+	}
+	return shellQuote(exe) + " hook", nil
+}
+
+// shellQuote quotes s as a single word for a POSIX shell. Inside single
+// quotes nothing is special, so only single quotes need escaping: each one
+// ends the quoted part, is added escaped and starts a new one.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
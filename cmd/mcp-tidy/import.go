@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nnnkkk7/mcp-tidy/bundle"
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/types"
+	"github.com/nnnkkk7/mcp-tidy/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importOnConflict string
+	importMapPaths   []string
+	importDryRun     bool
+	importDiffOnly   bool
+	importYes        bool
+	importNoExpand   bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import MCP server definitions from an export file",
+	Long: `Merge MCP server definitions exported with 'mcp-tidy export' into
+~/.claude.json.
+
+When a server with the same name already exists in the same scope,
+--on-conflict decides what happens: skip keeps the existing server,
+overwrite replaces it and rename imports it as name-2 (name-3, ...).
+Servers that are already configured identically are left alone.
+
+${NAME} placeholders are filled in from the environment. Env and header
+placeholders that can't be resolved keep the value of the existing server;
+any other unresolved placeholders stay as they are and are reported.
+
+Servers that the allow and deny lists of the administrator's
+managed-settings.json don't permit are not imported.
+
+Use --map-path when project paths differ between machines; it also
+rewrites arguments that point into the project.
+
+Before writing, a diff of the mcpServers entries that change is shown (env
+and header values masked). --diff-only prints just that patch.
+
+Exit codes: 0 when servers were imported (or would be, with --dry-run or
+--diff-only), 2 when nothing was imported, 1 on errors.`,
+	Example: `  mcp-tidy import mcp-servers.json --dry-run
+  mcp-tidy import mcp-servers.json --on-conflict rename --map-path /Users/old=/home/new`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importOnConflict, "on-conflict", "skip", "What to do with existing servers (skip, overwrite, rename)")
+	importCmd.Flags().StringArrayVar(&importMapPaths, "map-path", nil, "Map project paths, OLD=NEW (repeatable)")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Show what would change without writing")
+	importCmd.Flags().BoolVar(&importDiffOnly, "diff-only", false, "Print the config change as a unified diff without writing")
+	importCmd.Flags().BoolVarP(&importYes, "yes", "y", false, "Import without confirmation")
+	importCmd.Flags().BoolVar(&importNoExpand, "no-expand", false, "Don't fill in ${NAME} placeholders from the environment")
+}
+
+func runImport(_ *cobra.Command, args []string) error {
+	opts, err := importOptions()
+	if err != nil {
+		return err
+	}
+
+	b, err := bundle.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	loc, err := resolveLocation()
+	if err != nil {
+		return err
+	}
+	configPath := loc.ConfigPath
+	cfg, err := loadConfig(loc.Client, configPath)
+	if err != nil {
+		return err
+	}
+
+	plan := bundle.Plan(b, cfg.Servers(), opts)
+	upserts := bundle.Upserts(plan)
+	patch, err := configPatch(configPath, upserts, nil)
+	if err != nil {
+		return err
+	}
+	if importDiffOnly {
+		if len(upserts) == 0 {
+			return errNothingChanged
+		}
+		fmt.Print(patch)
+		return nil
+	}
+
+	ui.RenderImportPlan(os.Stdout, plan)
+	warnMissingProjects(plan)
+
+	if len(upserts) == 0 {
+		fmt.Println("Nothing to import.")
+		return errNothingChanged
+	}
+	ui.RenderDiff(os.Stdout, patch)
+	if importDryRun {
+		fmt.Println("[DRY RUN] Run without --dry-run to import these servers.")
+		return nil
+	}
+	ui.RenderClaudeWarning(os.Stdout, configPath, config.ClaudeProcesses(configPath))
+
+	prompt := fmt.Sprintf("Import %d server(s) into %s?", len(upserts), configPath)
+	if !importYes && !ui.ConfirmPrompt(prompt, false) {
+		fmt.Println("Canceled.")
+		return errNothingChanged
+	}
+
+	result, err := config.ApplyChanges(configPath, upserts, nil)
+	if err != nil {
+		return err
+	}
+	recordOperation(configPath, result, 0)
+	ui.RenderApplySummary(os.Stdout, upserts, nil)
+	return nil
+}
+
+// importOptions builds the import options from the flags.
+func importOptions() (bundle.ImportOptions, error) {
+	var opts bundle.ImportOptions
+
+	onConflict, err := bundle.ParseConflictPolicy(importOnConflict)
+	if err != nil {
+		return opts, err
+	}
+	opts.OnConflict = onConflict
+
+	for _, raw := range importMapPaths {
+		mapping, err := bundle.ParsePathMapping(raw)
+		if err != nil {
+			return opts, err
+		}
+		opts.PathMappings = append(opts.PathMappings, mapping)
+	}
+
+	if !importNoExpand {
+		opts.LookupEnv = os.LookupEnv
+	}
+
+	managed, err := config.LoadManagedPolicy(config.DefaultManagedDir())
+	if err != nil {
+		return opts, err
+	}
+	opts.Allow = managed.Check
+	return opts, nil
+}
+
+// warnMissingProjects warns about imported project servers whose project
+// directory doesn't exist on this machine, which usually means --map-path is needed.
+func warnMissingProjects(plan []bundle.PlannedServer) {
+	warned := make(map[string]bool)
+	for i := range plan {
+		server := &plan[i].Server
+		if !plan[i].Action.Writes() || server.Scope != types.ScopeProject || warned[server.ProjectPath] {
+			continue
+		}
+		if _, err := os.Stat(server.ProjectPath); err != nil {
+			fmt.Printf("Warning: project %s does not exist on this machine (use --map-path OLD=NEW)\n", server.ProjectPath)
+			warned[server.ProjectPath] = true
+		}
+	}
+}
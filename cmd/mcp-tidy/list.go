@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
 
 	"github.com/nnnkkk7/mcp-tidy/config"
@@ -8,26 +10,93 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	listAllProfiles bool
+	listClients     bool
+)
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List configured MCP servers",
 	Long: `Display all MCP servers configured in ~/.claude.json.
 
-Shows both global servers and project-specific servers with their
-scope, type, and command/URL.`,
+Shows global servers, project-specific servers, the servers projects share
+through .mcp.json and the servers your administrator deploys through
+managed-mcp.json, with their scope, type, and command/URL. .mcp.json
+servers that are disabled or not approved yet in Claude Code's settings files
+are marked as such. With --all-profiles, the servers of every profile in
+.mcp-tidy.yaml are listed in one table with a profile column.
+
+The servers of Claude Desktop, Cursor (~/.cursor/mcp.json and .cursor/mcp.json),
+VS Code (mcp.json and .vscode/mcp.json), Codex (~/.codex/config.toml) and
+Gemini CLI (~/.gemini/settings.json and .gemini/settings.json) are listed too,
+with a client column. With --clients, an overview shows which clients define each server,
+and marks definitions that connect differently.`,
 	RunE: runList,
 }
 
-func runList(_ *cobra.Command, _ []string) error {
-	configPath := config.DefaultConfigPath()
+func init() {
+	listCmd.Flags().BoolVar(&listAllProfiles, "all-profiles", false, "List the servers of every profile")
+	listCmd.Flags().BoolVar(&listClients, "clients", false, "Show which clients define each server")
+}
+
+func runList(cmd *cobra.Command, _ []string) error {
+	if listAllProfiles {
+		return runListAllProfiles(cmd.Context())
+	}
+	if listClients {
+		return runListClients(cmd.Context())
+	}
+
+	loc, err := resolveLocation()
+	if err != nil {
+		return err
+	}
+
+	inv, err := configuredServers(cmd.Context(), loc, includeOtherClients(loc))
+	if err != nil {
+		return err
+	}
+	ui.RenderServerTable(os.Stdout, inv.Servers)
+
+	return nil
+}
 
-	cfg, err := config.Load(configPath)
+func runListAllProfiles(ctx context.Context) error {
+	locations, err := resolveAllLocations()
 	if err != nil {
 		return err
 	}
 
-	servers := cfg.Servers()
-	ui.RenderServerTable(os.Stdout, servers)
+	profiles := make([]ui.ProfileServers, 0, len(locations))
+	for i := range locations {
+		inv, err := configuredServers(ctx, &locations[i], false)
+		if err != nil {
+			return err
+		}
+		profiles = append(profiles, ui.ProfileServers{Profile: locations[i].Profile, Servers: inv.Servers})
+	}
+
+	ui.RenderProfileServerTable(os.Stdout, profiles)
+	return nil
+}
 
+// runListClients shows which clients define each server. The Claude Code
+// servers come from the selected config or profile; the other clients' from
+// their default files.
+func runListClients(ctx context.Context) error {
+	if rootClient != "" {
+		return errors.New("--clients shows every client, so it cannot be combined with --client")
+	}
+	loc, err := resolveLocation()
+	if err != nil {
+		return err
+	}
+
+	inv, err := configuredServers(ctx, loc, true)
+	if err != nil {
+		return err
+	}
+	ui.RenderClientOverview(os.Stdout, inv.Servers, config.Clients())
 	return nil
 }
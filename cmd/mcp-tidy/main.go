@@ -2,17 +2,48 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/mcptidy"
+	"github.com/nnnkkk7/mcp-tidy/transcript"
 	"github.com/spf13/cobra"
 )
 
 // Version is set at build time
 var Version = "dev"
 
+const (
+	// exitCodeError is returned when a command fails.
+	exitCodeError = 1
+	// exitCodeNothingChanged is returned when a command succeeds without changing anything.
+	exitCodeNothingChanged = 2
+	// exitCodeFindings is returned when check finds violations or drift finds differences.
+	exitCodeFindings = 3
+)
+
+// exitError makes the process exit with a specific code without printing an error.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// errNothingChanged reports that a mutating command finished without changes.
+var errNothingChanged = &exitError{code: exitCodeNothingChanged}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitCodeError)
 	}
 }
 
@@ -23,10 +54,37 @@ var rootCmd = &cobra.Command{
 
 Like 'go mod tidy', it helps keep your MCP configuration clean and organized.`,
 	Version: Version,
+	// Errors are printed by main, so exit codes can be reported without a message.
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
+var (
+	rootConfigPath  string
+	rootTranscripts []string
+	rootProfile     string
+	rootClient      string
+)
+
 func init() {
+	rootCmd.PersistentFlags().StringVar(&rootConfigPath, "config", "", "Claude config file (default $"+config.ConfigPathEnv+", $"+config.ClaudeConfigDirEnv+"/.claude.json or ~/.claude.json)")
+	rootCmd.PersistentFlags().StringSliceVar(&rootTranscripts, "transcripts", nil, "Transcript directories to read usage from, merged; repeatable (default $"+transcript.PathsEnv+" or ~/.claude/projects)")
+	rootCmd.PersistentFlags().StringVar(&rootProfile, "profile", "", "Profile from .mcp-tidy.yaml to work on (default $"+mcptidy.ProfileEnv+")")
+	rootCmd.PersistentFlags().StringVar(&rootClient, "client", "", "Client whose config to work on ("+config.ClientNames()+"; default "+config.ClientClaudeCode+")")
+
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(statsCmd)
 	rootCmd.AddCommand(removeCmd)
+	rootCmd.AddCommand(disableCmd)
+	rootCmd.AddCommand(enableCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(driftCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(hookCmd)
+	rootCmd.AddCommand(installHookCmd)
 }
@@ -3,6 +3,8 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,10 +12,17 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/nnnkkk7/mcp-tidy/check"
 	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/journal"
+	"github.com/nnnkkk7/mcp-tidy/manifest"
+	"github.com/nnnkkk7/mcp-tidy/mcptidy"
+	"github.com/nnnkkk7/mcp-tidy/policy"
 	"github.com/nnnkkk7/mcp-tidy/transcript"
 	"github.com/nnnkkk7/mcp-tidy/types"
 	"github.com/nnnkkk7/mcp-tidy/ui"
+	"github.com/nnnkkk7/mcp-tidy/usagelog"
 )
 
 // Integration tests for mcp-tidy commands
@@ -252,7 +261,7 @@ func TestRemoveCommand_Force(t *testing.T) {
 	toRemove := []types.MCPServer{cfg.Servers()[0]}
 
 	// Actually remove (force mode - no confirmation needed)
-	if err := config.RemoveServers(tmpConfig, toRemove); err != nil {
+	if _, err := config.RemoveServers(tmpConfig, toRemove); err != nil {
 		t.Fatalf("failed to remove servers: %v", err)
 	}
 
@@ -300,39 +309,6 @@ func TestRemoveCommand_Unused(t *testing.T) {
 	t.Logf("Total servers: %d, Unused: %d", len(servers), len(unused))
 }
 
-func TestSortStats(t *testing.T) {
-	stats := []types.ServerStats{
-		{Name: "zebra", Calls: 10, LastUsed: time.Now().Add(-1 * time.Hour)},
-		{Name: "alpha", Calls: 100, LastUsed: time.Now().Add(-24 * time.Hour)},
-		{Name: "beta", Calls: 50, LastUsed: time.Now()},
-	}
-
-	tests := []struct {
-		name      string
-		sortBy    string
-		wantFirst string
-	}{
-		{"sort by calls (default)", "calls", "alpha"},
-		{"sort by name", "name", "alpha"},
-		{"sort by last-used", "last-used", "beta"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Make a copy to avoid mutating original
-			statsCopy := make([]types.ServerStats, len(stats))
-			copy(statsCopy, stats)
-
-			sortStats(statsCopy, tt.sortBy)
-
-			if statsCopy[0].Name != tt.wantFirst {
-				t.Errorf("sortStats(%q) first element = %q, want %q",
-					tt.sortBy, statsCopy[0].Name, tt.wantFirst)
-			}
-		})
-	}
-}
-
 func TestStatsOutput_JSON(t *testing.T) {
 	now := time.Now()
 	stats := []types.ServerStats{
@@ -370,205 +346,51 @@ func TestStatsOutput_JSON(t *testing.T) {
 	}
 }
 
-func TestMergeConfiguredServers(t *testing.T) {
-	tests := []struct {
-		name        string
-		stats       []types.ServerStats
-		servers     []types.MCPServer
-		wantCount   int
-		wantServers []string
-	}{
-		{
-			name: "adds configured server with no stats",
-			stats: []types.ServerStats{
-				{Name: "context7", Calls: 10},
-			},
-			servers: []types.MCPServer{
-				{Name: "context7"},
-				{Name: "serena"},
-				{Name: "puppeteer"},
-			},
-			wantCount:   3,
-			wantServers: []string{"context7", "serena", "puppeteer"},
-		},
-		{
-			name:  "all configured servers have no stats",
-			stats: []types.ServerStats{},
-			servers: []types.MCPServer{
-				{Name: "new-server"},
-				{Name: "another-server"},
-			},
-			wantCount:   2,
-			wantServers: []string{"new-server", "another-server"},
-		},
-		{
-			name: "no new servers to add",
-			stats: []types.ServerStats{
-				{Name: "context7", Calls: 10},
-				{Name: "serena", Calls: 5},
-			},
-			servers: []types.MCPServer{
-				{Name: "context7"},
-				{Name: "serena"},
-			},
-			wantCount:   2,
-			wantServers: []string{"context7", "serena"},
-		},
-		{
-			name: "duplicate server names in config are handled",
-			stats: []types.ServerStats{
-				{Name: "context7", Calls: 10},
-			},
-			servers: []types.MCPServer{
-				{Name: "context7", Scope: types.ScopeGlobal},
-				{Name: "context7", Scope: types.ScopeProject}, // same name, different scope
-				{Name: "serena"},
-			},
-			wantCount:   2, // context7 appears once, serena once
-			wantServers: []string{"context7", "serena"},
-		},
+func TestBuildStatsOutput_Unavailable(t *testing.T) {
+	desktop := types.MCPServer{Name: "context7", Scope: types.ScopeGlobal, Client: config.ClientClaudeDesktop}
+	usage := &mcptidy.Usage{
+		Stats:       []types.ServerStats{{Name: "context7", Calls: 3, LastUsed: time.Now()}},
+		Verdicts:    map[string]types.UnusedVerdict{desktop.Key(): {Unavailable: true, Reason: "usage unavailable"}},
+		Period:      30 * 24 * time.Hour,
+		PeriodLabel: "30d",
 	}
+	output := buildStatsOutput(mcptidy.Join([]types.MCPServer{desktop}, usage))
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := mergeConfiguredServers(tt.stats, tt.servers)
-
-			if len(result) != tt.wantCount {
-				t.Errorf("mergeConfiguredServers() returned %d servers, want %d", len(result), tt.wantCount)
-			}
-
-			// Check all expected servers are present
-			serverNames := make(map[string]bool)
-			for _, s := range result {
-				serverNames[s.Name] = true
-			}
-
-			for _, name := range tt.wantServers {
-				if !serverNames[name] {
-					t.Errorf("expected server %q not found in result", name)
-				}
-			}
-		})
+	// The calls belong to a Claude Code server of the same name, not to the Desktop one
+	wantSummary := mcptidy.Categories{NotConfigured: 1, Unavailable: 1}
+	if diff := cmp.Diff(wantSummary, output.Categories); diff != "" {
+		t.Errorf("categories mismatch (-want +got):\n%s", diff)
+	}
+	want := serverVerdictOutput{Name: "context7", Client: config.ClientClaudeDesktop, Scope: "global", Type: "stdio", Status: "active", Unavailable: true, Reason: "usage unavailable"}
+	if diff := cmp.Diff([]serverVerdictOutput{want}, output.Verdicts); diff != "" {
+		t.Errorf("verdicts mismatch (-want +got):\n%s", diff)
 	}
 }
 
-func TestMergeConfiguredServers_ZeroCalls(t *testing.T) {
-	// Verify that newly added servers have 0 calls
-	stats := []types.ServerStats{
-		{Name: "context7", Calls: 100},
+func TestAllowedByManagedPolicy(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(config.ManagedDirEnv, dir)
+	settings := `{"deniedMcpServers": [{"serverName": "puppeteer"}]}`
+	if err := os.WriteFile(filepath.Join(dir, config.ManagedSettingsFileName), []byte(settings), 0o644); err != nil {
+		t.Fatalf("failed to write managed settings: %v", err)
 	}
+
 	servers := []types.MCPServer{
-		{Name: "context7"},
-		{Name: "unused-server"},
+		{Name: "context7", Scope: types.ScopeGlobal},
+		{Name: "puppeteer", Scope: types.ScopeGlobal},
 	}
-
-	result := mergeConfiguredServers(stats, servers)
-
-	for _, s := range result {
-		if s.Name == "unused-server" {
-			if s.Calls != 0 {
-				t.Errorf("unused-server should have 0 calls, got %d", s.Calls)
-			}
-			if !s.LastUsed.IsZero() {
-				t.Errorf("unused-server should have zero LastUsed, got %v", s.LastUsed)
-			}
-			return
-		}
+	var buf bytes.Buffer
+	result, err := allowedByManagedPolicy(&buf, servers)
+	if err != nil {
+		t.Fatalf("allowedByManagedPolicy() error = %v", err)
 	}
-	t.Error("unused-server not found in result")
-}
-
-func TestFilterServersForRemoval(t *testing.T) {
-	now := time.Now()
-	period := types.Period30Days
 
-	tests := []struct {
-		name         string
-		servers      []types.MCPServer
-		statsMap     map[string]types.ServerStats
-		removeUnused bool
-		wantCount    int
-		wantNames    []string
-	}{
-		{
-			name: "returns all servers when removeUnused is false",
-			servers: []types.MCPServer{
-				{Name: "used", Scope: types.ScopeGlobal},
-				{Name: "unused", Scope: types.ScopeGlobal},
-			},
-			statsMap: map[string]types.ServerStats{
-				"used":   {Name: "used", Calls: 100, LastUsed: now},
-				"unused": {Name: "unused", Calls: 0},
-			},
-			removeUnused: false,
-			wantCount:    2,
-			wantNames:    []string{"used", "unused"},
-		},
-		{
-			name: "filters only unused servers when removeUnused is true",
-			servers: []types.MCPServer{
-				{Name: "used", Scope: types.ScopeGlobal},
-				{Name: "unused-no-stats", Scope: types.ScopeGlobal},
-				{Name: "unused-zero-calls", Scope: types.ScopeGlobal},
-			},
-			statsMap: map[string]types.ServerStats{
-				"used":              {Name: "used", Calls: 100, LastUsed: now},
-				"unused-zero-calls": {Name: "unused-zero-calls", Calls: 0, LastUsed: time.Time{}},
-			},
-			removeUnused: true,
-			wantCount:    2,
-			wantNames:    []string{"unused-no-stats", "unused-zero-calls"},
-		},
-		{
-			name: "filters old usage as unused",
-			servers: []types.MCPServer{
-				{Name: "recent", Scope: types.ScopeGlobal},
-				{Name: "old", Scope: types.ScopeGlobal},
-			},
-			statsMap: map[string]types.ServerStats{
-				"recent": {Name: "recent", Calls: 100, LastUsed: now.Add(-1 * 24 * time.Hour)}, // 1 day ago
-				"old":    {Name: "old", Calls: 50, LastUsed: now.Add(-60 * 24 * time.Hour)},    // 60 days ago
-			},
-			removeUnused: true,
-			wantCount:    1,
-			wantNames:    []string{"old"},
-		},
+	want := []types.MCPServer{servers[0]}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("allowedByManagedPolicy() mismatch (-want +got):\n%s", diff)
 	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Set global flag
-			removeUnused = tt.removeUnused
-
-			result := filterServersForRemoval(tt.servers, tt.statsMap, period)
-
-			// For removeUnused=false, it returns input as-is
-			if !tt.removeUnused {
-				if len(result) != len(tt.servers) {
-					t.Errorf("expected %d servers, got %d", len(tt.servers), len(result))
-				}
-				return
-			}
-
-			if len(result) != tt.wantCount {
-				t.Errorf("expected %d servers, got %d", tt.wantCount, len(result))
-			}
-
-			// Check expected names
-			resultNames := make(map[string]bool)
-			for _, s := range result {
-				resultNames[s.Name] = true
-			}
-
-			for _, name := range tt.wantNames {
-				if !resultNames[name] {
-					t.Errorf("expected server %q in result", name)
-				}
-			}
-
-			// Reset flag
-			removeUnused = false
-		})
+	if !strings.Contains(buf.String(), "Skipping puppeteer: not allowed by the managed policy") {
+		t.Errorf("output = %q, want a note about puppeteer", buf.String())
 	}
 }
 
@@ -586,7 +408,7 @@ func TestStatsCommand_GroupedOutput(t *testing.T) {
 	}
 
 	// Merge stats with servers
-	stats = mergeConfiguredServers(stats, servers)
+	stats = mcptidy.Join(servers, &mcptidy.Usage{Stats: stats}).ServerStats()
 
 	var buf bytes.Buffer
 	ui.RenderStatsTable(&buf, stats, types.PeriodAll.Duration(), servers)
@@ -613,31 +435,6 @@ func TestStatsCommand_GroupedOutput(t *testing.T) {
 	}
 }
 
-func TestFilterServersForRemoval_NoUnusedServers(t *testing.T) {
-	now := time.Now()
-	period := types.Period30Days
-
-	servers := []types.MCPServer{
-		{Name: "active1", Scope: types.ScopeGlobal},
-		{Name: "active2", Scope: types.ScopeProject, ProjectPath: "/project"},
-	}
-
-	statsMap := map[string]types.ServerStats{
-		"active1": {Name: "active1", Calls: 100, LastUsed: now},
-		"active2": {Name: "active2", Calls: 50, LastUsed: now},
-	}
-
-	removeUnused = true
-	defer func() { removeUnused = false }()
-
-	result := filterServersForRemoval(servers, statsMap, period)
-
-	// Should return nil when no unused servers
-	if result != nil {
-		t.Errorf("expected nil when no unused servers, got %d servers", len(result))
-	}
-}
-
 func TestSelectServersToRemove(t *testing.T) {
 	servers := []types.MCPServer{
 		{Name: "server1", Scope: types.ScopeGlobal},
@@ -721,3 +518,341 @@ func TestSelectServersToRemove(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRemoveFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		scope     string
+		project   string
+		yes       bool
+		allUnused bool
+		args      []string
+		wantErr   bool
+	}{
+		{name: "interactive defaults", wantErr: false},
+		{name: "invalid scope", scope: "local", wantErr: true},
+		{name: "project with global scope", scope: "global", project: "/p", wantErr: true},
+		{name: "yes without selection", yes: true, wantErr: true},
+		{name: "yes with selectors", yes: true, args: []string{"puppeteer"}, wantErr: false},
+		{name: "yes with all-unused", yes: true, allUnused: true, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removeScope, removeProject, removeYes, removeAllUnused = tt.scope, tt.project, tt.yes, tt.allUnused
+			defer func() {
+				removeScope, removeProject, removeYes, removeAllUnused = "", "", false, false
+			}()
+
+			err := validateRemoveFlags(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRemoveFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOutputCheckJUnit(t *testing.T) {
+	result := &check.Result{
+		Rules:   []string{check.RuleMaxServers, check.RuleRequirePins},
+		Servers: 2,
+		Violations: []check.Violation{
+			{Rule: check.RuleRequirePins, Server: "puppeteer", Scope: "global", Message: `npx runs "puppeteer" without a pinned version`},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := outputCheckJUnit(&buf, ".mcp.json", result); err != nil {
+		t.Fatalf("outputCheckJUnit() unexpected error: %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<testsuite name="mcp-tidy check .mcp.json" tests="2" failures="1">`,
+		`<testcase name="max-servers" classname="mcp-tidy.max-servers"></testcase>`,
+		`<testcase name="require-pins: puppeteer" classname="mcp-tidy.require-pins">`,
+		`<failure message="npx runs &#34;puppeteer&#34; without a pinned version" type="require-pins">`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q\nGot:\n%s", want, output)
+		}
+	}
+}
+
+func TestOutputCheckJSON(t *testing.T) {
+	result := &check.Result{Rules: []string{check.RuleMaxServers}, Servers: 3}
+
+	var buf bytes.Buffer
+	if err := outputCheckJSON(&buf, "/home/user/.claude.json", result); err != nil {
+		t.Fatalf("outputCheckJSON() unexpected error: %v", err)
+	}
+
+	var got checkOutput
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+	want := checkOutput{
+		Path:       "/home/user/.claude.json",
+		OK:         true,
+		Servers:    3,
+		Rules:      []string{check.RuleMaxServers},
+		Violations: []check.Violation{},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("outputCheckJSON() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlanDriftChanges(t *testing.T) {
+	policyPath := filepath.Join(t.TempDir(), policy.FileName)
+	if err := os.WriteFile(policyPath, []byte("protect: [github]\n"), 0o600); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+	set, err := policy.Load(policyPath, nil)
+	if err != nil {
+		t.Fatalf("failed to load policy: %v", err)
+	}
+
+	drifts := []manifest.Drift{
+		{Kind: manifest.KindMissing, Server: types.MCPServer{Name: "context7", Scope: types.ScopeGlobal}},
+		{Kind: manifest.KindChanged, Server: types.MCPServer{Name: "serena", Scope: types.ScopeGlobal}},
+		{Kind: manifest.KindExtra, Server: types.MCPServer{Name: "github", Scope: types.ScopeGlobal}},
+		{Kind: manifest.KindExtra, Server: types.MCPServer{Name: "puppeteer", Scope: types.ScopeGlobal}},
+	}
+
+	tests := []struct {
+		name       string
+		keepExtra  bool
+		wantUpsert []string
+		wantRemove []string
+	}{
+		{name: "removes unprotected extra servers", wantUpsert: []string{"context7", "serena"}, wantRemove: []string{"puppeteer"}},
+		{name: "keeps extra servers", keepExtra: true, wantUpsert: []string{"context7", "serena"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upsert, remove := planDriftChanges(io.Discard, drifts, tt.keepExtra, set)
+
+			names := func(servers []types.MCPServer) []string {
+				var result []string
+				for _, s := range servers {
+					result = append(result, s.Name)
+				}
+				return result
+			}
+			if diff := cmp.Diff(tt.wantUpsert, names(upsert)); diff != "" {
+				t.Errorf("upsert mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantRemove, names(remove)); diff != "" {
+				t.Errorf("remove mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDriftCommand_ApplyCodex(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	data, err := os.ReadFile("../../testdata/codex/config.toml")
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(configPath, data, 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	manifestPath := filepath.Join(dir, manifest.FileName)
+	if err := os.WriteFile(manifestPath, []byte(`servers:
+  - name: context7
+    command: npx
+    args: [-y, "@upstash/context7-mcp"]
+  - name: github
+    command: docker
+    args: [run, -i, --rm, ghcr.io/github/github-mcp-server]
+  - name: serena
+    url: https://serena.example.com/mcp
+`), 0o600); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+	rootClient, rootConfigPath, driftApply, driftYes = config.ClientCodex, configPath, true, true
+	defer func() { rootClient, rootConfigPath, driftApply, driftYes = "", "", false, false }()
+
+	// Only the servers that differ are changed, in the Codex schema
+	if err := runDrift(driftCmd, []string{manifestPath}); err != nil {
+		t.Fatalf("runDrift() error = %v", err)
+	}
+	cfg, err := config.Codex{}.Load(configPath)
+	if err != nil {
+		t.Fatalf("failed to load the applied config: %v", err)
+	}
+	var got []string
+	for _, s := range cfg.Servers() {
+		got = append(got, s.Client+":"+s.Name+":"+s.CommandString())
+	}
+	want := []string{
+		"codex:context7:npx -y @upstash/context7-mcp",
+		"codex:github:docker run -i --rm ghcr.io/github/github-mcp-server",
+		"codex:serena:[http] https://serena.example.com/mcp",
+	}
+	if diff := cmp.Diff(want, got, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("servers after drift --apply mismatch (-want +got):\n%s", diff)
+	}
+
+	// The config now matches the manifest
+	driftApply = false
+	if err := runDrift(driftCmd, []string{manifestPath}); err != nil {
+		t.Errorf("runDrift() after apply error = %v, want in sync", err)
+	}
+}
+
+func TestFindUndoTarget(t *testing.T) {
+	entries := []journal.Entry{{ID: 1}, {ID: 2}, {ID: 3, UndoOf: 2}}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    int
+		wantErr bool
+	}{
+		{name: "last not undone", args: nil, want: 1},
+		{name: "by number", args: []string{"3"}, want: 3},
+		{name: "with hash", args: []string{"#1"}, want: 1},
+		{name: "already undone", args: []string{"2"}, wantErr: true},
+		{name: "out of range", args: []string{"4"}, wantErr: true},
+		{name: "not a number", args: []string{"last"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findUndoTarget(entries, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("findUndoTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got == nil || got.ID != tt.want {
+				t.Errorf("findUndoTarget() = %v, want #%d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallHook_FailureEvent(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(config.ClaudeConfigDirEnv, filepath.Join(dir, "claude"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	installHookCommand = "mcp-tidy hook"
+	defer func() { installHookCommand = "" }()
+
+	if err := runInstallHook(installHookCmd, nil); err != nil {
+		t.Fatalf("runInstallHook() error = %v", err)
+	}
+	if err := runInstallHook(installHookCmd, nil); !errors.Is(err, errNothingChanged) {
+		t.Errorf("runInstallHook() again error = %v, want errNothingChanged", err)
+	}
+
+	// The hook is registered for failed calls too, and the change can be undone
+	assertHookInstalled(t, "mcp-tidy hook", "PostToolUse", "PostToolUseFailure")
+	entries, err := journal.Load(journal.DefaultPath())
+	if err != nil || len(entries) != 1 || entries[0].Config != config.UserSettingsPath() {
+		t.Errorf("journal entries = %+v, error = %v, want the install", entries, err)
+	}
+
+	// Claude Code runs the hook with either event
+	tests := []struct {
+		name  string
+		input string
+		want  usagelog.Record
+	}{
+		{
+			name: "successful call",
+			input: `{"session_id":"abc","cwd":"/work/app","hook_event_name":"PostToolUse",
+				"tool_name":"mcp__context7__query-docs","tool_input":{},"tool_use_id":"toolu_08","tool_response":{}}`,
+			want: usagelog.Record{ID: "toolu_08", Server: "context7", Tool: "query-docs", Project: "/work/app", Session: "abc"},
+		},
+		{
+			name: "failed call",
+			input: `{"session_id":"abc","cwd":"/work/app","hook_event_name":"PostToolUseFailure",
+				"tool_name":"mcp__github__create_issue","tool_input":{},"tool_use_id":"toolu_09","error":"MCP error -32603: timeout"}`,
+			want: usagelog.Record{ID: "toolu_09", Server: "github", Tool: "create_issue", Project: "/work/app", Session: "abc", Error: true},
+		},
+	}
+
+	defer hookCmd.SetIn(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hookCmd.SetIn(strings.NewReader(tt.input))
+			if err := runHook(hookCmd, nil); err != nil {
+				t.Fatalf("runHook() error = %v", err)
+			}
+			got := lastUsageRecord(t)
+			tt.want.Time = got.Time
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("recorded call mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// assertHookInstalled checks that the user settings run command for MCP
+// tools on each of the events.
+func assertHookInstalled(t *testing.T, command string, events ...string) {
+	t.Helper()
+	data, err := os.ReadFile(config.UserSettingsPath())
+	if err != nil {
+		t.Fatalf("failed to read settings: %v", err)
+	}
+	var settings struct {
+		Hooks map[string][]struct {
+			Matcher string `json:"matcher"`
+			Hooks   []struct {
+				Command string `json:"command"`
+			} `json:"hooks"`
+		} `json:"hooks"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("failed to parse settings: %v", err)
+	}
+	for _, event := range events {
+		groups := settings.Hooks[event]
+		if len(groups) != 1 || groups[0].Matcher != hookMatcher || len(groups[0].Hooks) != 1 || groups[0].Hooks[0].Command != command {
+			t.Errorf("%s hooks = %+v, want %s for %s", event, groups, command, hookMatcher)
+		}
+	}
+}
+
+// lastUsageRecord returns the last call recorded in the usage log.
+func lastUsageRecord(t *testing.T) usagelog.Record {
+	t.Helper()
+	data, err := os.ReadFile(usagelog.DefaultPath())
+	if err != nil {
+		t.Fatalf("failed to read usage log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var record usagelog.Record
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+		t.Fatalf("failed to parse usage log: %v", err)
+	}
+	return record
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "/usr/local/bin/mcp-tidy", want: `'/usr/local/bin/mcp-tidy'`},
+		{in: "/Users/me/My Tools/mcp-tidy", want: `'/Users/me/My Tools/mcp-tidy'`},
+		{in: `/opt/$HOME/"x"/mcp-tidy`, want: `'/opt/$HOME/"x"/mcp-tidy'`},
+		{in: "/home/o'brien/bin/mcp-tidy", want: `'/home/o'\''brien/bin/mcp-tidy'`},
+	}
+
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// allowedByManagedPolicy drops the servers the managed allow and deny lists
+// don't permit, telling the user about each one. The policy is enforced even
+// though Claude Code would refuse to start such servers anyway, so the
+// config doesn't fill up with entries that can never run.
+func allowedByManagedPolicy(w io.Writer, servers []types.MCPServer) ([]types.MCPServer, error) {
+	p, err := config.LoadManagedPolicy(config.DefaultManagedDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var allowed []types.MCPServer
+	for i := range servers {
+		if err := p.Check(&servers[i]); err != nil {
+			fmt.Fprintf(w, "Skipping %s: %v\n", servers[i].Name, err)
+			continue
+		}
+		allowed = append(allowed, servers[i])
+	}
+	return allowed, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mcpjsonProject  string
+	mcpjsonSettings string
+)
+
+var disableCmd = &cobra.Command{
+	Use:   "disable <server>...",
+	Short: "Disable .mcp.json servers of a project",
+	Long: `Disable servers that a project shares through its .mcp.json file, without
+editing the shared file: the servers are added to disabledMcpjsonServers in a
+Claude Code settings file.
+
+By default the personal .claude/settings.local.json of the project is
+changed, so only you are affected. Use --settings project for the shared
+.claude/settings.json, or --settings user for ~/.claude/settings.json.
+
+Exit codes: 0 when the settings were changed, 2 when the servers were
+already disabled there, 1 on errors.`,
+	Example: `  mcp-tidy disable puppeteer
+  mcp-tidy disable github --project ~/src/app --settings project`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return setMCPJSONStatus(args, false)
+	},
+}
+
+var enableCmd = &cobra.Command{
+	Use:   "enable <server>...",
+	Short: "Enable .mcp.json servers of a project",
+	Long: `Approve servers that a project shares through its .mcp.json file: the
+servers are added to enabledMcpjsonServers in a Claude Code settings file and
+removed from its disabledMcpjsonServers. Takes the same flags as disable.
+
+Exit codes: 0 when the settings were changed, 2 when the servers were
+already enabled there, 1 on errors.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return setMCPJSONStatus(args, true)
+	},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{disableCmd, enableCmd} {
+		cmd.Flags().StringVar(&mcpjsonProject, "project", "", "Project directory (default: current directory)")
+		cmd.Flags().StringVar(&mcpjsonSettings, "settings", config.SettingsLocal.String(), "Settings file to change (local, project, user)")
+	}
+}
+
+func setMCPJSONStatus(names []string, enable bool) error {
+	file, err := config.ParseSettingsFile(mcpjsonSettings)
+	if err != nil {
+		return err
+	}
+	loc, err := resolveLocation()
+	if err != nil {
+		return err
+	}
+	project, err := projectDir(mcpjsonProject)
+	if err != nil {
+		return err
+	}
+
+	servers, err := config.LoadMCPJSON(project, loc.SettingsPath)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(servers, func(s types.MCPServer) bool { return s.Name == name }) {
+			return fmt.Errorf("no server %q in %s", name, filepath.Join(project, config.MCPJSONFileName))
+		}
+	}
+
+	verb, want := "Disabled", types.StatusDisabled
+	if enable {
+		verb, want = "Enabled", types.StatusActive
+	}
+	settingsPath := config.SettingsPath(file, loc.SettingsPath, project)
+	result, err := config.SetMCPJSONServerStatus(settingsPath, names, enable)
+	if err != nil {
+		return err
+	}
+	if len(result.Changes) == 0 {
+		fmt.Printf("%s is already %s in %s.\n", strings.Join(names, ", "), strings.ToLower(verb), settingsPath)
+		return errNothingChanged
+	}
+	fmt.Printf("%s %s in %s\n", verb, strings.Join(names, ", "), settingsPath)
+	recordOperation(settingsPath, result, 0)
+
+	// Another settings file can still override the change
+	servers, err = config.LoadMCPJSON(project, loc.SettingsPath)
+	if err != nil {
+		return err
+	}
+	for i := range servers {
+		if slices.Contains(names, servers[i].Name) && servers[i].Status != want {
+			fmt.Printf("Warning: %s is still %s (%s)\n", servers[i].Name, servers[i].Status, servers[i].StatusReason)
+		}
+	}
+	return nil
+}
+
+// projectDir returns the absolute project directory, defaulting to the
+// current directory.
+func projectDir(dir string) (string, error) {
+	if dir == "" {
+		return os.Getwd()
+	}
+	return filepath.Abs(dir)
+}
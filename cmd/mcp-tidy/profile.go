@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/mcptidy"
+)
+
+// resolveLocation returns the source selected by the root flags and the
+// environment (see mcptidy.ResolveSource).
+func resolveLocation() (*mcptidy.Source, error) {
+	client, err := config.ParseClient(rootClient)
+	if err != nil {
+		return nil, err
+	}
+	if !client.HasTranscripts() {
+		if rootProfile != "" {
+			return nil, fmt.Errorf("--profile cannot be combined with --client %s", client.Name())
+		}
+		if len(rootTranscripts) > 0 {
+			return nil, fmt.Errorf("%s keeps no transcripts, so --transcripts cannot be used with it", client.Title())
+		}
+	}
+
+	return mcptidy.ResolveSource(context.Background(), mcptidy.SourceOptions{
+		Client:          rootClient,
+		Profile:         rootProfile,
+		ConfigPath:      rootConfigPath,
+		TranscriptPaths: rootTranscripts,
+	})
+}
+
+// resolveAllLocations returns the source of every configured profile, for
+// commands run with --all-profiles.
+func resolveAllLocations() ([]mcptidy.Source, error) {
+	if rootProfile != "" || rootConfigPath != "" || len(rootTranscripts) > 0 || rootClient != "" {
+		return nil, errors.New("--all-profiles cannot be combined with --profile, --config, --transcripts or --client")
+	}
+	return mcptidy.ProfileSources(context.Background())
+}
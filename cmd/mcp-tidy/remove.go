@@ -1,119 +1,187 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/nnnkkk7/mcp-tidy/config"
-	"github.com/nnnkkk7/mcp-tidy/transcript"
+	"github.com/nnnkkk7/mcp-tidy/mcptidy"
 	"github.com/nnnkkk7/mcp-tidy/types"
 	"github.com/nnnkkk7/mcp-tidy/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	removeUnused bool
-	removeDryRun bool
-	removeForce  bool
-	removePeriod string
+	removeUnused    bool
+	removeAllUnused bool
+	removeDryRun    bool
+	removeDiffOnly  bool
+	removeForce     bool
+	removeYes       bool
+	removePeriod    string
+	removeScope     string
+	removeProject   string
 )
 
 var removeCmd = &cobra.Command{
-	Use:   "remove",
+	Use:   "remove [selector...]",
 	Short: "Remove MCP servers",
-	Long: `Interactively select and remove MCP servers from ~/.claude.json.
-
-Creates a backup before making any changes. Use --dry-run to preview
-changes without actually removing servers.`,
+	Long: `Select and remove MCP servers from ~/.claude.json.
+
+Without selectors, servers are picked interactively. On a terminal, a
+full-screen selector is shown: arrow keys move, space toggles, '/' filters,
+'s' changes the sort order and enter opens the confirm screen. When stdin is
+not a terminal, a numbered line prompt is used instead.
+
+Selectors use the same syntax as the prompt: server names, globs
+(puppeteer*), 'unused', 'global', 'project:/path' and exclusions (!name).
+Numbers and ranges only work in the prompt, where the numbered list is shown.
+With --yes, nothing is prompted and no terminal is needed.
+
+Servers protected in .mcp-tidy.yaml are never removed, and 'unused' follows
+the policy's periods and minimum call counts. Servers your administrator
+deploys through managed-mcp.json can't be removed and are skipped.
+
+Creates a backup before making any changes, and shows a diff of the
+mcpServers entries that change. If Claude Code rewrites the config while
+mcp-tidy writes it, the removal is redone on the new content, and a warning
+is shown when Claude Code is running. Use --dry-run to preview changes without
+actually removing servers, or --diff-only to print just the patch.
+
+Exit codes: 0 when servers were removed (or would be, with --dry-run or --diff-only),
+2 when nothing was removed, 1 on errors.`,
+	Example: `  mcp-tidy remove puppeteer --scope global
+  mcp-tidy remove --project /path/to/project --all-unused --yes
+  mcp-tidy remove 'puppeteer*' '!puppeteer-extra' --dry-run`,
 	RunE: runRemove,
 }
 
 func init() {
 	removeCmd.Flags().BoolVar(&removeUnused, "unused", false, "Only show unused servers")
+	removeCmd.Flags().BoolVar(&removeAllUnused, "all-unused", false, "Select every unused server without prompting")
 	removeCmd.Flags().BoolVar(&removeDryRun, "dry-run", false, "Preview changes without removing")
+	removeCmd.Flags().BoolVar(&removeDiffOnly, "diff-only", false, "Print the config change as a unified diff without removing")
 	removeCmd.Flags().BoolVar(&removeForce, "force", false, "Remove without confirmation")
-	removeCmd.Flags().StringVar(&removePeriod, "period", "30d", "Period for determining 'unused' (7d, 30d, 90d)")
+	removeCmd.Flags().BoolVarP(&removeYes, "yes", "y", false, "Run non-interactively: never prompt (requires selectors or --all-unused)")
+	removeCmd.Flags().StringVar(&removePeriod, "period", "30d", "Period for determining 'unused' (7d, 30d, 90d, all, or e.g. 12w)")
+	removeCmd.Flags().StringVar(&removeScope, "scope", "", "Only consider servers in this scope (global, project)")
+	removeCmd.Flags().StringVar(&removeProject, "project", "", "Only consider servers of this project path")
 }
 
-func runRemove(_ *cobra.Command, _ []string) error {
-	configPath := config.DefaultConfigPath()
+func runRemove(cmd *cobra.Command, args []string) error {
+	if err := validateRemoveFlags(args); err != nil {
+		return err
+	}
 
-	// Load config and stats
-	servers, statsMap, period, err := loadServersWithStats(configPath)
+	loc, err := resolveLocation()
 	if err != nil {
 		return err
 	}
-	if len(servers) == 0 {
-		fmt.Println("No MCP servers configured.")
-		return nil
+	if (removeUnused || removeAllUnused) && !loc.Client.HasTranscripts() {
+		return fmt.Errorf("%s keeps no transcripts, so its unused servers can't be found; name the servers to remove instead", loc.Client.Title())
 	}
 
-	// Filter servers if --unused
-	displayServers := filterServersForRemoval(servers, statsMap, period)
-	if displayServers == nil {
-		return nil
+	// Load config and stats; managed servers count for usage but can't be removed
+	inv, err := loadServers(cmd.Context(), loc, mcptidy.LoadOptions{})
+	if err != nil {
+		return err
+	}
+	usage, err := mcptidy.CollectUsage(cmd.Context(), loc, inv.Servers, mcptidy.UsageOptions{Period: periodFlag(cmd, removePeriod)})
+	if err != nil {
+		return err
 	}
 
-	// Let user select and get servers to remove
-	toRemove := selectServersToRemove(displayServers, statsMap)
-	if len(toRemove) == 0 {
+	// Filter servers by --scope / --project and --unused, never offering
+	// servers protected by the policy, and resolve selectors
+	plan, err := mcptidy.PlanRemoval(cmd.Context(), inv, usage, mcptidy.RemovalOptions{
+		Scope:     removeScope,
+		Project:   removeProject,
+		Unused:    removeUnused || removeAllUnused,
+		Selectors: args,
+		All:       removeAllUnused,
+	})
+	if err != nil {
+		return err
+	}
+	if !reportRemovalPlan(plan) {
+		return errNothingChanged
+	}
+
+	// Let user select servers to remove unless selectors or --all-unused did
+	confirmed := false
+	if len(args) == 0 && !removeAllUnused {
+		plan.Remove, confirmed = selectServersToRemove(plan.Candidates, usage.StatsByName(), usage.Verdicts)
+	}
+	if len(plan.Remove) == 0 {
 		fmt.Println("No servers selected.")
-		return nil
+		return errNothingChanged
 	}
 
 	// Execute removal
-	return executeRemoval(configPath, toRemove)
+	return executeRemoval(cmd.Context(), plan, confirmed)
 }
 
-func loadServersWithStats(configPath string) ([]types.MCPServer, map[string]types.ServerStats, types.Period, error) {
-	cfg, err := config.Load(configPath)
-	if err != nil {
-		return nil, nil, 0, err
+// reportRemovalPlan tells the user which servers were skipped and why there
+// is nothing to remove, if so. It reports whether the plan has candidates.
+func reportRemovalPlan(plan *mcptidy.RemovalPlan) bool {
+	if len(plan.Managed) > 0 {
+		path := filepath.Join(config.DefaultManagedDir(), config.ManagedMCPFileName)
+		fmt.Printf("Skipping server(s) managed by your administrator in %s: %s\n", path, serverNames(plan.Managed))
 	}
-
-	servers := cfg.Servers()
-	period := types.ParsePeriod(removePeriod)
-
-	// Get stats for all servers
-	transcriptPath := transcript.DefaultTranscriptPath()
-	allStats, err := transcript.GetStats(transcriptPath, period)
-	if err != nil {
-		allStats = nil
+	if len(plan.Protected) > 0 {
+		fmt.Printf("Skipping protected server(s): %s\n", serverNames(plan.Protected))
 	}
 
-	// Convert stats to map
-	statsMap := make(map[string]types.ServerStats)
-	for _, s := range allStats {
-		statsMap[s.Name] = s
+	switch plan.Empty {
+	case mcptidy.NoServers:
+		fmt.Println("No MCP servers configured.")
+	case mcptidy.NoneInScope:
+		fmt.Println("No MCP servers configured in the given scope.")
+	case mcptidy.AllProtected:
+		fmt.Println("All MCP servers in the given scope are protected.")
+	case mcptidy.NoneUnused:
+		fmt.Println("No unused servers found.")
+	default:
+		return true
 	}
-
-	return servers, statsMap, period, nil
+	return false
 }
 
-func filterServersForRemoval(servers []types.MCPServer, statsMap map[string]types.ServerStats, period types.Period) []types.MCPServer {
-	if !removeUnused {
-		return servers
-	}
-
-	var unused []types.MCPServer
+// serverNames returns the names of the servers as a comma-separated list.
+func serverNames(servers []types.MCPServer) string {
+	names := make([]string, 0, len(servers))
 	for i := range servers {
-		stat, ok := statsMap[servers[i].Name]
-		if !ok || stat.IsUnused(period.Duration()) {
-			unused = append(unused, servers[i])
-		}
+		names = append(names, servers[i].Name)
 	}
+	return strings.Join(names, ", ")
+}
 
-	if len(unused) == 0 {
-		fmt.Println("No unused servers found.")
-		return nil
+// validateRemoveFlags checks flag combinations before anything is loaded.
+func validateRemoveFlags(args []string) error {
+	switch removeScope {
+	case "", types.ScopeGlobal.String(), types.ScopeProject.String():
+	default:
+		return fmt.Errorf("invalid --scope %q (expected global or project)", removeScope)
+	}
+	if removeProject != "" && removeScope == types.ScopeGlobal.String() {
+		return fmt.Errorf("--project cannot be combined with --scope global")
 	}
-	return unused
+	if removeYes && len(args) == 0 && !removeAllUnused {
+		return fmt.Errorf("--yes requires server selectors or --all-unused")
+	}
+	return nil
 }
 
-func selectServersToRemove(displayServers []types.MCPServer, statsMap map[string]types.ServerStats) []types.MCPServer {
-	selectedIdx := ui.SelectServersPrompt(displayServers, statsMap)
+// selectServersToRemove lets the user pick servers, using the full-screen
+// selector on a terminal. The returned bool reports whether the user already
+// confirmed the selection there.
+func selectServersToRemove(displayServers []types.MCPServer, statsMap map[string]types.ServerStats, verdicts map[string]types.UnusedVerdict) ([]types.MCPServer, bool) {
+	selectedIdx, confirmed := ui.SelectServers(displayServers, statsMap, verdicts)
 	if len(selectedIdx) == 0 {
-		return nil
+		return nil, false
 	}
 
 	var toRemove []types.MCPServer
@@ -122,27 +190,56 @@ func selectServersToRemove(displayServers []types.MCPServer, statsMap map[string
 			toRemove = append(toRemove, displayServers[idx])
 		}
 	}
-	return toRemove
+	return toRemove, confirmed
 }
 
-func executeRemoval(configPath string, toRemove []types.MCPServer) error {
+// executeRemoval removes the plan's servers from its source's config. When a
+// profile is selected, the profile and its file are named before anything
+// is written, so the wrong profile isn't changed by mistake.
+func executeRemoval(ctx context.Context, plan *mcptidy.RemovalPlan, confirmed bool) error {
+	loc, toRemove := plan.Source, plan.Remove
+	configPath := loc.ConfigPath
+	patch, err := configPatch(configPath, nil, toRemove)
+	if err != nil {
+		return err
+	}
+	if removeDiffOnly {
+		fmt.Print(patch)
+		return nil
+	}
+	if loc.Profile != "" {
+		fmt.Printf("Profile %s: %s\n", loc.Profile, loc.ConfigPath)
+	}
+	ui.RenderDiff(os.Stdout, patch)
+
 	if removeDryRun {
 		ui.RenderDryRunSummary(os.Stdout, toRemove)
 		return nil
 	}
+	if loc.Client.Name() == config.ClientClaudeCode {
+		ui.RenderClaudeWarning(os.Stdout, configPath, config.ClaudeProcesses(configPath))
+	}
 
-	if !removeForce {
+	if !removeForce && !removeYes && (!confirmed || loc.Profile != "") {
 		prompt := fmt.Sprintf("Remove %d server(s)?", len(toRemove))
+		if loc.Profile != "" {
+			prompt = fmt.Sprintf("Remove %d server(s) from %s?", len(toRemove), loc.Label())
+		}
 		if !ui.ConfirmPrompt(prompt, false) {
 			fmt.Println("Canceled.")
-			return nil
+			return errNothingChanged
 		}
 	}
 
-	if err := config.RemoveServers(configPath, toRemove); err != nil {
+	result, err := mcptidy.ApplyRemoval(ctx, plan)
+	if err != nil {
 		return err
 	}
+	recordOperation(configPath, result, 0)
 
 	ui.RenderRemovalSummary(os.Stdout, toRemove)
+	if loc.Client.Name() != config.ClientClaudeCode {
+		fmt.Printf("Restart %s for the change to take effect.\n", loc.Client.Title())
+	}
 	return nil
 }
@@ -2,20 +2,26 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
-	"sort"
+	"slices"
+	"time"
 
 	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/mcptidy"
 	"github.com/nnnkkk7/mcp-tidy/transcript"
 	"github.com/nnnkkk7/mcp-tidy/types"
 	"github.com/nnnkkk7/mcp-tidy/ui"
+	"github.com/nnnkkk7/mcp-tidy/usagelog"
 	"github.com/spf13/cobra"
 )
 
 var (
-	statsPeriod string
-	statsJSON   bool
-	statsSort   string
+	statsPeriod      string
+	statsJSON        bool
+	statsSort        string
+	statsAllProfiles bool
+	statsSource      string
 )
 
 var statsCmd = &cobra.Command{
@@ -24,91 +30,168 @@ var statsCmd = &cobra.Command{
 	Long: `Display usage statistics for MCP servers based on Claude Code transcript logs.
 
 Shows call counts, last used time, and a visual usage bar for each server.
-Servers that haven't been used in the specified period are marked as unused.`,
+Servers are sorted into categories: configured and used, configured but unused
+in the specified period, and used but not configured (removed, or coming from
+another source such as plugins or .mcp.json). The other clients (Claude
+Desktop, Cursor, VS Code, Codex and Gemini CLI) keep no transcripts mcp-tidy
+can read, so the usage of their servers is shown as unavailable.
+
+What counts as unused can be configured in .mcp-tidy.yaml files (in the user
+config dir and in each project): protected and allowed servers, per-scope and
+per-server periods and minimum call counts. Each server shows why it was or
+wasn't flagged.
+
+With --all-profiles, every profile in .mcp-tidy.yaml is read, and its servers
+are matched to the calls in its own transcripts.
+
+Calls recorded by 'mcp-tidy hook' (see install-hook) are read along with the
+transcripts, and a call found in both is counted once. Use --source to read
+only one of them.`,
 	RunE: runStats,
 }
 
 func init() {
-	statsCmd.Flags().StringVar(&statsPeriod, "period", "30d", "Time period (7d, 30d, 90d, all)")
+	statsCmd.Flags().StringVar(&statsPeriod, "period", "30d", "Time period (7d, 30d, 90d, all, or e.g. 12w)")
 	statsCmd.Flags().BoolVar(&statsJSON, "json", false, "Output in JSON format")
 	statsCmd.Flags().StringVar(&statsSort, "sort", "calls", "Sort order (calls, name, last-used)")
+	statsCmd.Flags().BoolVar(&statsAllProfiles, "all-profiles", false, "Show the usage of every profile")
+	statsCmd.Flags().StringVar(&statsSource, "source", "all", "Where to read usage from (all, transcripts, hook)")
 }
 
-func runStats(_ *cobra.Command, _ []string) error {
-	transcriptPath := transcript.DefaultTranscriptPath()
-	configPath := config.DefaultConfigPath()
-	period := types.ParsePeriod(statsPeriod)
+func runStats(cmd *cobra.Command, _ []string) error {
+	if !slices.Contains([]string{"all", "transcripts", "hook"}, statsSource) {
+		return fmt.Errorf("invalid source %q (expected all, transcripts or hook)", statsSource)
+	}
+	if statsAllProfiles {
+		return runStatsAllProfiles(cmd)
+	}
 
-	// Get usage stats from transcript logs
-	stats, err := transcript.GetStats(transcriptPath, period)
+	loc, err := resolveLocation()
 	if err != nil {
 		return err
 	}
 
-	// Load configured servers
-	cfg, err := config.Load(configPath)
+	// Get usage stats from transcript logs, matched to configured server names,
+	// and decide which servers are unused according to the policy files
+	inv, err := configuredServers(cmd.Context(), loc, includeOtherClients(loc))
+	if err != nil {
+		return err
+	}
+	report, err := collectReport(cmd, loc, inv.Servers)
 	if err != nil {
 		return err
 	}
-
-	// Merge: add configured servers that have no stats (0 calls)
-	stats = mergeConfiguredServers(stats, cfg.Servers())
-
-	// Sort stats
-	sortStats(stats, statsSort)
 
 	if statsJSON {
-		return outputStatsJSON(stats)
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(buildStatsOutput(report))
 	}
 
-	ui.RenderStatsTable(os.Stdout, stats, period.Duration(), cfg.Servers())
+	ui.RenderStatsTableWithVerdicts(os.Stdout, report.ServerStats(), report.Period, inv.Servers, report.Verdicts())
 	return nil
 }
 
-// mergeConfiguredServers adds configured servers that don't appear in stats
-func mergeConfiguredServers(stats []types.ServerStats, servers []types.MCPServer) []types.ServerStats {
-	// Create a map of existing stats by name
-	statsMap := make(map[string]bool)
-	for _, s := range stats {
-		statsMap[s.Name] = true
+// collectReport collects the usage of the servers and joins the two, sorted
+// by --sort. The configured servers without calls are added with 0 calls,
+// except those whose usage can't be measured.
+func collectReport(cmd *cobra.Command, loc *mcptidy.Source, servers []types.MCPServer) (*mcptidy.Report, error) {
+	opts := mcptidy.UsageOptions{Period: periodFlag(cmd, statsPeriod), Sources: usageSources(loc, statsSource)}
+	usage, err := mcptidy.CollectUsage(cmd.Context(), loc, servers, opts)
+	if err != nil {
+		return nil, err
 	}
+	report := mcptidy.Join(servers, usage)
+	report.Sort(statsSort)
+	return report, nil
+}
 
-	// Add configured servers that don't have stats
-	for i := range servers {
-		if !statsMap[servers[i].Name] {
-			stats = append(stats, types.ServerStats{
-				Name:  servers[i].Name,
-				Calls: 0,
-				// LastUsed is zero value (never used)
-			})
-			statsMap[servers[i].Name] = true // prevent duplicates
-		}
+// usageSources returns the usage sources of loc selected by --source: the
+// transcripts, the usage log of 'mcp-tidy hook', or all of them.
+func usageSources(loc *mcptidy.Source, source string) []mcptidy.UsageSource {
+	sources := mcptidy.DefaultSources(loc)
+	switch source {
+	case "transcripts":
+		return slices.DeleteFunc(sources, func(s mcptidy.UsageSource) bool {
+			_, ok := s.(usagelog.Log)
+			return ok
+		})
+	case "hook":
+		return slices.DeleteFunc(sources, func(s mcptidy.UsageSource) bool {
+			_, ok := s.(transcript.Transcripts)
+			return ok
+		})
+	default:
+		return sources
 	}
+}
 
-	return stats
+// periodFlag returns the --period flag if it was set, so the policy's
+// default period applies otherwise.
+func periodFlag(cmd *cobra.Command, period string) string {
+	if !cmd.Flags().Changed("period") {
+		return ""
+	}
+	return period
 }
 
-func sortStats(stats []types.ServerStats, sortBy string) {
-	switch sortBy {
-	case "name":
-		sort.Slice(stats, func(i, j int) bool {
-			return stats[i].Name < stats[j].Name
-		})
-	case "last-used":
-		sort.Slice(stats, func(i, j int) bool {
-			return stats[i].LastUsed.After(stats[j].LastUsed)
+// profileStatsOutput is the stats of one profile in --all-profiles JSON output.
+type profileStatsOutput struct {
+	Profile string `json:"profile"`
+	Config  string `json:"config"`
+	statsOutput
+}
+
+func runStatsAllProfiles(cmd *cobra.Command) error {
+	locations, err := resolveAllLocations()
+	if err != nil {
+		return err
+	}
+
+	var period time.Duration
+	profiles := make([]ui.ProfileServers, 0, len(locations))
+	outputs := make([]profileStatsOutput, 0, len(locations))
+	for i := range locations {
+		loc := &locations[i]
+		inv, err := configuredServers(cmd.Context(), loc, false)
+		if err != nil {
+			return err
+		}
+		report, err := collectReport(cmd, loc, inv.Servers)
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", loc.Profile, err)
+		}
+		period = report.Period
+
+		profiles = append(profiles, ui.ProfileServers{
+			Profile:  loc.Profile,
+			Servers:  inv.Servers,
+			Stats:    report.StatsByName(),
+			Verdicts: report.Verdicts(),
 		})
-	default: // "calls"
-		sort.Slice(stats, func(i, j int) bool {
-			return stats[i].Calls > stats[j].Calls
+		outputs = append(outputs, profileStatsOutput{
+			Profile:     loc.Profile,
+			Config:      loc.ConfigPath,
+			statsOutput: buildStatsOutput(report),
 		})
 	}
+
+	if statsJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(outputs)
+	}
+
+	ui.RenderProfileStatsTable(os.Stdout, profiles, period)
+	return nil
 }
 
 type statsOutput struct {
-	Servers    []serverStatsOutput `json:"servers"`
-	TotalCalls int                 `json:"totalCalls"`
-	Period     string              `json:"period"`
+	Servers    []serverStatsOutput   `json:"servers"`
+	Verdicts   []serverVerdictOutput `json:"verdicts"`
+	Categories mcptidy.Categories    `json:"categories"`
+	TotalCalls int                   `json:"totalCalls"`
+	Period     string                `json:"period"`
 }
 
 type serverStatsOutput struct {
@@ -116,17 +199,55 @@ type serverStatsOutput struct {
 	Calls    int    `json:"calls"`
 	LastUsed string `json:"lastUsed"`
 	Unused   bool   `json:"unused"`
+	Category string `json:"category"`
+}
+
+// serverVerdictOutput explains why a configured server was or wasn't flagged as unused.
+type serverVerdictOutput struct {
+	Name        string `json:"name"`
+	Client      string `json:"client"`
+	Scope       string `json:"scope"`
+	Project     string `json:"project,omitempty"`
+	Type        string `json:"type"`
+	Status      string `json:"status"`
+	Flagged     bool   `json:"flagged"`
+	Protected   bool   `json:"protected"`
+	Unavailable bool   `json:"unavailable"`
+	Reason      string `json:"reason"`
 }
 
-func outputStatsJSON(stats []types.ServerStats) error {
+// buildStatsOutput converts a report into the JSON output structure.
+func buildStatsOutput(report *mcptidy.Report) statsOutput {
 	output := statsOutput{
-		Period:  statsPeriod,
-		Servers: make([]serverStatsOutput, len(stats)),
+		Period:     report.PeriodLabel,
+		Servers:    make([]serverStatsOutput, len(report.Stats)),
+		Verdicts:   make([]serverVerdictOutput, 0, len(report.Servers)),
+		Categories: report.Categories,
+		TotalCalls: report.TotalCalls,
+	}
+
+	for i := range report.Servers {
+		server, verdict := &report.Servers[i].Server, report.Servers[i].Verdict
+		client := server.Client
+		if client == "" {
+			client = config.ClientClaudeCode
+		}
+		output.Verdicts = append(output.Verdicts, serverVerdictOutput{
+			Name:        server.Name,
+			Client:      client,
+			Scope:       server.Scope.String(),
+			Project:     server.ProjectPath,
+			Type:        server.TypeName(),
+			Status:      server.Status.String(),
+			Flagged:     verdict.Flagged,
+			Protected:   verdict.Protected,
+			Unavailable: verdict.Unavailable,
+			Reason:      verdict.Reason,
+		})
 	}
 
-	period := types.ParsePeriod(statsPeriod)
-	for i, s := range stats {
-		output.TotalCalls += s.Calls
+	for i := range report.Stats {
+		s := &report.Stats[i]
 		lastUsed := "never"
 		if !s.LastUsed.IsZero() {
 			lastUsed = s.LastUsed.Format("2006-01-02T15:04:05Z07:00")
@@ -135,11 +256,10 @@ func outputStatsJSON(stats []types.ServerStats) error {
 			Name:     s.Name,
 			Calls:    s.Calls,
 			LastUsed: lastUsed,
-			Unused:   s.IsUnused(period.Duration()),
+			Unused:   s.Unused,
+			Category: s.Category.String(),
 		}
 	}
 
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(output)
+	return output
 }
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/types"
+	"github.com/nnnkkk7/mcp-tidy/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncFrom   string
+	syncTo     []string
+	syncAll    bool
+	syncDryRun bool
+	syncYes    bool
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [server...]",
+	Short: "Copy MCP server definitions from one client to others",
+	Long: `Copy global MCP server definitions from one client's config to the global
+config of one or more other clients, e.g. from ~/.claude.json to Cursor's
+~/.cursor/mcp.json.
+
+Each server is translated to the target's schema: the transport is written
+as "type" where the client needs it and left implicit for Cursor, Claude
+Code's ${NAME} references become ${env:NAME} in Cursor and VS Code, and VS
+Code's ${input:id} prompts become environment variables. What can't be
+carried over exactly is noted; servers a target can't run (such as remote
+servers for Claude Desktop) are skipped.
+
+A server the target already defines is updated when it differs, keeping the
+target's env and header values when the source has none. Before writing, a
+diff is shown for each server (env and header values masked). Every target
+file is backed up and written atomically, and each write can be undone with
+'mcp-tidy undo'.
+
+Exit codes: 0 when servers were synced (or would be, with --dry-run), 2 when
+every target is already in sync, 1 on errors.`,
+	Example: `  mcp-tidy sync context7 github --to cursor
+  mcp-tidy sync --all --to cursor --to vscode --dry-run
+  mcp-tidy sync fetch --from vscode --to claude-code`,
+	RunE: runSync,
+}
+
+func init() {
+	syncCmd.Flags().StringVar(&syncFrom, "from", config.ClientClaudeCode, "Client to copy the servers from")
+	syncCmd.Flags().StringSliceVar(&syncTo, "to", nil, "Client to copy the servers to; repeatable")
+	syncCmd.Flags().BoolVar(&syncAll, "all", false, "Sync every global server of the source client")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show what would change without writing")
+	syncCmd.Flags().BoolVarP(&syncYes, "yes", "y", false, "Sync without confirmation")
+}
+
+// syncTarget is a client to sync into, with the servers written to it.
+type syncTarget struct {
+	client     config.Client
+	configPath string
+	upserts    []types.MCPServer
+}
+
+func runSync(_ *cobra.Command, args []string) error {
+	if err := validateSyncFlags(args); err != nil {
+		return err
+	}
+
+	from, err := config.ParseClient(syncFrom)
+	if err != nil {
+		return err
+	}
+	sourcePath, err := syncConfigPath(from)
+	if err != nil {
+		return err
+	}
+	source, err := loadConfig(from, sourcePath)
+	if err != nil {
+		return err
+	}
+	servers, err := selectSyncServers(source.GlobalServers(), args, from)
+	if err != nil {
+		return err
+	}
+
+	// Plan every target and show its per-server diffs before writing anything
+	targets, err := planSyncTargets(from, servers)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Println("\nNothing to sync.")
+		return errNothingChanged
+	}
+	if syncDryRun {
+		fmt.Println("\n[DRY RUN] Run without --dry-run to sync these servers.")
+		return nil
+	}
+	if !syncYes && !confirmSync(targets) {
+		fmt.Println("Canceled.")
+		return errNothingChanged
+	}
+	return applySync(targets)
+}
+
+// validateSyncFlags checks that the arguments and flags of sync fit together.
+func validateSyncFlags(args []string) error {
+	switch {
+	case len(syncTo) == 0:
+		return errors.New("name the client(s) to sync to with --to")
+	case len(args) == 0 && !syncAll:
+		return errors.New("name the servers to sync, or use --all")
+	case len(args) > 0 && syncAll:
+		return errors.New("--all cannot be combined with server names")
+	case rootClient != "" || rootConfigPath != "":
+		return errors.New("sync works on the clients' own config files; use --from and --to instead of --client and --config")
+	}
+	return nil
+}
+
+// planSyncTargets plans syncing the servers into each --to client, leaving
+// out the clients that need no change.
+func planSyncTargets(from config.Client, servers []types.MCPServer) ([]syncTarget, error) {
+	var targets []syncTarget
+	for _, name := range syncTo {
+		target, err := planSyncTarget(name, from, servers)
+		if err != nil {
+			return nil, err
+		}
+		if len(target.upserts) > 0 {
+			targets = append(targets, *target)
+		}
+	}
+	return targets, nil
+}
+
+// confirmSync asks whether to write the planned servers, warning about
+// running Claude Code processes first.
+func confirmSync(targets []syncTarget) bool {
+	count := 0
+	titles := make([]string, 0, len(targets))
+	for i := range targets {
+		count += len(targets[i].upserts)
+		titles = append(titles, targets[i].client.Title())
+		if targets[i].client.Name() == config.ClientClaudeCode {
+			ui.RenderClaudeWarning(os.Stdout, targets[i].configPath, config.ClaudeProcesses(targets[i].configPath))
+		}
+	}
+	prompt := fmt.Sprintf("\nWrite %d server(s) to %s?", count, strings.Join(titles, ", "))
+	return ui.ConfirmPrompt(prompt, false)
+}
+
+// applySync writes the planned servers into each target.
+func applySync(targets []syncTarget) error {
+	for i := range targets {
+		t := &targets[i]
+		result, err := config.ApplyChanges(t.configPath, t.upserts, nil)
+		if err != nil {
+			return fmt.Errorf("failed to sync to %s: %w", t.client.Title(), err)
+		}
+		recordOperation(t.configPath, result, 0)
+		ui.RenderApplySummary(os.Stdout, t.upserts, nil)
+		if t.client.Name() != config.ClientClaudeCode {
+			fmt.Printf("Restart %s for the change to take effect.\n", t.client.Title())
+		}
+	}
+	return nil
+}
+
+// syncConfigPath returns the config file of a client: for Claude Code the
+// one of the selected profile, for other clients their default file.
+func syncConfigPath(client config.Client) (string, error) {
+	if client.Name() != config.ClientClaudeCode {
+		return client.DefaultConfigPath(), nil
+	}
+	loc, err := resolveLocation()
+	if err != nil {
+		return "", err
+	}
+	return loc.ConfigPath, nil
+}
+
+// selectSyncServers returns the named servers, or every server ordered by
+// name with --all.
+func selectSyncServers(servers []types.MCPServer, names []string, from config.Client) ([]types.MCPServer, error) {
+	if syncAll {
+		if len(servers) == 0 {
+			return nil, fmt.Errorf("%s has no global servers", from.Title())
+		}
+		sorted := append([]types.MCPServer{}, servers...)
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].Name < sorted[j].Name
+		})
+		return sorted, nil
+	}
+
+	byName := make(map[string]types.MCPServer, len(servers))
+	for i := range servers {
+		byName[servers[i].Name] = servers[i]
+	}
+	selected := make([]types.MCPServer, 0, len(names))
+	for _, name := range names {
+		server, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%s has no global server named %q", from.Title(), name)
+		}
+		selected = append(selected, server)
+	}
+	return selected, nil
+}
+
+// planSyncTarget plans syncing the servers into a client, printing the plan
+// and a diff for each server that is written. Servers the managed policy
+// doesn't allow are not written to Claude Code.
+func planSyncTarget(name string, from config.Client, servers []types.MCPServer) (*syncTarget, error) {
+	to, err := config.ParseClient(name)
+	if err != nil {
+		return nil, err
+	}
+	if to.Name() == from.Name() {
+		return nil, fmt.Errorf("cannot sync %s to itself", to.Title())
+	}
+	configPath, err := syncConfigPath(to)
+	if err != nil {
+		return nil, err
+	}
+	if configPath == "" {
+		return nil, fmt.Errorf("can't locate the config file of %s", to.Title())
+	}
+	// The file may be missing, but not the client's directory
+	if _, err := os.Stat(filepath.Dir(configPath)); err != nil {
+		return nil, fmt.Errorf("%s doesn't seem to be installed: %w", to.Title(), err)
+	}
+	cfg, err := loadConfig(to, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := config.PlanSync(servers, to, cfg.GlobalServers())
+	if to.Name() == config.ClientClaudeCode {
+		policy, err := config.LoadManagedPolicy(config.DefaultManagedDir())
+		if err != nil {
+			return nil, err
+		}
+		for i := range plan {
+			if plan[i].Action.Writes() {
+				if err := policy.Check(&plan[i].Server); err != nil {
+					plan[i] = config.SyncedServer{Server: types.MCPServer{Name: plan[i].Server.Name}, Action: config.SyncUnsupported, Reason: err.Error()}
+				}
+			}
+		}
+	}
+	ui.RenderSyncPlan(os.Stdout, to.Title(), configPath, plan)
+
+	target := &syncTarget{client: to, configPath: configPath, upserts: config.SyncUpserts(plan)}
+	for i := range target.upserts {
+		patch, err := configPatch(configPath, target.upserts[i:i+1], nil)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Println()
+		ui.RenderDiff(os.Stdout, patch)
+	}
+	return target, nil
+}
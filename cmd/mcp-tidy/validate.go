@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/ui"
+	"github.com/spf13/cobra"
+)
+
+var validateJSON bool
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check MCP server entries for mistakes",
+	Long: `Check the MCP server entries of ~/.claude.json (or the given file) and
+report problems with their JSON path. With --client, the entries are checked
+as that client reads them, e.g. --client codex for ~/.codex/config.toml:
+
+  - unknown types (only stdio, http and sse are supported)
+  - a missing or empty command for stdio servers
+  - a missing or invalid url for http and sse servers
+  - args, env and headers values that aren't strings
+  - unknown keys (as warnings)
+
+Exit codes: 0 when there are no errors (warnings are allowed), 3 when errors
+were found, 1 when the file can't be read or isn't valid JSON.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runValidate,
+}
+
+func init() {
+	validateCmd.Flags().BoolVar(&validateJSON, "json", false, "Output in JSON format")
+}
+
+func runValidate(_ *cobra.Command, args []string) error {
+	var path string
+	var client config.Client
+	if len(args) > 0 {
+		path = args[0]
+		c, err := config.ParseClient(rootClient)
+		if err != nil {
+			return err
+		}
+		client = c
+	} else {
+		loc, err := resolveLocation()
+		if err != nil {
+			return err
+		}
+		path, client = loc.ConfigPath, loc.Client
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	issues, err := config.ValidateClient(client, path, data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if validateJSON {
+		if err := outputValidateJSON(os.Stdout, path, issues); err != nil {
+			return err
+		}
+	} else {
+		ui.RenderValidation(os.Stdout, path, issues)
+	}
+
+	if len(config.Errors(issues)) > 0 {
+		return &exitError{code: exitCodeFindings}
+	}
+	return nil
+}
+
+// loadConfig loads a client's config and reports errors in its server
+// entries on stderr, so they don't go unnoticed by commands that skip over them.
+func loadConfig(client config.Client, configPath string) (*config.Config, error) {
+	cfg, err := client.Load(configPath)
+	if err != nil {
+		return nil, withValidateHint(err)
+	}
+	ui.RenderLoadIssues(os.Stderr, configPath, cfg.Issues())
+	return cfg, nil
+}
+
+// withValidateHint points to the validate command when a config's server
+// entries are invalid.
+func withValidateHint(err error) error {
+	var validationErr *config.ValidationError
+	if errors.As(err, &validationErr) {
+		return fmt.Errorf("%w\nRun 'mcp-tidy validate' for details", err)
+	}
+	return err
+}
+
+type validateOutput struct {
+	Path   string                `json:"path"`
+	Valid  bool                  `json:"valid"`
+	Issues []validateIssueOutput `json:"issues"`
+}
+
+type validateIssueOutput struct {
+	Path     string `json:"path"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func outputValidateJSON(w io.Writer, path string, issues []config.Issue) error {
+	output := validateOutput{
+		Path:   path,
+		Valid:  len(config.Errors(issues)) == 0,
+		Issues: make([]validateIssueOutput, 0, len(issues)),
+	}
+	for i := range issues {
+		output.Issues = append(output.Issues, validateIssueOutput{
+			Path:     issues[i].Path,
+			Severity: issues[i].Severity.String(),
+			Message:  issues[i].Message,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// WriteResult describes a completed write of the config.
+type WriteResult struct {
+	// Backup is the path of the backup taken before the write, if any.
+	Backup string
+	// Changes lists the server entries the write added, changed or removed.
+	Changes []ServerChange
+}
+
+// SettingScope is the scope of a change to a settings file: Name is the
+// top-level setting that changed, such as disabledMcpjsonServers or hooks.
+const SettingScope = "setting"
+
+// ServerChange records how a single server entry, or a setting of a
+// settings file, changed.
+type ServerChange struct {
+	Name    string `json:"name"`
+	Scope   string `json:"scope"`
+	Project string `json:"project,omitempty"`
+	// Before is the entry's JSON before the change; empty when it was added.
+	Before json.RawMessage `json:"before,omitempty"`
+	// After is the entry's JSON after the change; empty when it was removed.
+	After json.RawMessage `json:"after,omitempty"`
+}
+
+// Server returns the server the change applies to. Only the name, scope and
+// project are set.
+func (c *ServerChange) Server() types.MCPServer {
+	server := types.MCPServer{Name: c.Name, Scope: types.ScopeGlobal}
+	if c.Scope == types.ScopeProject.String() {
+		server.Scope = types.ScopeProject
+		server.ProjectPath = c.Project
+	}
+	return server
+}
+
+// Kind describes the change as "added", "removed" or "changed".
+func (c *ServerChange) Kind() string {
+	switch {
+	case len(c.Before) == 0:
+		return "added"
+	case len(c.After) == 0:
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+// serverLocation identifies a server entry in the config, or a setting of a
+// settings file.
+type serverLocation struct {
+	scope   string
+	project string
+	name    string
+}
+
+// snapshotServers returns the JSON of every server entry in a parsed config.
+func snapshotServers(raw map[string]interface{}) (map[serverLocation]json.RawMessage, error) {
+	snapshot := make(map[serverLocation]json.RawMessage)
+	add := func(scope, project string, mcpServers interface{}) error {
+		servers, ok := mcpServers.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for name, entry := range servers {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return fmt.Errorf("failed to marshal server %s: %w", name, err)
+			}
+			snapshot[serverLocation{scope: scope, project: project, name: name}] = data
+		}
+		return nil
+	}
+
+	if err := add(types.ScopeGlobal.String(), "", raw[serversKey(raw)]); err != nil {
+		return nil, err
+	}
+	if projects, ok := raw["projects"].(map[string]interface{}); ok {
+		for path, project := range projects {
+			if p, ok := project.(map[string]interface{}); ok {
+				if err := add(types.ScopeProject.String(), path, p["mcpServers"]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// snapshotSettings returns the JSON of every top-level setting of a parsed
+// settings file.
+func snapshotSettings(raw map[string]interface{}) (map[serverLocation]json.RawMessage, error) {
+	snapshot := make(map[serverLocation]json.RawMessage, len(raw))
+	for key, value := range raw {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal setting %s: %w", key, err)
+		}
+		snapshot[serverLocation{scope: SettingScope, name: key}] = data
+	}
+	return snapshot, nil
+}
+
+// serverChanges compares two snapshots, ordered by scope, project and name.
+func serverChanges(before, after map[serverLocation]json.RawMessage) []ServerChange {
+	var changes []ServerChange
+	record := func(loc serverLocation) {
+		from, to := before[loc], after[loc]
+		if bytes.Equal(from, to) {
+			return
+		}
+		changes = append(changes, ServerChange{
+			Name:    loc.name,
+			Scope:   loc.scope,
+			Project: loc.project,
+			Before:  from,
+			After:   to,
+		})
+	}
+	for loc := range before {
+		record(loc)
+	}
+	for loc := range after {
+		if _, ok := before[loc]; !ok {
+			record(loc)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := &changes[i], &changes[j]
+		if a.Scope != b.Scope {
+			return a.Scope < b.Scope
+		}
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		return a.Name < b.Name
+	})
+	return changes
+}
+
+// Revert undoes changes by restoring each entry's Before state. An entry that
+// no longer matches its After state was changed since, by Claude Code or by
+// hand, and is left alone so that change isn't lost; such entries are
+// returned as conflicts. A backup is created first. The changes are those
+// of one file: a config, or a settings file if they are settings.
+func Revert(configPath string, changes []ServerChange) (*WriteResult, []ServerChange, error) {
+	doc := configDocument
+	if len(changes) > 0 && changes[0].Scope == SettingScope {
+		doc = settingsDocument
+	}
+
+	var conflicts []ServerChange
+	result, err := updateFile(configPath, doc, true, false, func(raw map[string]interface{}) {
+		conflicts = nil // the edit is redone if the file changes while writing
+		for i := range changes {
+			change := &changes[i]
+			if change.Scope == SettingScope {
+				if !revertSetting(raw, change) {
+					conflicts = append(conflicts, *change)
+				}
+				continue
+			}
+			server := change.Server()
+			mcpServers := serversObject(raw, &server, len(change.Before) > 0)
+
+			var current interface{}
+			if mcpServers != nil {
+				current = mcpServers[change.Name]
+			}
+			if !sameJSON(current, change.After) {
+				conflicts = append(conflicts, *change)
+				continue
+			}
+
+			if len(change.Before) == 0 {
+				if mcpServers != nil {
+					delete(mcpServers, change.Name)
+				}
+				continue
+			}
+			// Numbers are kept as written, so a TOML integer stays one
+			var entry interface{}
+			decoder := json.NewDecoder(bytes.NewReader(change.Before))
+			decoder.UseNumber()
+			if err := decoder.Decode(&entry); err != nil {
+				conflicts = append(conflicts, *change)
+				continue
+			}
+			mcpServers[change.Name] = entry
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, conflicts, nil
+}
+
+// revertSetting restores the Before state of a setting, unless the setting
+// no longer has its After state. It reports whether it was restored.
+func revertSetting(raw map[string]interface{}, change *ServerChange) bool {
+	if !sameJSON(raw[change.Name], change.After) {
+		return false
+	}
+	if len(change.Before) == 0 {
+		delete(raw, change.Name)
+		return true
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(change.Before))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return false
+	}
+	raw[change.Name] = value
+	return true
+}
+
+// sameJSON reports whether a parsed value equals the given JSON; a nil value
+// equals empty JSON. Values are compared as JSON, so an int64 parsed from
+// TOML equals the same number in JSON.
+func sameJSON(value interface{}, data json.RawMessage) bool {
+	if len(data) == 0 {
+		return value == nil
+	}
+	var want interface{}
+	if err := json.Unmarshal(data, &want); err != nil {
+		return false
+	}
+	if reflect.DeepEqual(value, want) {
+		return true
+	}
+	got, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	normalized, err := json.Marshal(want)
+	return err == nil && bytes.Equal(got, normalized)
+}
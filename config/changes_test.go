@@ -0,0 +1,132 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestApplyChanges_Result(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "claude.json")
+	initial := `{
+		"mcpServers": {
+			"github": {"type": "stdio", "command": "npx", "args": ["github-mcp@1"]},
+			"puppeteer": {"type": "stdio", "command": "npx"}
+		}
+	}`
+	if err := os.WriteFile(configPath, []byte(initial), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	result, err := ApplyChanges(configPath,
+		[]types.MCPServer{
+			{Name: "github", TypeStr: "stdio", Command: "npx", Args: []string{"github-mcp@2"}, Scope: types.ScopeGlobal},
+			{Name: "serena", Command: "uvx", Scope: types.ScopeProject, ProjectPath: "/work/app"},
+		},
+		[]types.MCPServer{{Name: "puppeteer", Scope: types.ScopeGlobal}},
+	)
+	if err != nil {
+		t.Fatalf("ApplyChanges() unexpected error: %v", err)
+	}
+
+	want := []ServerChange{
+		{
+			Name: "github", Scope: "global",
+			Before: json.RawMessage(`{"args":["github-mcp@1"],"command":"npx","type":"stdio"}`),
+			After:  json.RawMessage(`{"args":["github-mcp@2"],"command":"npx","type":"stdio"}`),
+		},
+		{Name: "puppeteer", Scope: "global", Before: json.RawMessage(`{"command":"npx","type":"stdio"}`)},
+		{Name: "serena", Scope: "project", Project: "/work/app", After: json.RawMessage(`{"command":"uvx"}`)},
+	}
+	if diff := cmp.Diff(want, result.Changes); diff != "" {
+		t.Errorf("ApplyChanges() changes mismatch (-want +got):\n%s", diff)
+	}
+	if result.Backup == "" {
+		t.Error("ApplyChanges() should report the backup path")
+	}
+}
+
+func TestApplyChanges_NoChanges(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "claude.json")
+	if err := os.WriteFile(configPath, []byte(`{"mcpServers": {}}`), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	result, err := ApplyChanges(configPath, nil, []types.MCPServer{{Name: "missing", Scope: types.ScopeGlobal}})
+	if err != nil {
+		t.Fatalf("ApplyChanges() unexpected error: %v", err)
+	}
+	if len(result.Changes) != 0 || result.Backup != "" {
+		t.Errorf("ApplyChanges() = %+v, want no changes and no backup", result)
+	}
+}
+
+func TestRevert(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "claude.json")
+	initial := `{
+		"numStartups": 1,
+		"mcpServers": {
+			"context7": {"type": "http", "url": "https://mcp.context7.com/mcp"},
+			"github": {"command": "npx", "env": {"GITHUB_TOKEN": "secret"}},
+			"puppeteer": {"command": "npx"}
+		}
+	}`
+	if err := os.WriteFile(configPath, []byte(initial), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	result, err := RemoveServers(configPath, []types.MCPServer{
+		{Name: "github", Scope: types.ScopeGlobal},
+		{Name: "puppeteer", Scope: types.ScopeGlobal},
+	})
+	if err != nil {
+		t.Fatalf("RemoveServers() unexpected error: %v", err)
+	}
+
+	// Meanwhile, Claude Code updates the file and puppeteer is added again by hand
+	concurrent := `{
+		"numStartups": 2,
+		"mcpServers": {
+			"context7": {"type": "http", "url": "https://mcp.context7.com/mcp"},
+			"puppeteer": {"command": "bunx"}
+		}
+	}`
+	if err := os.WriteFile(configPath, []byte(concurrent), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	reverted, conflicts, err := Revert(configPath, result.Changes)
+	if err != nil {
+		t.Fatalf("Revert() unexpected error: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Name != "puppeteer" {
+		t.Errorf("Revert() conflicts = %v, want puppeteer", conflicts)
+	}
+	if len(reverted.Changes) != 1 || reverted.Changes[0].Name != "github" {
+		t.Errorf("Revert() changes = %v, want github", reverted.Changes)
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	want := map[string]interface{}{
+		"numStartups": float64(2),
+		"mcpServers": map[string]interface{}{
+			"context7":  map[string]interface{}{"type": "http", "url": "https://mcp.context7.com/mcp"},
+			"github":    map[string]interface{}{"command": "npx", "env": map[string]interface{}{"GITHUB_TOKEN": "secret"}},
+			"puppeteer": map[string]interface{}{"command": "bunx"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Revert() result mismatch (-want +got):\n%s", diff)
+	}
+}
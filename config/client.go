@@ -0,0 +1,361 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+const (
+	// ClientClaudeCode is the name of Claude Code, the default client.
+	ClientClaudeCode = "claude-code"
+	// ClientClaudeDesktop is the name of the Claude Desktop app.
+	ClientClaudeDesktop = "claude-desktop"
+	// ClientCursor is the name of the Cursor editor.
+	ClientCursor = "cursor"
+	// ClientVSCode is the name of Visual Studio Code.
+	ClientVSCode = "vscode"
+	// ClientCodex is the name of the Codex CLI.
+	ClientCodex = "codex"
+	// ClientGemini is the name of the Gemini CLI.
+	ClientGemini = "gemini"
+
+	// DesktopConfigPathEnv overrides the path of the Claude Desktop config file.
+	DesktopConfigPathEnv = "MCP_TIDY_DESKTOP_CONFIG"
+	// CursorConfigPathEnv overrides the path of Cursor's global config file.
+	CursorConfigPathEnv = "MCP_TIDY_CURSOR_CONFIG"
+	// VSCodeConfigPathEnv overrides the path of VS Code's user config file.
+	VSCodeConfigPathEnv = "MCP_TIDY_VSCODE_CONFIG"
+	// CodexConfigPathEnv overrides the path of Codex's config file.
+	CodexConfigPathEnv = "MCP_TIDY_CODEX_CONFIG"
+	// GeminiConfigPathEnv overrides the path of Gemini CLI's user settings file.
+	GeminiConfigPathEnv = "MCP_TIDY_GEMINI_CONFIG"
+	// CodexHomeEnv is the variable Codex uses to move its config out of ~/.codex.
+	CodexHomeEnv = "CODEX_HOME"
+)
+
+// Client is an MCP client whose config file mcp-tidy can read and change.
+// Most clients keep their servers in the mcpServers format of
+// ~/.claude.json; VS Code calls the object "servers", and Codex keeps them
+// in TOML. The writers in this package handle every format, so they work on
+// any client's file.
+type Client interface {
+	// Name identifies the client in flags and output, e.g. "claude-code".
+	Name() string
+	// Title is the client's name for messages, e.g. "Claude Code".
+	Title() string
+	// DefaultConfigPath returns where the client keeps its config file.
+	DefaultConfigPath() string
+	// Load reads the client's config file. Its servers have
+	// types.MCPServer.Client set, except for Claude Code's.
+	Load(path string) (*Config, error)
+	// HasTranscripts reports whether the client records its tool calls in
+	// transcripts mcp-tidy can read.
+	HasTranscripts() bool
+	// ProjectConfigFile returns the path of the client's config file in a
+	// project, relative to the project root, or "" if it has none. Claude
+	// Code's .mcp.json is read by LoadMCPJSON instead, as it needs approval.
+	ProjectConfigFile() string
+}
+
+// ClaudeCode is the Claude Code CLI, configured in ~/.claude.json.
+type ClaudeCode struct{}
+
+// Name implements Client.
+func (ClaudeCode) Name() string { return ClientClaudeCode }
+
+// Title implements Client.
+func (ClaudeCode) Title() string { return "Claude Code" }
+
+// DefaultConfigPath implements Client.
+func (ClaudeCode) DefaultConfigPath() string { return DefaultConfigPath() }
+
+// Load implements Client.
+func (ClaudeCode) Load(path string) (*Config, error) { return Load(path) }
+
+// HasTranscripts implements Client.
+func (ClaudeCode) HasTranscripts() bool { return true }
+
+// ProjectConfigFile implements Client.
+func (ClaudeCode) ProjectConfigFile() string { return "" }
+
+// ClaudeDesktop is the Claude Desktop app, configured in
+// claude_desktop_config.json. It only has global servers.
+type ClaudeDesktop struct{}
+
+// Name implements Client.
+func (ClaudeDesktop) Name() string { return ClientClaudeDesktop }
+
+// Title implements Client.
+func (ClaudeDesktop) Title() string { return "Claude Desktop" }
+
+// DefaultConfigPath implements Client: $MCP_TIDY_DESKTOP_CONFIG, or
+// claude_desktop_config.json in the user config dir
+// (~/.config/Claude on Linux).
+func (ClaudeDesktop) DefaultConfigPath() string {
+	if path := os.Getenv(DesktopConfigPathEnv); path != "" {
+		return path
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "Claude", "claude_desktop_config.json")
+}
+
+// Load implements Client.
+func (ClaudeDesktop) Load(path string) (*Config, error) { return load(path, ClientClaudeDesktop) }
+
+// HasTranscripts implements Client.
+func (ClaudeDesktop) HasTranscripts() bool { return false }
+
+// ProjectConfigFile implements Client.
+func (ClaudeDesktop) ProjectConfigFile() string { return "" }
+
+// Cursor is the Cursor editor, configured in ~/.cursor/mcp.json and in
+// .cursor/mcp.json of each project.
+type Cursor struct{}
+
+// Name implements Client.
+func (Cursor) Name() string { return ClientCursor }
+
+// Title implements Client.
+func (Cursor) Title() string { return "Cursor" }
+
+// DefaultConfigPath implements Client: $MCP_TIDY_CURSOR_CONFIG, or
+// ~/.cursor/mcp.json.
+func (Cursor) DefaultConfigPath() string {
+	if path := os.Getenv(CursorConfigPathEnv); path != "" {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".cursor", "mcp.json")
+}
+
+// Load implements Client.
+func (Cursor) Load(path string) (*Config, error) { return load(path, ClientCursor) }
+
+// HasTranscripts implements Client.
+func (Cursor) HasTranscripts() bool { return false }
+
+// ProjectConfigFile implements Client.
+func (Cursor) ProjectConfigFile() string { return filepath.Join(".cursor", "mcp.json") }
+
+// VSCode is Visual Studio Code, configured in mcp.json in the user config dir
+// and in .vscode/mcp.json of each project. Its files keep the servers under
+// "servers", next to the "inputs" their ${input:...} references prompt for;
+// the inputs are left as they are.
+type VSCode struct{}
+
+// Name implements Client.
+func (VSCode) Name() string { return ClientVSCode }
+
+// Title implements Client.
+func (VSCode) Title() string { return "VS Code" }
+
+// DefaultConfigPath implements Client: $MCP_TIDY_VSCODE_CONFIG, or
+// Code/User/mcp.json in the user config dir (~/.config on Linux).
+func (VSCode) DefaultConfigPath() string {
+	if path := os.Getenv(VSCodeConfigPathEnv); path != "" {
+		return path
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "Code", "User", "mcp.json")
+}
+
+// Load implements Client.
+func (VSCode) Load(path string) (*Config, error) { return load(path, ClientVSCode) }
+
+// HasTranscripts implements Client.
+func (VSCode) HasTranscripts() bool { return false }
+
+// ProjectConfigFile implements Client.
+func (VSCode) ProjectConfigFile() string { return filepath.Join(".vscode", "mcp.json") }
+
+// Codex is the Codex CLI, configured in the [mcp_servers.NAME] tables of
+// ~/.codex/config.toml. It only has global servers.
+type Codex struct{}
+
+// Name implements Client.
+func (Codex) Name() string { return ClientCodex }
+
+// Title implements Client.
+func (Codex) Title() string { return "Codex" }
+
+// DefaultConfigPath implements Client: $MCP_TIDY_CODEX_CONFIG, or
+// config.toml in $CODEX_HOME or ~/.codex.
+func (Codex) DefaultConfigPath() string {
+	if path := os.Getenv(CodexConfigPathEnv); path != "" {
+		return path
+	}
+	if dir := os.Getenv(CodexHomeEnv); dir != "" {
+		return filepath.Join(dir, "config.toml")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".codex", "config.toml")
+}
+
+// Load implements Client.
+func (Codex) Load(path string) (*Config, error) { return load(path, ClientCodex) }
+
+// HasTranscripts implements Client.
+func (Codex) HasTranscripts() bool { return false }
+
+// ProjectConfigFile implements Client.
+func (Codex) ProjectConfigFile() string { return "" }
+
+// Gemini is the Gemini CLI, configured in ~/.gemini/settings.json and in
+// .gemini/settings.json of each project.
+type Gemini struct{}
+
+// Name implements Client.
+func (Gemini) Name() string { return ClientGemini }
+
+// Title implements Client.
+func (Gemini) Title() string { return "Gemini CLI" }
+
+// DefaultConfigPath implements Client: $MCP_TIDY_GEMINI_CONFIG, or
+// ~/.gemini/settings.json.
+func (Gemini) DefaultConfigPath() string {
+	if path := os.Getenv(GeminiConfigPathEnv); path != "" {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".gemini", "settings.json")
+}
+
+// Load implements Client.
+func (Gemini) Load(path string) (*Config, error) { return load(path, ClientGemini) }
+
+// HasTranscripts implements Client.
+func (Gemini) HasTranscripts() bool { return false }
+
+// ProjectConfigFile implements Client.
+func (Gemini) ProjectConfigFile() string { return filepath.Join(".gemini", "settings.json") }
+
+// Clients returns every supported client, Claude Code first.
+func Clients() []Client {
+	return []Client{ClaudeCode{}, ClaudeDesktop{}, Cursor{}, VSCode{}, Codex{}, Gemini{}}
+}
+
+// ParseClient returns the client with the given name. An empty name means
+// Claude Code, matching types.MCPServer.Client.
+func ParseClient(name string) (Client, error) {
+	if name == "" {
+		return ClaudeCode{}, nil
+	}
+	for _, client := range Clients() {
+		if client.Name() == name {
+			return client, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown client %q (expected %s)", name, ClientNames())
+}
+
+// ClientNames lists the names of the supported clients for messages, e.g.
+// "claude-code, cursor or vscode".
+func ClientNames() string {
+	clients := Clients()
+	names := make([]string, 0, len(clients))
+	for _, client := range clients[:len(clients)-1] {
+		names = append(names, client.Name())
+	}
+	return strings.Join(names, ", ") + " or " + clients[len(clients)-1].Name()
+}
+
+// LoadProjectServers reads the client's config file in a project and returns
+// its servers in the project scope, ordered by name. A project without the
+// file, and a client without project files, have no servers.
+func LoadProjectServers(client Client, projectPath string) ([]types.MCPServer, error) {
+	file := client.ProjectConfigFile()
+	if file == "" {
+		return nil, nil
+	}
+	cfg, err := client.Load(filepath.Join(projectPath, file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filepath.Join(projectPath, file), err)
+	}
+
+	servers := cfg.GlobalServers()
+	for i := range servers {
+		servers[i].Scope = types.ScopeProject
+		servers[i].ProjectPath = projectPath
+	}
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Name < servers[j].Name
+	})
+	return servers, nil
+}
+
+// entrySchema describes how a client's config file spells a server entry.
+type entrySchema struct {
+	// serversKey is the key of the global servers object.
+	serversKey string
+	// typed reports whether the client reads the transport from "type".
+	// Clients without it tell the transport from the keys that are set.
+	typed bool
+	// urlType is the transport of an entry with a url and no type. Claude
+	// Code and Claude Desktop take it for a stdio server lacking a command.
+	urlType types.ServerType
+	// httpURLKey, if set, holds the url of http servers, leaving url to sse
+	// servers, as Gemini CLI's httpUrl does.
+	httpURLKey string
+	// headersKey is the key of the HTTP headers.
+	headersKey string
+	// extraKeys are the client's own keys, which mcp-tidy leaves as they are.
+	extraKeys []string
+}
+
+// schemaOf returns the entry schema of a client, given its name as in
+// types.MCPServer.Client.
+func schemaOf(client string) entrySchema {
+	schema := entrySchema{serversKey: "mcpServers", typed: true, urlType: types.ServerTypeStdio, headersKey: "headers"}
+	switch client {
+	case ClientCursor:
+		schema.urlType = types.ServerTypeHTTP
+	case ClientVSCode:
+		schema.serversKey = vscodeServersKey
+		schema.urlType = types.ServerTypeHTTP
+		schema.extraKeys = []string{"envFile", "dev"}
+	case ClientCodex:
+		schema.serversKey = codexServersKey
+		schema.typed = false
+		schema.urlType = types.ServerTypeHTTP
+		schema.headersKey = "http_headers"
+		schema.extraKeys = []string{
+			"cwd", "env_vars", "env_http_headers", "bearer_token_env_var", "enabled",
+			"enabled_tools", "disabled_tools", "startup_timeout_sec", "startup_timeout_ms", "tool_timeout_sec",
+		}
+	case ClientGemini:
+		schema.typed = false
+		schema.urlType = types.ServerTypeSSE
+		schema.httpURLKey = "httpUrl"
+		schema.extraKeys = []string{
+			"cwd", "timeout", "trust", "description", "includeTools", "excludeTools",
+			"oauth", "authProviderType", "targetAudience", "targetServiceAccount",
+		}
+	}
+	return schema
+}
+
+// knows reports whether key is a valid key of the client's server entries.
+func (s *entrySchema) knows(key string) bool {
+	return knownServerKeys[key] || key == s.headersKey || key == s.httpURLKey || slices.Contains(s.extraKeys, key)
+}
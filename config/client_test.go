@@ -0,0 +1,222 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestParseClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Client
+		wantErr bool
+	}{
+		{name: "", want: ClaudeCode{}},
+		{name: "claude-code", want: ClaudeCode{}},
+		{name: "claude-desktop", want: ClaudeDesktop{}},
+		{name: "cursor", want: Cursor{}},
+		{name: "vscode", want: VSCode{}},
+		{name: "codex", want: Codex{}},
+		{name: "gemini", want: Gemini{}},
+		{name: "zed", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseClient(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseClient(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseClient(%q) mismatch (-want +got):\n%s", tt.name, diff)
+			}
+		})
+	}
+}
+
+func TestClaudeDesktop_DefaultConfigPath(t *testing.T) {
+	t.Setenv(DesktopConfigPathEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", "/home/user/.config")
+	if got, want := (ClaudeDesktop{}).DefaultConfigPath(), "/home/user/.config/Claude/claude_desktop_config.json"; got != want {
+		t.Errorf("DefaultConfigPath() = %q, want %q", got, want)
+	}
+
+	t.Setenv(DesktopConfigPathEnv, "/tmp/desktop.json")
+	if got := (ClaudeDesktop{}).DefaultConfigPath(); got != "/tmp/desktop.json" {
+		t.Errorf("DefaultConfigPath() = %q, want %q", got, "/tmp/desktop.json")
+	}
+}
+
+func TestClaudeDesktop_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claude_desktop_config.json")
+	writeFile(t, path, `{"mcpServers": {"filesystem": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-filesystem"]}}, "globalShortcut": ""}`)
+
+	cfg, err := ClaudeDesktop{}.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []types.MCPServer{{
+		Name:    "filesystem",
+		Type:    types.ServerTypeStdio,
+		Command: "npx",
+		Args:    []string{"-y", "@modelcontextprotocol/server-filesystem"},
+		Scope:   types.ScopeGlobal,
+		Client:  ClientClaudeDesktop,
+	}}
+	if diff := cmp.Diff(want, cfg.Servers(), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Servers() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCursor_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	writeFile(t, path, `{"mcpServers": {
+		"context7": {"url": "https://mcp.context7.com/mcp", "headers": {"API_KEY": "${env:CONTEXT7_KEY}"}},
+		"puppeteer": {"command": "npx", "args": ["-y", "@anthropic/server-puppeteer"]}
+	}}`)
+
+	cfg, err := Cursor{}.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// Cursor treats an entry with only a url as a remote server
+	context7, _ := cfg.GetServer("context7")
+	want := types.MCPServer{
+		Name:    "context7",
+		Type:    types.ServerTypeHTTP,
+		TypeStr: "http",
+		URL:     "https://mcp.context7.com/mcp",
+		Headers: map[string]string{"API_KEY": "${env:CONTEXT7_KEY}"},
+		Scope:   types.ScopeGlobal,
+		Client:  ClientCursor,
+	}
+	if diff := cmp.Diff(want, context7); diff != "" {
+		t.Errorf("GetServer() mismatch (-want +got):\n%s", diff)
+	}
+	if len(cfg.Issues()) != 0 {
+		t.Errorf("Issues() = %v, want none", cfg.Issues())
+	}
+}
+
+func TestVSCode_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.json")
+	writeFile(t, path, `{
+		"inputs": [{"type": "promptString", "id": "github-token", "description": "GitHub token", "password": true}],
+		"servers": {
+			"github": {"type": "http", "url": "https://api.githubcopilot.com/mcp/", "headers": {"Authorization": "Bearer ${input:github-token}"}},
+			"fetch": {"type": "stdio", "command": "uvx", "args": ["mcp-server-fetch"]}
+		}
+	}`)
+
+	cfg, err := VSCode{}.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	got := make(map[string]string)
+	for _, s := range cfg.Servers() {
+		got[s.Name] = s.Client + " " + s.CommandString()
+	}
+	want := map[string]string{
+		"github": "vscode [http] https://api.githubcopilot.com/mcp/",
+		"fetch":  "vscode uvx mcp-server-fetch",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Servers() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCodex_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeFile(t, path, `model = "o3"
+
+[mcp_servers.context7]
+command = "npx"
+args = ["-y", "@upstash/context7-mcp"]
+startup_timeout_sec = 20
+
+[mcp_servers.context7.env]
+CONTEXT7_KEY = "abc"
+
+# Remote servers have a url and no command
+[mcp_servers.figma]
+url = "https://mcp.figma.com/mcp"
+http_headers = { "X-Team" = "design" }
+`)
+
+	cfg, err := Codex{}.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []types.MCPServer{
+		{
+			Name: "context7", Type: types.ServerTypeStdio, Command: "npx", Args: []string{"-y", "@upstash/context7-mcp"},
+			Env: map[string]string{"CONTEXT7_KEY": "abc"}, Scope: types.ScopeGlobal, Client: ClientCodex,
+		},
+		{
+			Name: "figma", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://mcp.figma.com/mcp",
+			Headers: map[string]string{"X-Team": "design"}, Scope: types.ScopeGlobal, Client: ClientCodex,
+		},
+	}
+	sortByName := cmpopts.SortSlices(func(a, b types.MCPServer) bool { return a.Name < b.Name })
+	if diff := cmp.Diff(want, cfg.Servers(), sortByName); diff != "" {
+		t.Errorf("Servers() mismatch (-want +got):\n%s", diff)
+	}
+	if len(cfg.Issues()) != 0 {
+		t.Errorf("Issues() = %v, want none", cfg.Issues())
+	}
+}
+
+func TestGemini_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	writeFile(t, path, `{"theme": "Default", "mcpServers": {
+		"github": {"httpUrl": "https://api.githubcopilot.com/mcp/", "headers": {"Authorization": "Bearer $GITHUB_TOKEN"}, "trust": false},
+		"events": {"url": "http://localhost:8080/sse", "timeout": 30000},
+		"fetch": {"command": "uvx", "args": ["mcp-server-fetch"], "cwd": "/tmp"}
+	}}`)
+
+	cfg, err := Gemini{}.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// Gemini CLI reads httpUrl as an http server and url as an sse server
+	got := make(map[string]string)
+	for _, s := range cfg.Servers() {
+		got[s.Name] = s.Client + " " + s.CommandString()
+	}
+	want := map[string]string{
+		"github": "gemini [http] https://api.githubcopilot.com/mcp/",
+		"events": "gemini [sse] http://localhost:8080/sse",
+		"fetch":  "gemini uvx mcp-server-fetch",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Servers() mismatch (-want +got):\n%s", diff)
+	}
+	if len(cfg.Issues()) != 0 {
+		t.Errorf("Issues() = %v, want none", cfg.Issues())
+	}
+}
+
+func TestLoadProjectServers(t *testing.T) {
+	project := t.TempDir()
+	writeFile(t, filepath.Join(project, ".vscode", "mcp.json"), `{"servers": {"fetch": {"type": "stdio", "command": "uvx"}}}`)
+
+	servers, err := LoadProjectServers(VSCode{}, project)
+	if err != nil {
+		t.Fatalf("LoadProjectServers() error = %v", err)
+	}
+	want := []types.MCPServer{{Name: "fetch", Type: types.ServerTypeStdio, TypeStr: "stdio", Command: "uvx", Scope: types.ScopeProject, ProjectPath: project, Client: ClientVSCode}}
+	if diff := cmp.Diff(want, servers, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("LoadProjectServers() mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, client := range []Client{Cursor{}, ClaudeDesktop{}} {
+		servers, err := LoadProjectServers(client, project)
+		if err != nil || len(servers) != 0 {
+			t.Errorf("LoadProjectServers(%s) = %v, %v; want no servers", client.Name(), servers, err)
+		}
+	}
+}
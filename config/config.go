@@ -1,4 +1,5 @@
-// Package config handles reading and writing Claude Code MCP configuration.
+// Package config handles reading and writing the MCP configuration of Claude
+// Code and other clients.
 package config
 
 import (
@@ -6,6 +7,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/nnnkkk7/mcp-tidy/types"
 )
@@ -18,6 +20,55 @@ type rawServerConfig struct {
 	Env     map[string]string `json:"env,omitempty"`
 	URL     string            `json:"url,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
+	// HTTPURL and HTTPHeaders are Gemini CLI's httpUrl and Codex's
+	// http_headers (see entrySchema).
+	HTTPURL     string            `json:"httpUrl,omitempty"`
+	HTTPHeaders map[string]string `json:"http_headers,omitempty"`
+}
+
+// UnmarshalJSON decodes a server entry leniently: values of the wrong type
+// are dropped instead of failing the whole config. Validate reports them.
+func (r *rawServerConfig) UnmarshalJSON(data []byte) error {
+	type strict rawServerConfig
+	if err := json.Unmarshal(data, (*strict)(r)); err == nil {
+		return nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*r = rawServerConfig{}
+	r.Type, _ = fields["type"].(string)
+	r.Command, _ = fields["command"].(string)
+	r.URL, _ = fields["url"].(string)
+	r.HTTPURL, _ = fields["httpUrl"].(string)
+	if args, ok := fields["args"].([]interface{}); ok {
+		for _, arg := range args {
+			if s, ok := arg.(string); ok {
+				r.Args = append(r.Args, s)
+			}
+		}
+	}
+	r.Env = stringMap(fields["env"])
+	r.Headers = stringMap(fields["headers"])
+	r.HTTPHeaders = stringMap(fields["http_headers"])
+	return nil
+}
+
+// stringMap returns the string values of a parsed JSON object.
+func stringMap(value interface{}) map[string]string {
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	result := make(map[string]string, len(values))
+	for key, v := range values {
+		if s, ok := v.(string); ok {
+			result[key] = s
+		}
+	}
+	return result
 }
 
 // rawProjectConfig represents a project's configuration.
@@ -29,19 +80,41 @@ type rawProjectConfig struct {
 type rawConfig struct {
 	MCPServers map[string]rawServerConfig  `json:"mcpServers,omitempty"`
 	Projects   map[string]rawProjectConfig `json:"projects,omitempty"`
+	// Servers and CodexServers hold the servers of VS Code's mcp.json and
+	// of Codex's config.toml, used when the file has no mcpServers (see
+	// serversKey).
+	Servers      map[string]rawServerConfig `json:"servers,omitempty"`
+	CodexServers map[string]rawServerConfig `json:"mcp_servers,omitempty"`
 }
 
 // Config holds the parsed MCP server configuration.
 type Config struct {
 	path       string
+	client     string // types.MCPServer.Client of the servers
 	raw        rawConfig
 	servers    []types.MCPServer
 	serverMap  map[string]types.MCPServer
 	rawContent []byte
+	issues     []Issue
 }
 
-// DefaultConfigPath returns the default path to the Claude configuration file.
+const (
+	// ConfigPathEnv overrides the path of the Claude configuration file.
+	ConfigPathEnv = "MCP_TIDY_CONFIG"
+	// ClaudeConfigDirEnv is the variable Claude Code uses to move its
+	// configuration (.claude.json and projects/) out of the home directory.
+	ClaudeConfigDirEnv = "CLAUDE_CONFIG_DIR"
+)
+
+// DefaultConfigPath returns the default path to the Claude configuration file:
+// $MCP_TIDY_CONFIG, $CLAUDE_CONFIG_DIR/.claude.json or ~/.claude.json.
 func DefaultConfigPath() string {
+	if path := os.Getenv(ConfigPathEnv); path != "" {
+		return path
+	}
+	if dir := os.Getenv(ClaudeConfigDirEnv); dir != "" {
+		return filepath.Join(dir, ".claude.json")
+	}
 	homeDir, _ := os.UserHomeDir()
 	return filepath.Join(homeDir, ".claude.json")
 }
@@ -49,8 +122,16 @@ func DefaultConfigPath() string {
 // Load reads and parses the Claude configuration file.
 // If the file does not exist, returns an empty config (not an error).
 func Load(path string) (*Config, error) {
+	return load(path, "")
+}
+
+// load reads a client's config file, marking its servers as belonging to
+// client. JSON files are read in the ~/.claude.json format, and TOML files
+// in that of Codex's config.toml.
+func load(path, client string) (*Config, error) {
 	cfg := &Config{
 		path:      path,
+		client:    client,
 		serverMap: make(map[string]types.MCPServer),
 	}
 
@@ -64,10 +145,28 @@ func Load(path string) (*Config, error) {
 
 	cfg.rawContent = data
 
+	data, err = configJSON(path, data)
+	if err != nil {
+		return nil, err
+	}
 	if err := json.Unmarshal(data, &cfg.raw); err != nil {
+		// Point at the offending values if the structure is wrong
+		if issues, verr := validate(data, client); verr == nil && len(Errors(issues)) > 0 {
+			return nil, &ValidationError{Path: path, Issues: Errors(issues)}
+		}
 		return nil, err
 	}
 
+	switch {
+	case cfg.raw.MCPServers != nil:
+	case cfg.raw.Servers != nil:
+		cfg.raw.MCPServers = cfg.raw.Servers
+	default:
+		cfg.raw.MCPServers = cfg.raw.CodexServers
+	}
+
+	// The JSON is valid at this point, so validate can't fail
+	cfg.issues, _ = validate(data, client)
 	cfg.parseServers()
 	return cfg, nil
 }
@@ -75,8 +174,19 @@ func Load(path string) (*Config, error) {
 // parseServers converts raw server configs into typed MCPServer structs.
 func (c *Config) parseServers() {
 	// Parse global servers
+	schema := schemaOf(c.client)
 	for name, raw := range c.raw.MCPServers {
-		server := c.parseServer(name, &raw, types.ScopeGlobal, "")
+		server := parseServer(name, &raw, types.ScopeGlobal, "")
+		server.Client = c.client
+		switch {
+		case schema.httpURLKey != "" && raw.HTTPURL != "" && server.Command == "":
+			server.Type, server.TypeStr, server.URL = types.ServerTypeHTTP, types.ServerTypeHTTP.String(), raw.HTTPURL
+		case schema.urlType != types.ServerTypeStdio && server.TypeStr == "" && server.Command == "" && server.URL != "":
+			server.Type, server.TypeStr = schema.urlType, schema.urlType.String()
+		}
+		if schema.headersKey == "http_headers" {
+			server.Headers = raw.HTTPHeaders
+		}
 		c.servers = append(c.servers, server)
 		c.serverMap[name] = server
 	}
@@ -84,7 +194,8 @@ func (c *Config) parseServers() {
 	// Parse project-specific servers
 	for projectPath, project := range c.raw.Projects {
 		for name, raw := range project.MCPServers {
-			server := c.parseServer(name, &raw, types.ScopeProject, projectPath)
+			server := parseServer(name, &raw, types.ScopeProject, projectPath)
+			server.Client = c.client
 			c.servers = append(c.servers, server)
 			c.serverMap[name] = server
 		}
@@ -92,15 +203,10 @@ func (c *Config) parseServers() {
 }
 
 // parseServer converts a raw server config into a typed MCPServer.
-func (c *Config) parseServer(name string, raw *rawServerConfig, scope types.Scope, projectPath string) types.MCPServer {
-	serverType := types.ServerTypeStdio
-	if raw.Type == "http" {
-		serverType = types.ServerTypeHTTP
-	}
-
+func parseServer(name string, raw *rawServerConfig, scope types.Scope, projectPath string) types.MCPServer {
 	return types.MCPServer{
 		Name:        name,
-		Type:        serverType,
+		Type:        types.ParseServerType(raw.Type),
 		TypeStr:     raw.Type,
 		Command:     raw.Command,
 		Args:        raw.Args,
@@ -145,11 +251,27 @@ func (c *Config) ProjectServers(projectPath string) []types.MCPServer {
 	return result
 }
 
+// ProjectPaths returns the paths of the projects in the config, sorted.
+func (c *Config) ProjectPaths() []string {
+	paths := make([]string, 0, len(c.raw.Projects))
+	for path := range c.raw.Projects {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // Path returns the config file path.
 func (c *Config) Path() string {
 	return c.path
 }
 
+// Issues returns the problems Validate found in the server entries when the
+// config was loaded.
+func (c *Config) Issues() []Issue {
+	return c.issues
+}
+
 // RawContent returns the original JSON content.
 func (c *Config) RawContent() []byte {
 	return c.rawContent
@@ -133,6 +133,41 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestLoad_ServerTypes(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".claude.json")
+	data := `{"mcpServers": {
+		"events": {"type": "sse", "url": "http://localhost:8080/sse"},
+		"future": {"type": "websocket", "url": "wss://example.com/mcp"},
+		"local": {"command": "npx"}
+	}}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := map[string]types.ServerType{
+		"events": types.ServerTypeSSE,
+		"future": types.ServerTypeUnknown,
+		"local":  types.ServerTypeStdio,
+	}
+	for name, wantType := range want {
+		server, ok := cfg.GetServer(name)
+		if !ok {
+			t.Fatalf("GetServer(%q) not found", name)
+		}
+		if server.Type != wantType {
+			t.Errorf("server %s: Type = %v, want %v", name, server.Type, wantType)
+		}
+	}
+	if future, _ := cfg.GetServer("future"); future.TypeName() != "websocket" {
+		t.Errorf("TypeName() = %q, want websocket", future.TypeName())
+	}
+}
+
 func TestConfig_GetServer(t *testing.T) {
 	cfg, err := Load("../testdata/claude.json")
 	if err != nil {
@@ -190,10 +225,38 @@ func TestDefaultConfigPath(t *testing.T) {
 		t.Fatalf("failed to get home dir: %v", err)
 	}
 
-	want := filepath.Join(homeDir, ".claude.json")
-	got := DefaultConfigPath()
+	tests := []struct {
+		name      string
+		configEnv string
+		dirEnv    string
+		want      string
+	}{
+		{
+			name: "home directory",
+			want: filepath.Join(homeDir, ".claude.json"),
+		},
+		{
+			name:   "claude config dir",
+			dirEnv: "/srv/claude",
+			want:   filepath.Join("/srv/claude", ".claude.json"),
+		},
+		{
+			name:      "explicit config wins",
+			configEnv: "/tmp/exported.json",
+			dirEnv:    "/srv/claude",
+			want:      "/tmp/exported.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ConfigPathEnv, tt.configEnv)
+			t.Setenv(ClaudeConfigDirEnv, tt.dirEnv)
 
-	if diff := cmp.Diff(want, got); diff != "" {
-		t.Errorf("DefaultConfigPath() mismatch (-want +got):\n%s", diff)
+			got := DefaultConfigPath()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("DefaultConfigPath() mismatch (-want +got):\n%s", diff)
+			}
+		})
 	}
 }
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// lockTimeout is how long to wait for another process to release the lock.
+var lockTimeout = 5 * time.Second
+
+const (
+	// lockPollInterval is how often the lock is retried while waiting.
+	lockPollInterval = 100 * time.Millisecond
+	// staleLockAge is the age after which a lock is assumed to be left behind
+	// by a crashed process and is taken over.
+	staleLockAge = 30 * time.Second
+	// maxWriteAttempts is how often a write is retried when the config
+	// changes between reading and writing it.
+	maxWriteAttempts = 3
+)
+
+var (
+	// ErrLocked is returned when another process holds the config lock.
+	ErrLocked = errors.New("config is locked by another process")
+	// ErrConcurrentModification is returned when the config keeps changing
+	// while it is being updated.
+	ErrConcurrentModification = errors.New("config was modified by another process while updating it")
+)
+
+// LockPath returns the path of the advisory lock for a config file.
+func LockPath(configPath string) string {
+	return configPath + ".lock"
+}
+
+// lock takes the advisory lock of a config file, waiting up to lockTimeout.
+// The lock is a directory, as mkdir is atomic everywhere, which is also what
+// lockfile implementations of other tools do. The returned function releases it.
+func lock(configPath string) (func(), error) {
+	path := LockPath(configPath)
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := os.Mkdir(path, 0o700)
+		if err == nil {
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock: %w", err)
+		}
+
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w (remove %s if no other mcp-tidy is running)", ErrLocked, path)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// fingerprint identifies one version of a file's content.
+type fingerprint struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+	hash    [sha256.Size]byte
+}
+
+// readFile reads a file and returns its content with a fingerprint.
+// A missing file is not an error; its fingerprint records that it doesn't exist.
+func readFile(path string) ([]byte, fingerprint, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fingerprint{}, nil
+	}
+	if err != nil {
+		return nil, fingerprint{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fingerprint{}, err
+	}
+	return content, fingerprint{
+		exists:  true,
+		size:    info.Size(),
+		modTime: info.ModTime(),
+		hash:    sha256.Sum256(content),
+	}, nil
+}
+
+// verify returns errModified when the file no longer has the fingerprinted content.
+func (f fingerprint) verify(path string) error {
+	_, current, err := readFile(path)
+	if err != nil {
+		return err
+	}
+	if current.exists != f.exists || current.size != f.size ||
+		!current.modTime.Equal(f.modTime) || current.hash != f.hash {
+		return errModified
+	}
+	return nil
+}
+
+// errModified signals that the file changed since it was read.
+var errModified = errors.New("file modified")
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestLock(t *testing.T) {
+	defer func(timeout time.Duration) { lockTimeout = timeout }(lockTimeout)
+	lockTimeout = 200 * time.Millisecond
+
+	configPath := filepath.Join(t.TempDir(), "claude.json")
+
+	unlock, err := lock(configPath)
+	if err != nil {
+		t.Fatalf("lock() unexpected error: %v", err)
+	}
+	if _, err := lock(configPath); !errors.Is(err, ErrLocked) {
+		t.Errorf("second lock() error = %v, want ErrLocked", err)
+	}
+
+	unlock()
+	unlock, err = lock(configPath)
+	if err != nil {
+		t.Fatalf("lock() after unlock unexpected error: %v", err)
+	}
+	unlock()
+}
+
+func TestLock_Stale(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "claude.json")
+	if err := os.Mkdir(LockPath(configPath), 0o700); err != nil {
+		t.Fatalf("failed to create lock: %v", err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(LockPath(configPath), old, old); err != nil {
+		t.Fatalf("failed to age lock: %v", err)
+	}
+
+	unlock, err := lock(configPath)
+	if err != nil {
+		t.Fatalf("lock() should take over a stale lock: %v", err)
+	}
+	unlock()
+}
+
+func TestUpdateConfig_ConcurrentModification(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "claude.json")
+	if err := os.WriteFile(configPath, []byte(`{"mcpServers": {"a": {"command": "npx"}, "b": {"command": "npx"}}}`), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	// Simulate Claude Code writing the config once while we remove "a"
+	calls := 0
+	_, err := updateConfig(configPath, false, false, func(raw map[string]interface{}) {
+		calls++
+		if calls == 1 {
+			concurrent := `{"mcpServers": {"a": {"command": "npx"}, "b": {"command": "npx"}}, "numStartups": 4}`
+			if err := os.WriteFile(configPath, []byte(concurrent), 0o644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+		}
+		applyChanges(raw, nil, []types.MCPServer{{Name: "a", Scope: types.ScopeGlobal}})
+	})
+	if err != nil {
+		t.Fatalf("updateConfig() unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("edit called %d times, want 2", calls)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if _, ok := cfg.GetServer("a"); ok {
+		t.Error("server a should be removed")
+	}
+	content, _ := os.ReadFile(configPath)
+	if !json.Valid(content) || !strings.Contains(string(content), `"numStartups": 4`) {
+		t.Errorf("concurrent change was lost:\n%s", content)
+	}
+}
+
+func TestUpdateConfig_KeepsChanging(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "claude.json")
+	if err := os.WriteFile(configPath, []byte(`{"mcpServers": {"a": {"command": "npx"}}}`), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	calls := 0
+	_, err := updateConfig(configPath, false, false, func(raw map[string]interface{}) {
+		calls++
+		concurrent := fmt.Sprintf(`{"mcpServers": {"a": {"command": "npx"}}, "numStartups": %d}`, calls)
+		if err := os.WriteFile(configPath, []byte(concurrent), 0o644); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		applyChanges(raw, nil, []types.MCPServer{{Name: "a", Scope: types.ScopeGlobal}})
+	})
+	if !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("updateConfig() error = %v, want ErrConcurrentModification", err)
+	}
+	if calls != maxWriteAttempts {
+		t.Errorf("edit called %d times, want %d", calls, maxWriteAttempts)
+	}
+	if _, err := os.Stat(LockPath(configPath)); !errors.Is(err, os.ErrNotExist) {
+		t.Error("lock should be released")
+	}
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+const (
+	// ManagedDirEnv overrides the directory of the managed (enterprise)
+	// configuration files.
+	ManagedDirEnv = "MCP_TIDY_MANAGED_DIR"
+	// ManagedMCPFileName is the file in which administrators deploy servers.
+	ManagedMCPFileName = "managed-mcp.json"
+	// ManagedSettingsFileName is the file that holds the managed allow and deny lists.
+	ManagedSettingsFileName = "managed-settings.json"
+)
+
+// ErrServerNotAllowed is returned when the managed policy doesn't allow a server.
+var ErrServerNotAllowed = errors.New("not allowed by the managed policy")
+
+// DefaultManagedDir returns the directory of the managed configuration:
+// $MCP_TIDY_MANAGED_DIR, or the system-wide directory Claude Code reads it
+// from (/etc/claude-code on Linux).
+func DefaultManagedDir() string {
+	if dir := os.Getenv(ManagedDirEnv); dir != "" {
+		return dir
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return "/Library/Application Support/ClaudeCode"
+	case "windows":
+		return `C:\Program Files\ClaudeCode`
+	default:
+		return "/etc/claude-code"
+	}
+}
+
+// LoadManagedServers reads the servers of managed-mcp.json in dir, ordered
+// by name. Without the file there are no managed servers.
+func LoadManagedServers(dir string) ([]types.MCPServer, error) {
+	entries, err := readServerFile(filepath.Join(dir, ManagedMCPFileName))
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return parseServerFile(entries, types.ScopeManaged, ""), nil
+}
+
+// ServerRule is an entry of the managed allow or deny list. It matches
+// servers by exactly one of name, command line or URL.
+type ServerRule struct {
+	ServerName    string   `json:"serverName,omitempty"`
+	ServerCommand []string `json:"serverCommand,omitempty"`
+	// ServerURL may contain * wildcards.
+	ServerURL string `json:"serverUrl,omitempty"`
+}
+
+// String describes the rule the way it is written.
+func (r ServerRule) String() string {
+	switch {
+	case r.ServerName != "":
+		return fmt.Sprintf("serverName %q", r.ServerName)
+	case len(r.ServerCommand) > 0:
+		return fmt.Sprintf("serverCommand %q", strings.Join(r.ServerCommand, " "))
+	default:
+		return fmt.Sprintf("serverUrl %q", r.ServerURL)
+	}
+}
+
+// matches reports whether the rule matches a server.
+func (r ServerRule) matches(server *types.MCPServer) bool {
+	switch {
+	case r.ServerName != "":
+		return r.ServerName == server.Name
+	case len(r.ServerCommand) > 0:
+		return server.Type == types.ServerTypeStdio && slices.Equal(r.ServerCommand, append([]string{server.Command}, server.Args...))
+	case r.ServerURL != "":
+		return server.Type.IsRemote() && matchWildcard(r.ServerURL, server.URL)
+	default:
+		return false
+	}
+}
+
+// ManagedPolicy holds the allow and deny lists of managed-settings.json.
+type ManagedPolicy struct {
+	// Path is the file the lists were read from.
+	Path string `json:"-"`
+	// Allowed is the allow list. nil means every server is allowed; an empty
+	// list allows none.
+	Allowed *[]ServerRule `json:"allowedMcpServers"`
+	// Denied is the deny list, which wins over the allow list.
+	Denied []ServerRule `json:"deniedMcpServers"`
+}
+
+// LoadManagedPolicy reads the allow and deny lists of managed-settings.json
+// in dir. Without the file nothing is restricted.
+func LoadManagedPolicy(dir string) (*ManagedPolicy, error) {
+	path := filepath.Join(dir, ManagedSettingsFileName)
+	p := &ManagedPolicy{Path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read managed settings: %w", err)
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Check returns an error wrapping ErrServerNotAllowed when the policy
+// doesn't allow a server, naming the list entry that decided it.
+//
+// As in Claude Code, a server matching the deny list is never allowed. When
+// the allow list has serverCommand entries, stdio servers must match one of
+// them, and when it has serverUrl entries, http and sse servers must match
+// one of them; other servers are allowed by a serverName entry.
+func (p *ManagedPolicy) Check(server *types.MCPServer) error {
+	if p == nil {
+		return nil
+	}
+	for _, rule := range p.Denied {
+		if rule.matches(server) {
+			return fmt.Errorf("%w (deniedMcpServers %s in %s)", ErrServerNotAllowed, rule, p.Path)
+		}
+	}
+	if p.Allowed == nil {
+		return nil
+	}
+
+	var byName, byCommand, byURL []ServerRule
+	for _, rule := range *p.Allowed {
+		switch {
+		case rule.ServerName != "":
+			byName = append(byName, rule)
+		case len(rule.ServerCommand) > 0:
+			byCommand = append(byCommand, rule)
+		case rule.ServerURL != "":
+			byURL = append(byURL, rule)
+		}
+	}
+	candidates := byName
+	switch {
+	case server.Type == types.ServerTypeStdio && len(byCommand) > 0:
+		candidates = byCommand
+	case server.Type.IsRemote() && len(byURL) > 0:
+		candidates = byURL
+	}
+	for _, rule := range candidates {
+		if rule.matches(server) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w (not in allowedMcpServers in %s)", ErrServerNotAllowed, p.Path)
+}
+
+// matchWildcard matches s against a pattern in which * matches any text.
+func matchWildcard(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	re, err := regexp.Compile("^" + strings.Join(parts, ".*") + "$")
+	return err == nil && re.MatchString(s)
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestDefaultManagedDir(t *testing.T) {
+	t.Setenv(ManagedDirEnv, "/tmp/managed")
+	if got := DefaultManagedDir(); got != "/tmp/managed" {
+		t.Errorf("DefaultManagedDir() = %q, want %q", got, "/tmp/managed")
+	}
+}
+
+func TestLoadManagedServers(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ManagedMCPFileName), `{"mcpServers": {
+		"corp-search": {"type": "http", "url": "https://mcp.corp.example.com/search"},
+		"corp-files": {"command": "corp-files-mcp"}
+	}}`)
+
+	servers, err := LoadManagedServers(dir)
+	if err != nil {
+		t.Fatalf("LoadManagedServers() error = %v", err)
+	}
+	want := []types.MCPServer{
+		{Name: "corp-files", Type: types.ServerTypeStdio, Command: "corp-files-mcp", Scope: types.ScopeManaged},
+		{Name: "corp-search", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://mcp.corp.example.com/search", Scope: types.ScopeManaged},
+	}
+	if diff := cmp.Diff(want, servers, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("LoadManagedServers() mismatch (-want +got):\n%s", diff)
+	}
+
+	servers, err = LoadManagedServers(filepath.Join(dir, "missing"))
+	if err != nil || servers != nil {
+		t.Errorf("LoadManagedServers() without file = %v, %v; want nil, nil", servers, err)
+	}
+}
+
+func TestManagedPolicy_Check(t *testing.T) {
+	github := types.MCPServer{Name: "github", Type: types.ServerTypeHTTP, URL: "https://api.github.com/mcp"}
+	puppeteer := types.MCPServer{Name: "puppeteer", Type: types.ServerTypeStdio, Command: "npx", Args: []string{"-y", "@anthropic/server-puppeteer"}}
+
+	tests := []struct {
+		name     string
+		settings string
+		server   types.MCPServer
+		wantErr  bool
+	}{
+		{
+			name:   "no managed settings",
+			server: github,
+		},
+		{
+			name:     "no lists",
+			settings: `{"permissions": {}}`,
+			server:   github,
+		},
+		{
+			name:     "denied by name",
+			settings: `{"deniedMcpServers": [{"serverName": "github"}]}`,
+			server:   github,
+			wantErr:  true,
+		},
+		{
+			name:     "denied by url wildcard",
+			settings: `{"deniedMcpServers": [{"serverUrl": "https://*.github.com/*"}]}`,
+			server:   github,
+			wantErr:  true,
+		},
+		{
+			name:     "deny list wins over allow list",
+			settings: `{"allowedMcpServers": [{"serverName": "github"}], "deniedMcpServers": [{"serverName": "github"}]}`,
+			server:   github,
+			wantErr:  true,
+		},
+		{
+			name:     "empty allow list allows nothing",
+			settings: `{"allowedMcpServers": []}`,
+			server:   github,
+			wantErr:  true,
+		},
+		{
+			name:     "allowed by name",
+			settings: `{"allowedMcpServers": [{"serverName": "github"}]}`,
+			server:   github,
+		},
+		{
+			name:     "not in allow list",
+			settings: `{"allowedMcpServers": [{"serverName": "slack"}]}`,
+			server:   github,
+			wantErr:  true,
+		},
+		{
+			name:     "allowed by command",
+			settings: `{"allowedMcpServers": [{"serverCommand": ["npx", "-y", "@anthropic/server-puppeteer"]}]}`,
+			server:   puppeteer,
+		},
+		{
+			name:     "command entries override name entries for stdio servers",
+			settings: `{"allowedMcpServers": [{"serverName": "puppeteer"}, {"serverCommand": ["npx", "other"]}]}`,
+			server:   puppeteer,
+			wantErr:  true,
+		},
+		{
+			name:     "command entries don't apply to http servers",
+			settings: `{"allowedMcpServers": [{"serverName": "github"}, {"serverCommand": ["npx", "other"]}]}`,
+			server:   github,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.settings != "" {
+				writeFile(t, filepath.Join(dir, ManagedSettingsFileName), tt.settings)
+			}
+
+			p, err := LoadManagedPolicy(dir)
+			if err != nil {
+				t.Fatalf("LoadManagedPolicy() error = %v", err)
+			}
+			err = p.Check(&tt.server)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrServerNotAllowed) {
+				t.Errorf("Check() error = %v, want ErrServerNotAllowed", err)
+			}
+		})
+	}
+}
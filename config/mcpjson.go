@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// MCPJSONFileName is the name of the file in which a project shares its
+// servers (added with 'claude mcp add --scope project').
+const MCPJSONFileName = ".mcp.json"
+
+// LoadMCPJSON reads the .mcp.json file of a project and returns its servers,
+// ordered by name, with their status resolved from the settings files.
+// A project without .mcp.json has no servers.
+func LoadMCPJSON(projectPath, userSettingsPath string) ([]types.MCPServer, error) {
+	entries, err := readServerFile(filepath.Join(projectPath, MCPJSONFileName))
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+
+	settings, err := LoadProjectSettings(userSettingsPath, projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	servers := parseServerFile(entries, types.ScopeMCPJSON, projectPath)
+	for i := range servers {
+		servers[i].Status, servers[i].StatusReason = settings.Status(servers[i].Name)
+	}
+	return servers, nil
+}
+
+// readServerFile reads the mcpServers entries of a file in the .mcp.json
+// format. A missing file has no entries.
+func readServerFile(path string) (map[string]rawServerConfig, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var raw struct {
+		MCPServers map[string]rawServerConfig `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return raw.MCPServers, nil
+}
+
+// parseServerFile converts the entries read by readServerFile, ordered by name.
+func parseServerFile(entries map[string]rawServerConfig, scope types.Scope, projectPath string) []types.MCPServer {
+	servers := make([]types.MCPServer, 0, len(entries))
+	for name, entry := range entries {
+		servers = append(servers, parseServer(name, &entry, scope, projectPath))
+	}
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].Name < servers[j].Name
+	})
+	return servers
+}
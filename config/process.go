@@ -0,0 +1,37 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Process is a running process that may write the config.
+type Process struct {
+	PID  int
+	Name string
+	// HoldsConfig is true when the process has the config file open.
+	HoldsConfig bool
+}
+
+// ClaudeProcesses returns the running Claude Code processes, and any other
+// process that has the config file open. Detection is best effort: it is
+// only supported on Linux and macOS, and errors yield no processes.
+func ClaudeProcesses(configPath string) []Process {
+	return claudeProcesses(configPath)
+}
+
+// isClaudeCommand reports whether a command line runs Claude Code, either
+// the native binary or the npm package through a JavaScript runtime.
+func isClaudeCommand(argv []string) bool {
+	if len(argv) == 0 {
+		return false
+	}
+	if filepath.Base(argv[0]) == "claude" {
+		return true
+	}
+	switch filepath.Base(argv[0]) {
+	case "node", "bun":
+		return len(argv) > 1 && (filepath.Base(argv[1]) == "claude" || strings.Contains(argv[1], "@anthropic-ai/claude-code"))
+	}
+	return false
+}
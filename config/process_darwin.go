@@ -0,0 +1,33 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// claudeProcesses lists Claude Code processes with ps. Open files are not
+// inspected, as lsof is too slow to run before every write.
+func claudeProcesses(_ string) []Process {
+	out, err := exec.Command("ps", "-axo", "pid=,args=").Output()
+	if err != nil {
+		return nil
+	}
+
+	self := os.Getpid()
+	var processes []Process
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil || pid == self || !isClaudeCommand(fields[1:]) {
+			continue
+		}
+		processes = append(processes, Process{PID: pid, Name: filepath.Base(fields[1])})
+	}
+	return processes
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// claudeProcesses scans /proc for Claude Code processes and for processes
+// with an open file descriptor on the config.
+func claudeProcesses(configPath string) []Process {
+	target, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	self := os.Getpid()
+	var processes []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		dir := filepath.Join("/proc", entry.Name())
+
+		cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+		if err != nil {
+			continue // the process exited or belongs to another user
+		}
+		argv := strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+		holds := holdsFile(dir, target)
+		if !holds && !isClaudeCommand(argv) {
+			continue
+		}
+
+		name := filepath.Base(argv[0])
+		if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+			name = strings.TrimSpace(string(comm))
+		}
+		processes = append(processes, Process{PID: pid, Name: name, HoldsConfig: holds})
+	}
+	return processes
+}
+
+// holdsFile reports whether the process in procDir has path open.
+func holdsFile(procDir, path string) bool {
+	fds, err := os.ReadDir(filepath.Join(procDir, "fd"))
+	if err != nil {
+		return false
+	}
+	for _, fd := range fds {
+		if link, err := os.Readlink(filepath.Join(procDir, "fd", fd.Name())); err == nil && link == path {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package config
+
+// claudeProcesses is not supported on this platform.
+func claudeProcesses(_ string) []Process {
+	return nil
+}
@@ -0,0 +1,23 @@
+package config
+
+import "testing"
+
+func TestIsClaudeCommand(t *testing.T) {
+	tests := []struct {
+		argv []string
+		want bool
+	}{
+		{argv: []string{"/usr/local/bin/claude"}, want: true},
+		{argv: []string{"claude", "--resume"}, want: true},
+		{argv: []string{"node", "/usr/lib/node_modules/@anthropic-ai/claude-code/cli.js"}, want: true},
+		{argv: []string{"node", "/home/me/.npm-global/bin/claude"}, want: true},
+		{argv: []string{"vim", "claude"}, want: false},
+		{argv: []string{"/usr/bin/claude-desktop"}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isClaudeCommand(tt.argv); got != tt.want {
+			t.Errorf("isClaudeCommand(%q) = %v, want %v", tt.argv, got, tt.want)
+		}
+	}
+}
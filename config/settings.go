@@ -0,0 +1,440 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// SettingsFile identifies one of the settings files in which Claude Code
+// approves or blocks .mcp.json servers, in order of increasing precedence.
+type SettingsFile int
+
+const (
+	// SettingsUser is ~/.claude/settings.json, which applies to every project.
+	SettingsUser SettingsFile = iota
+	// SettingsProject is .claude/settings.json, shared with the project.
+	SettingsProject
+	// SettingsLocal is .claude/settings.local.json, personal and not committed.
+	SettingsLocal
+)
+
+// String returns the string representation of the settings file.
+func (f SettingsFile) String() string {
+	switch f {
+	case SettingsUser:
+		return "user"
+	case SettingsProject:
+		return "project"
+	case SettingsLocal:
+		return "local"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSettingsFile parses "user", "project" or "local".
+func ParseSettingsFile(s string) (SettingsFile, error) {
+	for _, f := range []SettingsFile{SettingsUser, SettingsProject, SettingsLocal} {
+		if s == f.String() {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid settings file %q (expected user, project or local)", s)
+}
+
+// UserSettingsPath returns the path of the user settings file:
+// $CLAUDE_CONFIG_DIR/settings.json or ~/.claude/settings.json.
+func UserSettingsPath() string {
+	if dir := os.Getenv(ClaudeConfigDirEnv); dir != "" {
+		return filepath.Join(dir, "settings.json")
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".claude", "settings.json")
+}
+
+// SettingsPath returns the path of a settings file. userSettingsPath is
+// used for SettingsUser, projectPath for the others.
+func SettingsPath(file SettingsFile, userSettingsPath, projectPath string) string {
+	switch file {
+	case SettingsProject:
+		return filepath.Join(projectPath, ".claude", "settings.json")
+	case SettingsLocal:
+		return filepath.Join(projectPath, ".claude", "settings.local.json")
+	default:
+		return userSettingsPath
+	}
+}
+
+// mcpjsonSettings holds the settings keys that control .mcp.json servers.
+type mcpjsonSettings struct {
+	EnabledMcpjsonServers      []string `json:"enabledMcpjsonServers"`
+	DisabledMcpjsonServers     []string `json:"disabledMcpjsonServers"`
+	EnableAllProjectMcpServers *bool    `json:"enableAllProjectMcpServers"`
+}
+
+// settingsLayer is one settings file that was read.
+type settingsLayer struct {
+	path     string
+	settings mcpjsonSettings
+}
+
+// ProjectSettings is the combined .mcp.json approval state of the settings
+// files that apply to a project.
+type ProjectSettings struct {
+	layers []settingsLayer // in order of increasing precedence
+}
+
+// LoadProjectSettings reads the user, project and local settings files of a
+// project. Missing files are skipped.
+func LoadProjectSettings(userSettingsPath, projectPath string) (*ProjectSettings, error) {
+	s := &ProjectSettings{}
+	for _, file := range []SettingsFile{SettingsUser, SettingsProject, SettingsLocal} {
+		path := SettingsPath(file, userSettingsPath, projectPath)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read settings: %w", err)
+		}
+		layer := settingsLayer{path: path}
+		if err := json.Unmarshal(data, &layer.settings); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		s.layers = append(s.layers, layer)
+	}
+	return s, nil
+}
+
+// Status resolves whether Claude Code starts a .mcp.json server, the way
+// Claude Code merges the settings files: the server lists of all files are
+// combined and a disabled entry wins over an enabled one, while
+// enableAllProjectMcpServers is taken from the most specific file that sets
+// it. The reason names the deciding setting and file.
+func (s *ProjectSettings) Status(name string) (types.Status, string) {
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		if slices.Contains(s.layers[i].settings.DisabledMcpjsonServers, name) {
+			return types.StatusDisabled, "disabledMcpjsonServers in " + s.layers[i].path
+		}
+	}
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		if slices.Contains(s.layers[i].settings.EnabledMcpjsonServers, name) {
+			return types.StatusActive, "enabledMcpjsonServers in " + s.layers[i].path
+		}
+	}
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		if enableAll := s.layers[i].settings.EnableAllProjectMcpServers; enableAll != nil {
+			if *enableAll {
+				return types.StatusActive, "enableAllProjectMcpServers in " + s.layers[i].path
+			}
+			break
+		}
+	}
+	return types.StatusPending, "not approved yet"
+}
+
+// SetMCPJSONServerStatus disables or enables .mcp.json servers in a settings
+// file: the names are added to disabledMcpjsonServers and removed from
+// enabledMcpjsonServers, or the other way round. Other settings are kept.
+// The file is created if needed, and backed up if it exists. The result
+// lists the settings that changed; if there are none, nothing is written.
+func SetMCPJSONServerStatus(settingsPath string, names []string, enable bool) (*WriteResult, error) {
+	addTo, removeFrom := "disabledMcpjsonServers", "enabledMcpjsonServers"
+	if enable {
+		addTo, removeFrom = removeFrom, addTo
+	}
+
+	return updateSettings(settingsPath, func(raw map[string]interface{}) {
+		changed := false
+		add := stringList(raw[addTo])
+		remove := stringList(raw[removeFrom])
+		for _, name := range names {
+			if !slices.Contains(add, name) {
+				add = append(add, name)
+				changed = true
+			}
+			if i := slices.Index(remove, name); i >= 0 {
+				remove = slices.Delete(remove, i, i+1)
+				changed = true
+			}
+		}
+		if !changed {
+			return
+		}
+
+		raw[addTo] = add
+		if len(remove) > 0 {
+			raw[removeFrom] = remove
+		} else {
+			delete(raw, removeFrom)
+		}
+	})
+}
+
+// AddCommandHook registers a command hook for hook events in a settings
+// file, for the tools matching matcher:
+//
+//	"hooks": {"PostToolUse": [{"matcher": "mcp__.*", "hooks": [{"type": "command", "command": "..."}]}]}
+//
+// Other settings and hooks are kept, and events the command is already
+// registered for are left alone. The file is created if needed, and backed
+// up if it exists. The result lists the settings that changed; if there are
+// none, nothing is written.
+func AddCommandHook(settingsPath string, events []string, matcher, command string) (*WriteResult, error) {
+	var editErr error
+	result, err := updateSettings(settingsPath, func(raw map[string]interface{}) {
+		editErr = nil // the edit is redone if the file changes while writing
+		hooks, ok := raw["hooks"].(map[string]interface{})
+		if !ok {
+			if raw["hooks"] != nil {
+				editErr = fmt.Errorf("failed to parse %s: hooks is not an object", settingsPath)
+				return
+			}
+			hooks = make(map[string]interface{})
+		}
+		for _, event := range events {
+			if _, ok := hooks[event].([]interface{}); !ok && hooks[event] != nil {
+				editErr = fmt.Errorf("failed to parse %s: hooks.%s is not an array", settingsPath, event)
+				return
+			}
+		}
+
+		changed := false
+		for _, event := range events {
+			groups, _ := hooks[event].([]interface{})
+			if slices.Contains(hookCommands(groups), command) {
+				continue
+			}
+			hooks[event] = append(groups, map[string]interface{}{
+				"matcher": matcher,
+				"hooks":   []interface{}{map[string]interface{}{"type": "command", "command": command}},
+			})
+			changed = true
+		}
+		if changed {
+			raw["hooks"] = hooks
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if editErr != nil {
+		return nil, editErr
+	}
+	return result, nil
+}
+
+// hookCommands returns the commands of the hook groups of an event.
+func hookCommands(groups []interface{}) []string {
+	var commands []string
+	for _, group := range groups {
+		g, _ := group.(map[string]interface{})
+		hooks, _ := g["hooks"].([]interface{})
+		for _, hook := range hooks {
+			h, _ := hook.(map[string]interface{})
+			if command, ok := h["command"].(string); ok {
+				commands = append(commands, command)
+			}
+		}
+	}
+	return commands
+}
+
+// settingsDocument is a Claude Code settings file, whose changes are its
+// top-level settings.
+var settingsDocument = document{name: "settings", decode: decodeSettings, encode: encodeSettings, snapshot: snapshotSettings}
+
+// updateSettings applies edit to a parsed settings file and writes it back
+// with the locking, backup and change tracking of updateFile. The file and
+// its directory are created if needed.
+func updateSettings(settingsPath string, edit func(raw map[string]interface{})) (*WriteResult, error) {
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create settings directory: %w", err)
+	}
+	return updateFile(settingsPath, settingsDocument, true, true, edit)
+}
+
+// decodeSettings parses a settings file, keeping numbers as written.
+func decodeSettings(_ string, content []byte) (map[string]interface{}, error) {
+	raw := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// encodeSettings returns the content of a settings file after its parsed
+// form was edited. People edit their settings by hand, so the keys keep the
+// order they have in content, new keys follow in sorted order, and
+// characters such as & and < are not escaped.
+func encodeSettings(_ string, content []byte, raw map[string]interface{}) ([]byte, error) {
+	e := &settingsEncoder{order: keyOrder(content)}
+	if err := e.write(raw, "", ""); err != nil {
+		return nil, err
+	}
+	e.buf.WriteByte('\n')
+	return e.buf.Bytes(), nil
+}
+
+// keyOrder returns the keys of every object in a JSON document in the order
+// they are written, by the path of the object: its keys and array indexes
+// joined with NUL. A document that doesn't parse yields what was read.
+func keyOrder(content []byte) map[string][]string {
+	order := make(map[string][]string)
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	var walk func(path string) error
+	walk = func(path string) error {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'):
+			for decoder.More() {
+				token, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := token.(string)
+				order[path] = append(order[path], key)
+				if err := walk(path + "\x00" + key); err != nil {
+					return err
+				}
+			}
+		case json.Delim('['):
+			for i := 0; decoder.More(); i++ {
+				if err := walk(path + "\x00" + strconv.Itoa(i)); err != nil {
+					return err
+				}
+			}
+		default:
+			return nil
+		}
+		_, err = decoder.Token() // the closing delimiter
+		return err
+	}
+	_ = walk("")
+	return order
+}
+
+// settingsEncoder writes parsed JSON indented by two spaces, with the keys
+// of each object in the order of the original document.
+type settingsEncoder struct {
+	buf   bytes.Buffer
+	order map[string][]string
+}
+
+// write writes a value at path, the keys and indices leading to it joined
+// by NUL, indented by indent.
+func (e *settingsEncoder) write(value interface{}, path, indent string) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return e.writeObject(v, path, indent)
+	case []interface{}:
+		return e.writeArray(v, path, indent)
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return e.writeArray(items, path, indent)
+	default:
+		return e.scalar(v)
+	}
+}
+
+// writeObject writes an object, its keys in the original order followed by
+// the added keys sorted.
+func (e *settingsEncoder) writeObject(v map[string]interface{}, path, indent string) error {
+	if len(v) == 0 {
+		e.buf.WriteString("{}")
+		return nil
+	}
+	keys := make([]string, 0, len(v))
+	for _, key := range e.order[path] {
+		if _, ok := v[key]; ok && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	var added []string
+	for key := range v {
+		if !slices.Contains(keys, key) {
+			added = append(added, key)
+		}
+	}
+	slices.Sort(added)
+	keys = append(keys, added...)
+
+	e.buf.WriteString("{\n")
+	for i, key := range keys {
+		e.buf.WriteString(indent + "  ")
+		if err := e.scalar(key); err != nil {
+			return err
+		}
+		e.buf.WriteString(": ")
+		if err := e.write(v[key], path+"\x00"+key, indent+"  "); err != nil {
+			return err
+		}
+		if i < len(keys)-1 {
+			e.buf.WriteByte(',')
+		}
+		e.buf.WriteByte('\n')
+	}
+	e.buf.WriteString(indent + "}")
+	return nil
+}
+
+// writeArray writes an array, one item per line.
+func (e *settingsEncoder) writeArray(v []interface{}, path, indent string) error {
+	if len(v) == 0 {
+		e.buf.WriteString("[]")
+		return nil
+	}
+	e.buf.WriteString("[\n")
+	for i, item := range v {
+		e.buf.WriteString(indent + "  ")
+		if err := e.write(item, path+"\x00"+strconv.Itoa(i), indent+"  "); err != nil {
+			return err
+		}
+		if i < len(v)-1 {
+			e.buf.WriteByte(',')
+		}
+		e.buf.WriteByte('\n')
+	}
+	e.buf.WriteString(indent + "]")
+	return nil
+}
+
+// scalar writes a value without HTML escaping.
+func (e *settingsEncoder) scalar(value interface{}) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	e.buf.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return nil
+}
+
+// stringList returns the strings of a parsed JSON array.
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,463 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestProjectSettings_Status(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       string
+		project    string
+		local      string
+		want       types.Status
+		wantReason string
+	}{
+		{
+			name:       "no settings",
+			want:       types.StatusPending,
+			wantReason: "not approved yet",
+		},
+		{
+			name:       "enabled in local",
+			local:      `{"enabledMcpjsonServers": ["github"]}`,
+			want:       types.StatusActive,
+			wantReason: "enabledMcpjsonServers in <local>",
+		},
+		{
+			name:       "disabled in user wins over enabled in local",
+			user:       `{"disabledMcpjsonServers": ["github"]}`,
+			local:      `{"enabledMcpjsonServers": ["github"]}`,
+			want:       types.StatusDisabled,
+			wantReason: "disabledMcpjsonServers in <user>",
+		},
+		{
+			name:       "enable all in project",
+			project:    `{"enableAllProjectMcpServers": true}`,
+			want:       types.StatusActive,
+			wantReason: "enableAllProjectMcpServers in <project>",
+		},
+		{
+			name:       "enable all turned off in local",
+			project:    `{"enableAllProjectMcpServers": true}`,
+			local:      `{"enableAllProjectMcpServers": false}`,
+			want:       types.StatusPending,
+			wantReason: "not approved yet",
+		},
+		{
+			name:       "list entry wins over enable all",
+			project:    `{"enableAllProjectMcpServers": true, "disabledMcpjsonServers": ["github"]}`,
+			want:       types.StatusDisabled,
+			wantReason: "disabledMcpjsonServers in <project>",
+		},
+		{
+			name:       "other servers don't count",
+			local:      `{"enabledMcpjsonServers": ["slack"], "disabledMcpjsonServers": ["jira"]}`,
+			want:       types.StatusPending,
+			wantReason: "not approved yet",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			userPath := filepath.Join(dir, "home", "settings.json")
+			projectPath := filepath.Join(dir, "project")
+			paths := map[string]string{
+				"<user>":    userPath,
+				"<project>": SettingsPath(SettingsProject, userPath, projectPath),
+				"<local>":   SettingsPath(SettingsLocal, userPath, projectPath),
+			}
+			for key, content := range map[string]string{"<user>": tt.user, "<project>": tt.project, "<local>": tt.local} {
+				if content == "" {
+					continue
+				}
+				writeFile(t, paths[key], content)
+			}
+
+			settings, err := LoadProjectSettings(userPath, projectPath)
+			if err != nil {
+				t.Fatalf("LoadProjectSettings() error = %v", err)
+			}
+			got, reason := settings.Status("github")
+			if got != tt.want {
+				t.Errorf("Status() = %v, want %v", got, tt.want)
+			}
+			wantReason := tt.wantReason
+			for key, path := range paths {
+				wantReason = strings.ReplaceAll(wantReason, key, path)
+			}
+			if reason != wantReason {
+				t.Errorf("Status() reason = %q, want %q", reason, wantReason)
+			}
+		})
+	}
+}
+
+func TestLoadProjectSettings_InvalidJSON(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, SettingsPath(SettingsLocal, "", dir), `{not json`)
+
+	if _, err := LoadProjectSettings(filepath.Join(dir, "missing.json"), dir); err == nil {
+		t.Error("LoadProjectSettings() error = nil, want parse error")
+	}
+}
+
+func TestSetMCPJSONServerStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    string
+		names       []string
+		enable      bool
+		wantChanged bool
+		want        map[string]interface{}
+	}{
+		{
+			name:        "creates the file",
+			names:       []string{"github"},
+			wantChanged: true,
+			want:        map[string]interface{}{"disabledMcpjsonServers": []interface{}{"github"}},
+		},
+		{
+			name:        "keeps other settings",
+			existing:    `{"permissions": {"allow": ["Bash(ls)"]}, "disabledMcpjsonServers": ["slack"]}`,
+			names:       []string{"github"},
+			wantChanged: true,
+			want: map[string]interface{}{
+				"permissions":            map[string]interface{}{"allow": []interface{}{"Bash(ls)"}},
+				"disabledMcpjsonServers": []interface{}{"slack", "github"},
+			},
+		},
+		{
+			name:        "moves from enabled to disabled",
+			existing:    `{"enabledMcpjsonServers": ["github"]}`,
+			names:       []string{"github"},
+			wantChanged: true,
+			want:        map[string]interface{}{"disabledMcpjsonServers": []interface{}{"github"}},
+		},
+		{
+			name:        "enable keeps the rest of the disabled list",
+			existing:    `{"disabledMcpjsonServers": ["github", "slack"]}`,
+			names:       []string{"github"},
+			enable:      true,
+			wantChanged: true,
+			want: map[string]interface{}{
+				"enabledMcpjsonServers":  []interface{}{"github"},
+				"disabledMcpjsonServers": []interface{}{"slack"},
+			},
+		},
+		{
+			name:     "already disabled",
+			existing: `{"disabledMcpjsonServers": ["github"]}`,
+			names:    []string{"github"},
+			want:     map[string]interface{}{"disabledMcpjsonServers": []interface{}{"github"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".claude", "settings.local.json")
+			if tt.existing != "" {
+				writeFile(t, path, tt.existing)
+			}
+
+			result, err := SetMCPJSONServerStatus(path, tt.names, tt.enable)
+			if err != nil {
+				t.Fatalf("SetMCPJSONServerStatus() error = %v", err)
+			}
+			if changed := len(result.Changes) > 0; changed != tt.wantChanged {
+				t.Errorf("SetMCPJSONServerStatus() changed = %v, want %v", changed, tt.wantChanged)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read settings: %v", err)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("failed to parse settings: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("settings mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetMCPJSONServerStatus_Undo(t *testing.T) {
+	// Written the way Claude Code writes it: keys in their own order, a hook
+	// command with && and a number that must not turn into a float
+	original := `{
+  "permissions": {
+    "allow": [
+      "Bash(ls)"
+    ]
+  },
+  "enabledMcpjsonServers": [
+    "github",
+    "slack"
+  ],
+  "hooks": {
+    "Stop": [
+      {
+        "hooks": [
+          {
+            "type": "command",
+            "command": "make lint && make test",
+            "timeout": 600
+          }
+        ]
+      }
+    ]
+  }
+}
+`
+	path := filepath.Join(t.TempDir(), ".claude", "settings.local.json")
+	writeFile(t, path, original)
+
+	result, err := SetMCPJSONServerStatus(path, []string{"github"}, false)
+	if err != nil {
+		t.Fatalf("SetMCPJSONServerStatus() error = %v", err)
+	}
+	if result.Backup == "" {
+		t.Error("SetMCPJSONServerStatus() created no backup")
+	}
+	gotChanges := make([]string, 0, len(result.Changes))
+	for _, c := range result.Changes {
+		gotChanges = append(gotChanges, c.Scope+" "+c.Name+" "+c.Kind())
+	}
+	wantChanges := []string{"setting disabledMcpjsonServers added", "setting enabledMcpjsonServers changed"}
+	if diff := cmp.Diff(wantChanges, gotChanges, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Errorf("SetMCPJSONServerStatus() changes mismatch (-want +got):\n%s", diff)
+	}
+
+	want := strings.Replace(original, `  "enabledMcpjsonServers": [
+    "github",
+    "slack"
+  ],`, `  "enabledMcpjsonServers": [
+    "slack"
+  ],`, 1)
+	want = strings.Replace(want, "    ]\n  }\n}\n", "    ]\n  },\n  \"disabledMcpjsonServers\": [\n    \"github\"\n  ]\n}\n", 1)
+	if diff := cmp.Diff(want, fileContent(t, path)); diff != "" {
+		t.Errorf("settings mismatch (-want +got):\n%s", diff)
+	}
+
+	// Undo restores both lists, and the file as it was
+	if _, conflicts, err := Revert(path, result.Changes); err != nil || len(conflicts) > 0 {
+		t.Fatalf("Revert() conflicts = %v, error = %v", conflicts, err)
+	}
+	if diff := cmp.Diff(original, fileContent(t, path)); diff != "" {
+		t.Errorf("settings after Revert() mismatch (-want +got):\n%s", diff)
+	}
+
+	// A list changed since is left alone
+	if _, err := SetMCPJSONServerStatus(path, []string{"github"}, false); err != nil {
+		t.Fatalf("SetMCPJSONServerStatus() error = %v", err)
+	}
+	if _, err := SetMCPJSONServerStatus(path, []string{"slack"}, false); err != nil {
+		t.Fatalf("SetMCPJSONServerStatus() error = %v", err)
+	}
+	if _, conflicts, err := Revert(path, result.Changes); err != nil || len(conflicts) != 2 {
+		t.Errorf("Revert() of changed lists conflicts = %v, error = %v, want 2 conflicts", conflicts, err)
+	}
+}
+
+func TestAddCommandHook(t *testing.T) {
+	hook := map[string]interface{}{
+		"matcher": "mcp__.*",
+		"hooks":   []interface{}{map[string]interface{}{"type": "command", "command": "mcp-tidy hook"}},
+	}
+	other := map[string]interface{}{
+		"matcher": "Bash",
+		"hooks":   []interface{}{map[string]interface{}{"type": "command", "command": "echo hi"}},
+	}
+
+	tests := []struct {
+		name        string
+		existing    string
+		wantChanged bool
+		want        map[string]interface{}
+		wantErr     bool
+	}{
+		{
+			name:        "creates the file",
+			wantChanged: true,
+			want: map[string]interface{}{"hooks": map[string]interface{}{
+				"PostToolUse":        []interface{}{hook},
+				"PostToolUseFailure": []interface{}{hook},
+			}},
+		},
+		{
+			name:        "keeps other settings and hooks",
+			existing:    `{"permissions": {"allow": ["Bash(ls)"]}, "hooks": {"PostToolUse": [{"matcher": "Bash", "hooks": [{"type": "command", "command": "echo hi"}]}]}}`,
+			wantChanged: true,
+			want: map[string]interface{}{
+				"permissions": map[string]interface{}{"allow": []interface{}{"Bash(ls)"}},
+				"hooks": map[string]interface{}{
+					"PostToolUse":        []interface{}{other, hook},
+					"PostToolUseFailure": []interface{}{hook},
+				},
+			},
+		},
+		{
+			name:        "registered for one event",
+			existing:    `{"hooks": {"PostToolUse": [{"matcher": "mcp__.*", "hooks": [{"type": "command", "command": "mcp-tidy hook"}]}]}}`,
+			wantChanged: true,
+			want: map[string]interface{}{"hooks": map[string]interface{}{
+				"PostToolUse":        []interface{}{hook},
+				"PostToolUseFailure": []interface{}{hook},
+			}},
+		},
+		{
+			name:     "already registered",
+			existing: `{"hooks": {"PostToolUse": [{"matcher": "mcp__.*", "hooks": [{"type": "command", "command": "mcp-tidy hook"}]}], "PostToolUseFailure": [{"matcher": "mcp__.*", "hooks": [{"type": "command", "command": "mcp-tidy hook"}]}]}}`,
+			want: map[string]interface{}{"hooks": map[string]interface{}{
+				"PostToolUse":        []interface{}{hook},
+				"PostToolUseFailure": []interface{}{hook},
+			}},
+		},
+		{
+			name:     "unexpected event hooks",
+			existing: `{"hooks": {"PostToolUseFailure": "mcp-tidy hook"}}`,
+			wantErr:  true,
+		},
+		{
+			name:     "unexpected hooks",
+			existing: `{"hooks": ["mcp-tidy hook"]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid JSON",
+			existing: `{"hooks": `,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".claude", "settings.json")
+			if tt.existing != "" {
+				writeFile(t, path, tt.existing)
+			}
+
+			result, err := AddCommandHook(path, []string{"PostToolUse", "PostToolUseFailure"}, "mcp__.*", "mcp-tidy hook")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AddCommandHook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if changed := result != nil && len(result.Changes) > 0; changed != tt.wantChanged {
+				t.Errorf("AddCommandHook() changed = %v, want %v", changed, tt.wantChanged)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read settings: %v", err)
+			}
+			if tt.wantErr {
+				if string(data) != tt.existing {
+					t.Errorf("AddCommandHook() changed the settings on error: %s", data)
+				}
+				return
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("failed to parse settings: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("settings mismatch (-want +got):\n%s", diff)
+			}
+			if tt.wantChanged && tt.existing != "" && result.Backup == "" {
+				t.Error("AddCommandHook() created no backup")
+			}
+		})
+	}
+}
+
+func TestLoadMCPJSON(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "settings.json")
+	writeFile(t, filepath.Join(dir, MCPJSONFileName), `{"mcpServers": {
+		"slack": {"command": "npx", "args": ["-y", "slack-mcp"]},
+		"github": {"type": "http", "url": "https://api.github.com/mcp"}
+	}}`)
+	writeFile(t, SettingsPath(SettingsLocal, userPath, dir), `{"disabledMcpjsonServers": ["slack"]}`)
+
+	servers, err := LoadMCPJSON(dir, userPath)
+	if err != nil {
+		t.Fatalf("LoadMCPJSON() error = %v", err)
+	}
+
+	want := []types.MCPServer{
+		{
+			Name:         "github",
+			Type:         types.ServerTypeHTTP,
+			TypeStr:      "http",
+			URL:          "https://api.github.com/mcp",
+			Scope:        types.ScopeMCPJSON,
+			ProjectPath:  dir,
+			Status:       types.StatusPending,
+			StatusReason: "not approved yet",
+		},
+		{
+			Name:         "slack",
+			Type:         types.ServerTypeStdio,
+			Command:      "npx",
+			Args:         []string{"-y", "slack-mcp"},
+			Scope:        types.ScopeMCPJSON,
+			ProjectPath:  dir,
+			Status:       types.StatusDisabled,
+			StatusReason: "disabledMcpjsonServers in " + SettingsPath(SettingsLocal, userPath, dir),
+		},
+	}
+	if diff := cmp.Diff(want, servers, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("LoadMCPJSON() mismatch (-want +got):\n%s", diff)
+	}
+
+	servers, err = LoadMCPJSON(filepath.Join(dir, "missing"), userPath)
+	if err != nil || servers != nil {
+		t.Errorf("LoadMCPJSON() without .mcp.json = %v, %v; want nil, nil", servers, err)
+	}
+}
+
+func TestRemoveServers_ReadOnly(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "claude.json")
+	writeFile(t, configPath, `{"mcpServers": {}}`)
+
+	for _, server := range []types.MCPServer{
+		{Name: "github", Scope: types.ScopeMCPJSON, ProjectPath: "/p"},
+		{Name: "corp-search", Scope: types.ScopeManaged},
+	} {
+		if _, err := RemoveServers(configPath, []types.MCPServer{server}); !errors.Is(err, ErrReadOnlyServer) {
+			t.Errorf("RemoveServers(%s) error = %v, want ErrReadOnlyServer", server.Scope, err)
+		}
+	}
+}
+
+// writeFile writes a test file, creating its directory.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+// fileContent returns the content of a test file.
+func fileContent(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// ErrUnsupportedServer is returned when a client can't run a server that is
+// synced to it.
+var ErrUnsupportedServer = errors.New("not supported by the target client")
+
+// placeholderPattern matches ${...} references in server entries.
+var placeholderPattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// envNamePattern matches a variable name, optionally with a :-default as
+// Claude Code allows.
+var envNamePattern = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)(:-.*)?$`)
+
+// nonNameChars matches the characters an input id can't keep in a variable name.
+var nonNameChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Translate converts a server to the schema of another client, as a global
+// server of that client. The transport is written the way the client expects
+// it: Claude Code and Claude Desktop need "type" for remote servers, VS Code
+// always, and Cursor, Codex and Gemini CLI infer it from the keys that are
+// set. ${...} references are rewritten too: Claude Code's ${NAME} is
+// ${env:NAME} in Cursor and VS Code, and VS Code's ${input:id} prompts
+// become environment variables.
+//
+// The returned notes describe what couldn't be carried over exactly. An
+// error wrapping ErrUnsupportedServer is returned when the client can't run
+// the server at all.
+func Translate(server *types.MCPServer, to Client) (types.MCPServer, []string, error) {
+	if server.Type == types.ServerTypeUnknown {
+		return types.MCPServer{}, nil, fmt.Errorf("%w (type %q is unknown to mcp-tidy)", ErrUnsupportedServer, server.TypeName())
+	}
+	if to.Name() == ClientClaudeDesktop && server.Type.IsRemote() {
+		// Claude Desktop connects to remote servers through connectors only
+		return types.MCPServer{}, nil, fmt.Errorf("%w (Claude Desktop only runs stdio servers from its config file)", ErrUnsupportedServer)
+	}
+	if to.Name() == ClientCodex && server.Type == types.ServerTypeSSE {
+		return types.MCPServer{}, nil, fmt.Errorf("%w (Codex doesn't support the sse transport)", ErrUnsupportedServer)
+	}
+
+	from, err := ParseClient(server.Client)
+	if err != nil {
+		return types.MCPServer{}, nil, err
+	}
+	t := &translator{from: from, to: to}
+
+	out := types.MCPServer{
+		Name:    server.Name,
+		Type:    server.Type,
+		Command: t.translate(server.Command),
+		URL:     t.translate(server.URL),
+		Scope:   types.ScopeGlobal,
+	}
+	if to.Name() != ClientClaudeCode {
+		out.Client = to.Name()
+	}
+	for _, arg := range server.Args {
+		out.Args = append(out.Args, t.translate(arg))
+	}
+	out.Env = t.translateMap(server.Env)
+	out.Headers = t.translateMap(server.Headers)
+
+	switch to.Name() {
+	case ClientCursor, ClientCodex, ClientGemini:
+		// These clients tell the transport from the keys that are set
+	case ClientVSCode:
+		out.TypeStr = server.Type.String()
+	default:
+		if server.Type.IsRemote() || server.TypeStr != "" {
+			out.TypeStr = server.Type.String()
+		}
+	}
+	return out, t.notes, nil
+}
+
+// translator rewrites the ${...} references of one server.
+type translator struct {
+	from, to Client
+	notes    []string
+}
+
+// note records a note once.
+func (t *translator) note(format string, args ...interface{}) {
+	if note := fmt.Sprintf(format, args...); !slices.Contains(t.notes, note) {
+		t.notes = append(t.notes, note)
+	}
+}
+
+// translateMap translates the values of an env or headers map.
+func (t *translator) translateMap(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	result := make(map[string]string, len(values))
+	for key, value := range values {
+		result[key] = t.translate(value)
+	}
+	return result
+}
+
+// translate rewrites the references in s for the target client.
+func (t *translator) translate(s string) string {
+	return placeholderPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		inner := ref[2 : len(ref)-1]
+		if strings.HasPrefix(inner, "input:") && t.to.Name() == ClientVSCode {
+			return ref
+		}
+		name, def, ok := t.envReference(inner)
+		if !ok {
+			// A client's own variable, such as Cursor's ${workspaceFolder}
+			if t.from.Name() != t.to.Name() {
+				t.note("%s is left as it is; %s may not expand it", ref, t.to.Title())
+			}
+			return ref
+		}
+
+		switch t.to.Name() {
+		case ClientCursor, ClientVSCode:
+			if def != "" {
+				t.note("the default of %s is dropped; %s doesn't support defaults", ref, t.to.Title())
+			}
+			return "${env:" + name + "}"
+		case ClientClaudeDesktop, ClientCodex:
+			t.note("%s doesn't expand ${%s}; replace it with the value", t.to.Title(), name)
+			return "${" + name + "}"
+		case ClientGemini:
+			if def != "" {
+				t.note("the default of %s is dropped; %s doesn't support defaults", ref, t.to.Title())
+			}
+			return "${" + name + "}"
+		default:
+			return "${" + name + def + "}"
+		}
+	})
+}
+
+// envReference returns the environment variable a reference reads, with
+// its :-default, if any. VS Code's ${input:id} prompts have no equivalent in
+// other clients, so they become a variable named after the input.
+func (t *translator) envReference(inner string) (name, def string, ok bool) {
+	switch {
+	case strings.HasPrefix(inner, "env:"):
+		return strings.TrimPrefix(inner, "env:"), "", true
+	case strings.HasPrefix(inner, "input:"):
+		id := strings.TrimPrefix(inner, "input:")
+		name = inputEnvName(id)
+		t.note("VS Code input %q is read from $%s instead; set it in the environment", id, name)
+		return name, "", true
+	case t.from.Name() == ClientCursor || t.from.Name() == ClientVSCode:
+		return "", "", false
+	}
+	m := envNamePattern.FindStringSubmatch(inner)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// inputEnvName derives a variable name from a VS Code input id, e.g.
+// GITHUB_TOKEN from github-token.
+func inputEnvName(id string) string {
+	return strings.ToUpper(nonNameChars.ReplaceAllString(id, "_"))
+}
+
+// SyncAction is what syncing does with a server in the target client.
+type SyncAction int
+
+const (
+	// SyncAdd adds a server the target client doesn't have.
+	SyncAdd SyncAction = iota
+	// SyncUpdate replaces a server the target client defines differently.
+	SyncUpdate
+	// SyncUnchanged leaves a server the target client already defines the same way.
+	SyncUnchanged
+	// SyncUnsupported skips a server the target client can't run.
+	SyncUnsupported
+)
+
+// String returns the string representation of the action.
+func (a SyncAction) String() string {
+	switch a {
+	case SyncAdd:
+		return "add"
+	case SyncUpdate:
+		return "update"
+	case SyncUnchanged:
+		return "unchanged"
+	case SyncUnsupported:
+		return "unsupported"
+	default:
+		return "unknown"
+	}
+}
+
+// Writes reports whether the action changes the target's config.
+func (a SyncAction) Writes() bool {
+	return a == SyncAdd || a == SyncUpdate
+}
+
+// SyncedServer is the plan for one server.
+type SyncedServer struct {
+	// Server is the server translated for the target client. For unsupported
+	// servers, only the name is set.
+	Server types.MCPServer
+	Action SyncAction
+	// Changes lists the connection fields an update changes.
+	Changes []string
+	// Notes describe what the translation couldn't carry over exactly.
+	Notes []string
+	// Reason is why an unsupported server is skipped.
+	Reason string
+}
+
+// PlanSync decides for each server what syncing it into the target client
+// does, given the target's global servers. A server the target defines the
+// same way is unchanged; env and header values the source leaves empty
+// don't count, as an update keeps the target's.
+func PlanSync(servers []types.MCPServer, to Client, existing []types.MCPServer) []SyncedServer {
+	byName := make(map[string]*types.MCPServer, len(existing))
+	for i := range existing {
+		byName[existing[i].Name] = &existing[i]
+	}
+
+	plan := make([]SyncedServer, 0, len(servers))
+	for i := range servers {
+		translated, notes, err := Translate(&servers[i], to)
+		if err != nil {
+			plan = append(plan, SyncedServer{Server: types.MCPServer{Name: servers[i].Name}, Action: SyncUnsupported, Reason: err.Error()})
+			continue
+		}
+
+		entry := SyncedServer{Server: translated, Action: SyncAdd, Notes: notes}
+		if current, ok := byName[translated.Name]; ok {
+			entry.Changes = definitionChanges(current, &translated)
+			entry.Action = SyncUpdate
+			if len(entry.Changes) == 0 {
+				entry.Action = SyncUnchanged
+			}
+		}
+		plan = append(plan, entry)
+	}
+	return plan
+}
+
+// SyncUpserts returns the servers of a plan that are written.
+func SyncUpserts(plan []SyncedServer) []types.MCPServer {
+	var upserts []types.MCPServer
+	for i := range plan {
+		if plan[i].Action.Writes() {
+			upserts = append(upserts, plan[i].Server)
+		}
+	}
+	return upserts
+}
+
+// definitionChanges describes how a server changes when replaced by target.
+// Types are compared by transport, so an implicit type equals its explicit
+// spelling; env and headers count only when target sets them.
+func definitionChanges(current, target *types.MCPServer) []string {
+	a, b := *current, *target
+	a.TypeStr, b.TypeStr = a.TypeName(), b.TypeName()
+	changes := a.ConnectionChanges(&b)
+	if len(b.Env) > 0 && !maps.Equal(a.Env, b.Env) {
+		changes = append(changes, "env: values differ")
+	}
+	if len(b.Headers) > 0 && !maps.Equal(a.Headers, b.Headers) {
+		changes = append(changes, "headers: values differ")
+	}
+	return changes
+}
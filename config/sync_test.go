@@ -0,0 +1,182 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestTranslate(t *testing.T) {
+	context7 := types.MCPServer{
+		Name:    "context7",
+		Type:    types.ServerTypeHTTP,
+		TypeStr: "http",
+		URL:     "https://mcp.context7.com/mcp",
+		Headers: map[string]string{"API_KEY": "${CONTEXT7_KEY:-none}"},
+		Scope:   types.ScopeGlobal,
+	}
+	fetch := types.MCPServer{
+		Name:    "fetch",
+		Type:    types.ServerTypeStdio,
+		TypeStr: "stdio",
+		Command: "uvx",
+		Args:    []string{"mcp-server-fetch", "--root", "${workspaceFolder}"},
+		Env:     map[string]string{"TOKEN": "${input:fetch-token}", "HOME_DIR": "${env:HOME}"},
+		Scope:   types.ScopeProject,
+		Client:  ClientVSCode,
+	}
+
+	tests := []struct {
+		name      string
+		server    types.MCPServer
+		to        Client
+		want      types.MCPServer
+		wantNotes []string
+		wantErr   bool
+	}{
+		{
+			name:   "claude code to cursor leaves the transport implicit",
+			server: context7,
+			to:     Cursor{},
+			want: types.MCPServer{
+				Name: "context7", Type: types.ServerTypeHTTP, URL: "https://mcp.context7.com/mcp",
+				Headers: map[string]string{"API_KEY": "${env:CONTEXT7_KEY}"}, Scope: types.ScopeGlobal, Client: ClientCursor,
+			},
+			wantNotes: []string{"the default of ${CONTEXT7_KEY:-none} is dropped; Cursor doesn't support defaults"},
+		},
+		{
+			name:   "claude code to vscode",
+			server: context7,
+			to:     VSCode{},
+			want: types.MCPServer{
+				Name: "context7", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://mcp.context7.com/mcp",
+				Headers: map[string]string{"API_KEY": "${env:CONTEXT7_KEY}"}, Scope: types.ScopeGlobal, Client: ClientVSCode,
+			},
+			wantNotes: []string{"the default of ${CONTEXT7_KEY:-none} is dropped; VS Code doesn't support defaults"},
+		},
+		{
+			name:   "vscode inputs become environment variables",
+			server: fetch,
+			to:     ClaudeCode{},
+			want: types.MCPServer{
+				Name: "fetch", Type: types.ServerTypeStdio, TypeStr: "stdio", Command: "uvx",
+				Args:  []string{"mcp-server-fetch", "--root", "${workspaceFolder}"},
+				Env:   map[string]string{"TOKEN": "${FETCH_TOKEN}", "HOME_DIR": "${HOME}"},
+				Scope: types.ScopeGlobal,
+			},
+			wantNotes: []string{
+				"${workspaceFolder} is left as it is; Claude Code may not expand it",
+				`VS Code input "fetch-token" is read from $FETCH_TOKEN instead; set it in the environment`,
+			},
+		},
+		{
+			name:   "claude code to gemini keeps ${NAME}",
+			server: context7,
+			to:     Gemini{},
+			want: types.MCPServer{
+				Name: "context7", Type: types.ServerTypeHTTP, URL: "https://mcp.context7.com/mcp",
+				Headers: map[string]string{"API_KEY": "${CONTEXT7_KEY}"}, Scope: types.ScopeGlobal, Client: ClientGemini,
+			},
+			wantNotes: []string{"the default of ${CONTEXT7_KEY:-none} is dropped; Gemini CLI doesn't support defaults"},
+		},
+		{
+			name:    "codex can't run sse servers",
+			server:  types.MCPServer{Name: "events", Type: types.ServerTypeSSE, TypeStr: "sse", URL: "http://localhost:8080/sse"},
+			to:      Codex{},
+			wantErr: true,
+		},
+		{
+			name:    "claude desktop can't run remote servers",
+			server:  context7,
+			to:      ClaudeDesktop{},
+			wantErr: true,
+		},
+		{
+			name:    "unknown types can't be translated",
+			server:  types.MCPServer{Name: "future", Type: types.ServerTypeUnknown, TypeStr: "websocket"},
+			to:      Cursor{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, notes, err := Translate(&tt.server, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Translate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrUnsupportedServer) {
+					t.Errorf("Translate() error = %v, want ErrUnsupportedServer", err)
+				}
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Translate() mismatch (-want +got):\n%s", diff)
+			}
+			// Arguments are translated before env values, which are unordered
+			if diff := cmp.Diff(len(tt.wantNotes), len(notes)); diff != "" {
+				t.Errorf("Translate() notes = %q, want %q", notes, tt.wantNotes)
+			}
+			for _, note := range tt.wantNotes {
+				if !contains(notes, note) {
+					t.Errorf("Translate() notes = %q, missing %q", notes, note)
+				}
+			}
+		})
+	}
+}
+
+func contains(items []string, item string) bool {
+	for _, s := range items {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPlanSync(t *testing.T) {
+	servers := []types.MCPServer{
+		{Name: "context7", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://mcp.context7.com/mcp"},
+		{Name: "github", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://api.githubcopilot.com/mcp/"},
+		{Name: "puppeteer", Type: types.ServerTypeStdio, Command: "npx", Args: []string{"-y", "@anthropic/server-puppeteer"}},
+		{Name: "events", Type: types.ServerTypeSSE, TypeStr: "sse", URL: "http://localhost:8080/sse"},
+	}
+	// As loaded from Cursor's mcp.json: the url-only entry counts as http
+	existing := []types.MCPServer{
+		{Name: "context7", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://mcp.context7.com/mcp", Client: ClientCursor},
+		{Name: "github", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://api.github.com/mcp", Client: ClientCursor},
+	}
+
+	plan := PlanSync(servers, Cursor{}, existing)
+	got := make(map[string]SyncAction, len(plan))
+	for i := range plan {
+		got[plan[i].Server.Name] = plan[i].Action
+	}
+	want := map[string]SyncAction{"context7": SyncUnchanged, "github": SyncUpdate, "puppeteer": SyncAdd, "events": SyncAdd}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("PlanSync() actions mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{`url: "https://api.github.com/mcp" → "https://api.githubcopilot.com/mcp/"`}, plan[1].Changes); diff != "" {
+		t.Errorf("PlanSync() changes mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"github", "puppeteer", "events"}, names(SyncUpserts(plan))); diff != "" {
+		t.Errorf("SyncUpserts() mismatch (-want +got):\n%s", diff)
+	}
+
+	plan = PlanSync(servers[3:], ClaudeDesktop{}, nil)
+	if plan[0].Action != SyncUnsupported || plan[0].Reason == "" {
+		t.Errorf("PlanSync() to Claude Desktop = %+v, want an unsupported sse server", plan[0])
+	}
+}
+
+func names(servers []types.MCPServer) []string {
+	result := make([]string, 0, len(servers))
+	for i := range servers {
+		result = append(result, servers[i].Name)
+	}
+	return result
+}
@@ -0,0 +1,711 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Codex keeps its settings in TOML, with each server in a table under
+// mcp_servers. The values are read with a TOML library, but none keeps
+// comments and formatting when writing, so the writer below only rewrites the
+// lines of the servers that change, leaving the rest of the file as the user
+// wrote it. To find those lines, a small scanner locates the statements of
+// the document without interpreting their values.
+
+// isTOML reports whether a config file is TOML, like Codex's config.toml.
+func isTOML(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".toml")
+}
+
+// tomlStatement is a table header or a key/value line of a TOML document.
+type tomlStatement struct {
+	// path is the full key: the table's for headers, the table's followed
+	// by the key for key/values.
+	path   []string
+	header bool
+	// table is the index of the header of the table a key/value belongs
+	// to, or -1 for the root table.
+	table int
+	// first is the first line, including the comments right above it;
+	// start and end are the lines of the statement itself. For headers,
+	// end is the last line of the table's section.
+	first, start, end int
+	// comment is the comment after a key/value, if any.
+	comment string
+}
+
+// hasPrefix reports whether the statement's key starts with prefix.
+func (s *tomlStatement) hasPrefix(prefix ...string) bool {
+	return len(s.path) >= len(prefix) && slices.Equal(s.path[:len(prefix)], prefix)
+}
+
+// tomlDocument is a parsed TOML document.
+type tomlDocument struct {
+	// values holds strings, int64, float64, bool, []interface{} and
+	// map[string]interface{} values, as encoding/json would. Dates and times
+	// are kept as strings.
+	values     map[string]interface{}
+	statements []tomlStatement
+	lines      []string
+}
+
+// parseTOML parses a TOML document.
+func parseTOML(content []byte) (*tomlDocument, error) {
+	src := string(content)
+	values := make(map[string]interface{})
+	if _, err := toml.Decode(src, &values); err != nil {
+		return nil, err
+	}
+	doc := &tomlDocument{values: normalizeTOML(values).(map[string]interface{}), lines: strings.Split(src, "\n")}
+
+	// The document is valid, so the scanner only has to find the statements
+	s := &tomlScanner{src: src, lineStarts: []int{0}, doc: doc, tableIndex: -1, comments: -1}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			s.lineStarts = append(s.lineStarts, i+1)
+		}
+	}
+	s.scan()
+
+	// A table's section runs until the comments above the next header
+	last := doc.lastLine()
+	next := last + 1
+	for i := len(doc.statements) - 1; i >= 0; i-- {
+		if stmt := &doc.statements[i]; stmt.header {
+			stmt.end = next - 1
+			next = stmt.first
+		}
+	}
+	return doc, nil
+}
+
+// normalizeTOML converts decoded TOML values to those encoding/json would
+// decode: arrays of tables become []interface{} and dates and times strings.
+func normalizeTOML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeTOML(item)
+		}
+		return v
+	case []map[string]interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = normalizeTOML(item)
+		}
+		return items
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeTOML(item)
+		}
+		return v
+	case time.Time:
+		// The library marks local dates and times with these zones
+		switch v.Location().String() {
+		case "date-local":
+			return v.Format(time.DateOnly)
+		case "time-local":
+			return v.Format("15:04:05.999999999")
+		case "datetime-local":
+			return v.Format("2006-01-02T15:04:05.999999999")
+		}
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+// lastLine returns the index of the last line, not counting the empty one
+// after a final newline; -1 for an empty document.
+func (d *tomlDocument) lastLine() int {
+	if n := len(d.lines); d.lines[n-1] == "" {
+		return n - 2
+	}
+	return len(d.lines) - 1
+}
+
+// tomlScanner finds the statements of a valid TOML document.
+type tomlScanner struct {
+	src        string
+	pos        int
+	lineStarts []int
+	doc        *tomlDocument
+	// tablePath is the current table, opened by the header at tableIndex.
+	tablePath  []string
+	tableIndex int
+	// comments is the first line of the comments above the next statement,
+	// or -1.
+	comments int
+}
+
+func (s *tomlScanner) line() int {
+	return sort.Search(len(s.lineStarts), func(i int) bool { return s.lineStarts[i] > s.pos }) - 1
+}
+
+func (s *tomlScanner) peek() byte {
+	if s.pos < len(s.src) {
+		return s.src[s.pos]
+	}
+	return 0
+}
+
+func (s *tomlScanner) skipSpaces() {
+	for s.pos < len(s.src) && (s.src[s.pos] == ' ' || s.src[s.pos] == '\t') {
+		s.pos++
+	}
+}
+
+// endOfLine consumes the rest of a line and returns its comment, if any.
+func (s *tomlScanner) endOfLine() string {
+	start := s.pos
+	for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+		s.pos++
+	}
+	rest := strings.TrimSpace(s.src[start:s.pos])
+	s.pos++
+	if strings.HasPrefix(rest, "#") {
+		return rest
+	}
+	return ""
+}
+
+func (s *tomlScanner) scan() {
+	for {
+		s.skipSpaces()
+		if s.pos >= len(s.src) {
+			return
+		}
+		switch s.src[s.pos] {
+		case '\r', '\n':
+			s.comments = -1
+			s.endOfLine()
+		case '#':
+			if s.comments < 0 {
+				s.comments = s.line()
+			}
+			s.endOfLine()
+		case '[':
+			s.scanHeader()
+		default:
+			s.scanKeyValue()
+		}
+	}
+}
+
+// takeComments returns the first line of a statement starting at start.
+func (s *tomlScanner) takeComments(start int) int {
+	first := start
+	if s.comments >= 0 {
+		first = s.comments
+	}
+	s.comments = -1
+	return first
+}
+
+func (s *tomlScanner) scanHeader() {
+	start := s.line()
+	first := s.takeComments(start)
+	s.pos++
+	if s.peek() == '[' {
+		s.pos++
+	}
+	path := s.scanKey()
+	s.endOfLine()
+
+	s.tablePath = path
+	s.doc.statements = append(s.doc.statements, tomlStatement{path: path, header: true, table: -1, first: first, start: start, end: start})
+	s.tableIndex = len(s.doc.statements) - 1
+}
+
+func (s *tomlScanner) scanKeyValue() {
+	start := s.line()
+	first := s.takeComments(start)
+	key := s.scanKey()
+	s.pos++ // =
+	s.skipValue()
+	end := s.line()
+	comment := s.endOfLine()
+
+	path := append(append([]string{}, s.tablePath...), key...)
+	s.doc.statements = append(s.doc.statements, tomlStatement{path: path, table: s.tableIndex, first: first, start: start, end: end, comment: comment})
+}
+
+// isBareKeyChar reports whether c may appear in a bare key.
+func isBareKeyChar(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// scanKey reads a dotted key into its parts.
+func (s *tomlScanner) scanKey() []string {
+	var parts []string
+	for {
+		s.skipSpaces()
+		start := s.pos
+		switch s.peek() {
+		case '"', '\'':
+			s.skipString()
+			part := s.src[start+1 : s.pos-1]
+			if s.src[start] == '"' {
+				if unquoted, err := strconv.Unquote(s.src[start:s.pos]); err == nil {
+					part = unquoted
+				}
+			}
+			parts = append(parts, part)
+		default:
+			for s.pos < len(s.src) && isBareKeyChar(s.src[s.pos]) {
+				s.pos++
+			}
+			parts = append(parts, s.src[start:s.pos])
+		}
+		s.skipSpaces()
+		if s.peek() != '.' {
+			return parts
+		}
+		s.pos++
+	}
+}
+
+// skipString skips a string starting at the current position.
+func (s *tomlScanner) skipString() {
+	quote := s.src[s.pos]
+	delim := string(quote)
+	if strings.HasPrefix(s.src[s.pos:], strings.Repeat(delim, 3)) {
+		delim = strings.Repeat(delim, 3)
+	}
+	s.pos += len(delim)
+	for s.pos < len(s.src) {
+		switch {
+		case s.src[s.pos] == '\\' && quote == '"':
+			s.pos += 2
+		case strings.HasPrefix(s.src[s.pos:], delim):
+			s.pos += len(delim)
+			// Up to two quotes right before the closing delimiter belong to the string
+			for n := 0; n < 2 && len(delim) == 3 && s.peek() == quote; n++ {
+				s.pos++
+			}
+			return
+		default:
+			s.pos++
+		}
+	}
+}
+
+// skipValue skips a value, which may span lines inside strings, arrays and
+// inline tables.
+func (s *tomlScanner) skipValue() {
+	depth := 0
+	for s.pos < len(s.src) {
+		switch c := s.src[s.pos]; {
+		case c == '"' || c == '\'':
+			s.skipString()
+		case c == '[' || c == '{':
+			depth++
+			s.pos++
+		case c == ']' || c == '}':
+			depth--
+			s.pos++
+		case c == '#' || c == '\n':
+			if depth == 0 {
+				return
+			}
+			for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+				s.pos++
+			}
+			s.pos++
+		default:
+			s.pos++
+		}
+	}
+}
+
+// patchTOML returns content with its mcp_servers changed to those of raw, a
+// parsed and edited copy of it. Only the lines of the servers that changed
+// are rewritten; comments and formatting elsewhere are kept. A server that
+// isn't defined in its own [mcp_servers.NAME] table is rewritten as one.
+// Servers defined inside an inline mcp_servers table can't be edited line
+// by line, so changing them is an error.
+func patchTOML(content []byte, raw map[string]interface{}) ([]byte, error) {
+	doc, err := parseTOML(content)
+	if err != nil {
+		return nil, err
+	}
+	before, _ := doc.values[codexServersKey].(map[string]interface{})
+	after, _ := raw[codexServersKey].(map[string]interface{})
+
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	e := &tomlEditor{doc: doc, remove: make(map[int]bool), replace: make(map[int]string), insert: make(map[int][]string)}
+	for _, name := range names {
+		old, had := before[name]
+		entry, has := after[name]
+		if had && has && sameValue(old, entry) {
+			continue
+		}
+		if stmt := doc.inlineServers(); stmt != nil {
+			return nil, fmt.Errorf("line %d: %s is an inline table, which can't be edited in place; define each server in its own [%s.NAME] table", stmt.start+1, codexServersKey, codexServersKey)
+		}
+		switch {
+		case !has:
+			e.removeStatements(codexServersKey, name)
+		case !had:
+			if err := e.appendServer(name, entry); err != nil {
+				return nil, err
+			}
+		default:
+			if err := e.updateServer(name, old, entry); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return e.bytes(), nil
+}
+
+// inlineServers returns the statement setting mcp_servers to an inline
+// table, if any.
+func (d *tomlDocument) inlineServers() *tomlStatement {
+	for i := range d.statements {
+		if stmt := &d.statements[i]; !stmt.header && slices.Equal(stmt.path, []string{codexServersKey}) {
+			return stmt
+		}
+	}
+	return nil
+}
+
+// tomlEditor collects line changes to a TOML document.
+type tomlEditor struct {
+	doc     *tomlDocument
+	remove  map[int]bool
+	replace map[int]string
+	// insert holds lines to add after a line.
+	insert map[int][]string
+	// tables are new tables appended to the document.
+	tables [][]string
+}
+
+// removeStatements removes the statements under a key, with the comments
+// right above them.
+func (e *tomlEditor) removeStatements(prefix ...string) {
+	for i := range e.doc.statements {
+		if stmt := &e.doc.statements[i]; stmt.hasPrefix(prefix...) {
+			for line := stmt.first; line <= stmt.end; line++ {
+				e.remove[line] = true
+			}
+		}
+	}
+}
+
+// appendServer adds a server as a new table at the end of the document.
+func (e *tomlEditor) appendServer(name string, entry interface{}) error {
+	fields, ok := entry.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("server %s must be a table", name)
+	}
+	table := []string{"[" + tomlKey(codexServersKey, name) + "]"}
+	for _, key := range serverKeyOrder(fields) {
+		table = append(table, tomlKey(key)+" = "+tomlValue(fields[key]))
+	}
+	e.tables = append(e.tables, table)
+	return nil
+}
+
+// updateServer rewrites the keys of a server that changed, in place where
+// possible. Changed keys that were spread over several statements or
+// tables, such as an [mcp_servers.NAME.env] table, become a single
+// key/value at the end of the server's table.
+func (e *tomlEditor) updateServer(name string, old, entry interface{}) error {
+	oldFields, _ := old.(map[string]interface{})
+	fields, ok := entry.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("server %s must be a table", name)
+	}
+	header := e.serverHeader(name)
+	if header < 0 || oldFields == nil {
+		e.removeStatements(codexServersKey, name)
+		return e.appendServer(name, entry)
+	}
+
+	// New keys go after the last key/value of the server's table
+	last := e.tableEnd(header)
+	keys := make(map[string]interface{}, len(fields)+len(oldFields))
+	for key := range oldFields {
+		keys[key] = nil
+	}
+	for key := range fields {
+		keys[key] = nil
+	}
+	for _, key := range serverKeyOrder(keys) {
+		value, has := fields[key]
+		if !sameValue(oldFields[key], value) {
+			e.updateKey(name, key, value, has, last)
+		}
+	}
+	return nil
+}
+
+// serverHeader returns the index of the [mcp_servers.NAME] header of a
+// server, or -1 if it has none.
+func (e *tomlEditor) serverHeader(name string) int {
+	header := -1
+	for i := range e.doc.statements {
+		if stmt := &e.doc.statements[i]; stmt.header && slices.Equal(stmt.path, []string{codexServersKey, name}) {
+			header = i
+		}
+	}
+	return header
+}
+
+// tableEnd returns the last line of the key/values of the table opened by
+// the header at index header.
+func (e *tomlEditor) tableEnd(header int) int {
+	last := e.doc.statements[header].start
+	for i := range e.doc.statements {
+		if stmt := &e.doc.statements[i]; stmt.table == header && stmt.end > last {
+			last = stmt.end
+		}
+	}
+	return last
+}
+
+// updateKey rewrites a key of a server that changed: in place if it is a
+// single key/value of the server's table, and otherwise as a new key/value
+// after line last. Without has, the key is removed.
+func (e *tomlEditor) updateKey(name, key string, value interface{}, has bool, last int) {
+	var stmts []*tomlStatement
+	for i := range e.doc.statements {
+		if stmt := &e.doc.statements[i]; stmt.hasPrefix(codexServersKey, name, key) {
+			stmts = append(stmts, stmt)
+		}
+	}
+	line := tomlKey(key) + " = " + tomlValue(value)
+	if has && len(stmts) == 1 && !stmts[0].header && len(stmts[0].path) == 3 {
+		e.replaceStatement(stmts[0], line)
+		return
+	}
+	e.removeStatements(codexServersKey, name, key)
+	if has {
+		e.insert[last] = append(e.insert[last], line)
+	}
+}
+
+// replaceStatement replaces a key/value, keeping its indentation and comment.
+func (e *tomlEditor) replaceStatement(stmt *tomlStatement, line string) {
+	original := e.doc.lines[stmt.start]
+	indent := original[:len(original)-len(strings.TrimLeft(original, " \t"))]
+	if stmt.comment != "" {
+		line += " " + stmt.comment
+	}
+	e.replace[stmt.start] = indent + line
+	for l := stmt.start + 1; l <= stmt.end; l++ {
+		e.remove[l] = true
+	}
+}
+
+// bytes returns the edited document.
+func (e *tomlEditor) bytes() []byte {
+	var out []string
+	// blank is set when the removed lines right before end with a blank
+	// line, such as a subtable separating its section from the next table.
+	// The blank line is kept if nothing else separates them.
+	blank := false
+	for i := 0; i <= e.doc.lastLine(); i++ {
+		line := e.doc.lines[i]
+		if e.remove[i] {
+			blank = strings.TrimSpace(line) == ""
+		} else {
+			if r, ok := e.replace[i]; ok {
+				line = r
+			}
+			if blank && len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" && strings.TrimSpace(line) != "" {
+				out = append(out, "")
+			}
+			blank = false
+			out = append(out, line)
+		}
+		out = append(out, e.insert[i]...)
+	}
+	// Removed tables may leave blank lines at the end
+	if len(e.remove) > 0 {
+		for len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+			out = out[:len(out)-1]
+		}
+	}
+	for _, table := range e.tables {
+		if len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" {
+			out = append(out, "")
+		}
+		out = append(out, table...)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+// sameValue reports whether two parsed or edited values are equal, such as
+// a parsed []interface{} and a []string with the same strings.
+func sameValue(a, b interface{}) bool {
+	return tomlValue(a) == tomlValue(b)
+}
+
+// serverKeyRanks orders the keys of a server table; other keys come in
+// between, sorted.
+var serverKeyRanks = map[string]int{
+	"command": -3, "args": -2, "url": -1, "env": 1, "http_headers": 2, "env_http_headers": 3,
+}
+
+// serverKeyOrder returns the keys of a server table in the order they are written.
+func serverKeyOrder(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := serverKeyRanks[keys[i]], serverKeyRanks[keys[j]]
+		if ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// tomlKey returns a dotted key, quoting the parts that can't be bare.
+func tomlKey(parts ...string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		quoted[i] = part
+		for j := 0; j < len(part); j++ {
+			if !isBareKeyChar(part[j]) {
+				quoted[i] = tomlString(part)
+				break
+			}
+		}
+		if part == "" {
+			quoted[i] = `""`
+		}
+	}
+	return strings.Join(quoted, ".")
+}
+
+// tomlString returns s as a basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// tomlValue renders a value inline: tables as inline tables with sorted keys.
+func tomlValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return tomlString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return tomlFloat(v)
+	case json.Number:
+		return v.String()
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return tomlArray(items)
+	case []interface{}:
+		return tomlArray(v)
+	case map[string]string:
+		table := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			table[key] = item
+		}
+		return tomlInlineTable(table)
+	case map[string]interface{}:
+		return tomlInlineTable(v)
+	default:
+		return tomlString(fmt.Sprint(v))
+	}
+}
+
+// tomlArray renders an array on one line.
+func tomlArray(values []interface{}) string {
+	items := make([]string, len(values))
+	for i, item := range values {
+		items[i] = tomlValue(item)
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// tomlInlineTable renders an inline table with sorted keys.
+func tomlInlineTable(table map[string]interface{}) string {
+	if len(table) == 0 {
+		return "{}"
+	}
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	items := make([]string, len(keys))
+	for i, key := range keys {
+		items[i] = tomlKey(key) + " = " + tomlValue(table[key])
+	}
+	return "{ " + strings.Join(items, ", ") + " }"
+}
+
+// tomlFloat renders a float so it reads back as a float.
+func tomlFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	switch s {
+	case "+Inf":
+		return "inf"
+	case "-Inf":
+		return "-inf"
+	case "NaN":
+		return "nan"
+	}
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTOML(t *testing.T) {
+	content := `# settings
+title = "a \"quoted\" \u00e9 string"
+path = 'C:\Users\me'
+count = 1_000
+ratio = 0.5
+enabled = true
+released = 1979-05-27 07:32:00Z
+hex = 0xff
+site."google.com" = true
+
+description = """
+first line \
+  continued"""
+literal = '''
+raw \n'''
+
+[mcp_servers.context7]
+command = "npx"
+args = [
+  "-y", # the package follows
+  "@upstash/context7-mcp",
+]
+env = { KEY = "value", nested.deep = 1 }
+
+[[profiles]]
+name = "fast"
+
+[[profiles]]
+name = "slow"
+`
+	doc, err := parseTOML([]byte(content))
+	if err != nil {
+		t.Fatalf("parseTOML() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"title":       "a \"quoted\" é string",
+		"path":        `C:\Users\me`,
+		"count":       int64(1000),
+		"ratio":       0.5,
+		"enabled":     true,
+		"released":    "1979-05-27T07:32:00Z",
+		"hex":         int64(255),
+		"site":        map[string]interface{}{"google.com": true},
+		"description": "first line continued",
+		"literal":     `raw \n`,
+		"mcp_servers": map[string]interface{}{
+			"context7": map[string]interface{}{
+				"command": "npx",
+				"args":    []interface{}{"-y", "@upstash/context7-mcp"},
+				"env":     map[string]interface{}{"KEY": "value", "nested": map[string]interface{}{"deep": int64(1)}},
+			},
+		},
+		"profiles": []interface{}{
+			map[string]interface{}{"name": "fast"},
+			map[string]interface{}{"name": "slow"},
+		},
+	}
+	if diff := cmp.Diff(want, doc.values); diff != "" {
+		t.Errorf("parseTOML() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseTOML_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "missing value", content: "key =\n"},
+		{name: "unterminated string", content: "key = \"value\n"},
+		{name: "duplicate key", content: "key = 1\nkey = 2\n"},
+		{name: "garbage after value", content: "key = 1 2\n"},
+		{name: "unclosed table", content: "[mcp_servers\n"},
+		{name: "unclosed array", content: "args = [\"a\"\n"},
+		{name: "table redefined as value", content: "a = 1\n[a.b]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseTOML([]byte(tt.content)); err == nil {
+				t.Errorf("parseTOML(%q) error = nil, want an error", tt.content)
+			}
+		})
+	}
+}
+
+func TestPatchTOML(t *testing.T) {
+	const base = `model = "o3"
+
+# Docs lookups
+[mcp_servers.context7]
+command = "npx" # pinned
+args = [
+  "-y",
+  "@upstash/context7-mcp",
+]
+startup_timeout_sec = 20
+
+[mcp_servers.context7.env]
+CONTEXT7_KEY = "abc"
+
+[mcp_servers.puppeteer]
+command = "npx"
+
+[profiles.fast]
+model = "o4-mini"
+`
+
+	tests := []struct {
+		name    string
+		content string
+		edit    func(servers map[string]interface{})
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "nothing changed",
+			content: base,
+			edit:    func(map[string]interface{}) {},
+			want:    base,
+		},
+		{
+			name:    "remove a server with its comments and subtables",
+			content: base,
+			edit:    func(servers map[string]interface{}) { delete(servers, "context7") },
+			want: `model = "o3"
+
+[mcp_servers.puppeteer]
+command = "npx"
+
+[profiles.fast]
+model = "o4-mini"
+`,
+		},
+		{
+			name:    "remove the last server",
+			content: "model = \"o3\"\n\n[mcp_servers.puppeteer]\ncommand = \"npx\"\n",
+			edit:    func(servers map[string]interface{}) { delete(servers, "puppeteer") },
+			want:    "model = \"o3\"\n",
+		},
+		{
+			name:    "update keys in place",
+			content: base,
+			edit: func(servers map[string]interface{}) {
+				entry := servers["context7"].(map[string]interface{})
+				entry["command"] = "bunx"
+				entry["args"] = []string{"@upstash/context7-mcp@2"}
+				entry["env"] = map[string]string{"CONTEXT7_KEY": "xyz"}
+			},
+			want: `model = "o3"
+
+# Docs lookups
+[mcp_servers.context7]
+command = "bunx" # pinned
+args = ["@upstash/context7-mcp@2"]
+startup_timeout_sec = 20
+env = { CONTEXT7_KEY = "xyz" }
+
+[mcp_servers.puppeteer]
+command = "npx"
+
+[profiles.fast]
+model = "o4-mini"
+`,
+		},
+		{
+			name:    "add a server",
+			content: base,
+			edit: func(servers map[string]interface{}) {
+				servers["my.figma"] = map[string]interface{}{
+					"url":          "https://mcp.figma.com/mcp",
+					"http_headers": map[string]string{"X-Team": "design"},
+				}
+			},
+			want: base + `
+[mcp_servers."my.figma"]
+url = "https://mcp.figma.com/mcp"
+http_headers = { X-Team = "design" }
+`,
+		},
+		{
+			name:    "inline servers are rewritten as tables",
+			content: "[mcp_servers]\nfetch = { command = \"uvx\" }\nother = { command = \"npx\" }\n",
+			edit: func(servers map[string]interface{}) {
+				servers["fetch"].(map[string]interface{})["args"] = []string{"mcp-server-fetch"}
+			},
+			want: "[mcp_servers]\nother = { command = \"npx\" }\n\n[mcp_servers.fetch]\ncommand = \"uvx\"\nargs = [\"mcp-server-fetch\"]\n",
+		},
+		{
+			name:    "add to a file without servers",
+			content: "",
+			edit: func(servers map[string]interface{}) {
+				servers["fetch"] = map[string]interface{}{"command": "uvx"}
+			},
+			want: "[mcp_servers.fetch]\ncommand = \"uvx\"\n",
+		},
+		{
+			name:    "servers in an inline table at the root",
+			content: "model = \"o3\"\nmcp_servers = { fetch = { command = \"uvx\" }, other = { command = \"npx\" } }\n",
+			edit:    func(servers map[string]interface{}) { delete(servers, "fetch") },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := decodeConfig("config.toml", []byte(tt.content))
+			if err != nil {
+				t.Fatalf("decodeConfig() error = %v", err)
+			}
+			tt.edit(raw[codexServersKey].(map[string]interface{}))
+
+			got, err := patchTOML([]byte(tt.content), raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("patchTOML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.want, string(got)); diff != "" {
+				t.Errorf("patchTOML() mismatch (-want +got):\n%s", diff)
+			}
+			if _, err := parseTOML(got); err != nil {
+				t.Errorf("patchTOML() result doesn't parse: %v", err)
+			}
+		})
+	}
+}
+
+func TestPatchTOML_Golden(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "testdata", "codex", "config.toml"))
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	want, err := os.ReadFile(filepath.Join("..", "testdata", "codex", "config_patched.toml.golden"))
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	raw, err := decodeConfig("config.toml", content)
+	if err != nil {
+		t.Fatalf("decodeConfig() error = %v", err)
+	}
+	servers := raw[codexServersKey].(map[string]interface{})
+	// Both env subtables are rewritten: one directly below its server's keys,
+	// one after a blank line, each followed by a blank line
+	servers["context7"].(map[string]interface{})["env"] = map[string]string{"CONTEXT7_API_KEY": "ctx7-new"}
+	servers["github"].(map[string]interface{})["env"] = map[string]string{"GITHUB_PERSONAL_ACCESS_TOKEN": "ghp-new"}
+	delete(servers, "puppeteer")
+	servers["fetch"] = map[string]interface{}{"command": "uvx", "args": []string{"mcp-server-fetch"}}
+
+	got, err := patchTOML(content, raw)
+	if err != nil {
+		t.Fatalf("patchTOML() error = %v", err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("patchTOML() mismatch with config_patched.toml.golden (-want +got):\n%s", diff)
+	}
+	if _, err := parseTOML(got); err != nil {
+		t.Errorf("patchTOML() result doesn't parse: %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// Severity is how serious a validation issue is.
+type Severity int
+
+const (
+	// SeverityError is an entry Claude Code can't use as intended.
+	SeverityError Severity = iota
+	// SeverityWarning is suspicious but harmless, such as an unknown key.
+	SeverityWarning
+)
+
+// String returns the string representation of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// Issue is a problem found in a server entry.
+type Issue struct {
+	// Path is the JSON path of the offending value, e.g. $.mcpServers.github.url.
+	Path     string
+	Severity Severity
+	Message  string
+}
+
+// String formats the issue as "path: message".
+func (i *Issue) String() string {
+	return i.Path + ": " + i.Message
+}
+
+// ValidationError is returned by Load when the config can't be parsed.
+type ValidationError struct {
+	Path   string
+	Issues []Issue
+}
+
+// Error lists the issues, one per line, under the path of the config.
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Issues)+1)
+	lines = append(lines, fmt.Sprintf("invalid config %s:", e.Path))
+	for i := range e.Issues {
+		lines = append(lines, "  "+e.Issues[i].String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Errors returns the issues with error severity.
+func Errors(issues []Issue) []Issue {
+	var errs []Issue
+	for i := range issues {
+		if issues[i].Severity == SeverityError {
+			errs = append(errs, issues[i])
+		}
+	}
+	return errs
+}
+
+// knownServerKeys are the keys of a server entry that mcp-tidy knows about,
+// besides the client's own (see entrySchema).
+var knownServerKeys = map[string]bool{
+	"type": true, "command": true, "args": true, "env": true, "url": true,
+}
+
+// identifierPattern matches keys that can be written as .key in a JSON path.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Validate checks the MCP server entries of a Claude config. Only a config
+// that isn't valid JSON returns an error; everything else is reported as
+// issues, ordered by path.
+func Validate(data []byte) ([]Issue, error) {
+	return validate(data, ClientClaudeCode)
+}
+
+// ValidateClient is Validate for the config file of any client, at path.
+// Entries are checked as the client reads them: Cursor, for example, takes
+// an entry with a url and no type for an http server.
+func ValidateClient(client Client, path string, data []byte) ([]Issue, error) {
+	data, err := configJSON(path, data)
+	if err != nil {
+		return nil, err
+	}
+	return validate(data, client.Name())
+}
+
+// validate is Validate for the JSON config of the named client.
+func validate(data []byte, client string) ([]Issue, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	v := &validator{schema: schemaOf(client)}
+	key := serversKey(raw)
+	v.validateServers(raw[key], "$."+key)
+
+	switch projects := raw["projects"].(type) {
+	case nil:
+	case map[string]interface{}:
+		for path, project := range projects {
+			projectPath := jsonPath("$.projects", path)
+			p, ok := project.(map[string]interface{})
+			if !ok {
+				v.errorf(projectPath, "project must be an object")
+				continue
+			}
+			v.validateServers(p["mcpServers"], projectPath+".mcpServers")
+		}
+	default:
+		v.errorf("$.projects", "projects must be an object")
+	}
+
+	sort.SliceStable(v.issues, func(i, j int) bool {
+		return v.issues[i].Path < v.issues[j].Path
+	})
+	return v.issues, nil
+}
+
+// validator collects issues.
+type validator struct {
+	issues []Issue
+	schema entrySchema
+}
+
+func (v *validator) errorf(path, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{Path: path, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(path, format string, args ...interface{}) {
+	v.issues = append(v.issues, Issue{Path: path, Severity: SeverityWarning, Message: fmt.Sprintf(format, args...)})
+}
+
+// validateServers checks an mcpServers object.
+func (v *validator) validateServers(value interface{}, path string) {
+	if value == nil {
+		return
+	}
+	servers, ok := value.(map[string]interface{})
+	if !ok {
+		v.errorf(path, "mcpServers must be an object")
+		return
+	}
+	for name, entry := range servers {
+		v.validateServer(entry, jsonPath(path, name))
+	}
+}
+
+// validateServer checks a single server entry.
+func (v *validator) validateServer(value interface{}, path string) {
+	entry, ok := value.(map[string]interface{})
+	if !ok {
+		v.errorf(path, "server must be an object")
+		return
+	}
+
+	serverType, urlKey, ok := v.serverType(entry, path)
+	if !ok {
+		return
+	}
+	if serverType == types.ServerTypeStdio.String() {
+		v.validateStdio(entry, path)
+	} else {
+		v.validateRemote(entry, path, serverType, urlKey)
+	}
+	v.validateStringMap(entry["env"], path+".env")
+	v.validateKeys(entry, path)
+}
+
+// serverType returns the type of a server entry, as set or as the client
+// infers it from the keys, and the key of its url. It reports false if the
+// type is invalid.
+func (v *validator) serverType(entry map[string]interface{}, path string) (serverType, urlKey string, ok bool) {
+	serverType, urlKey = types.ServerTypeStdio.String(), "url"
+	if _, hasCommand := entry["command"]; !hasCommand {
+		switch {
+		case v.schema.httpURLKey != "" && entry[v.schema.httpURLKey] != nil:
+			serverType, urlKey = types.ServerTypeHTTP.String(), v.schema.httpURLKey
+		case entry["url"] != nil:
+			serverType = v.schema.urlType.String()
+		}
+	}
+	t, hasType := entry["type"]
+	if !hasType {
+		return serverType, urlKey, true
+	}
+	s, isString := t.(string)
+	switch {
+	case !isString:
+		v.errorf(path+".type", "type must be a string")
+		return "", "", false
+	case types.ParseServerType(s) == types.ServerTypeUnknown:
+		v.errorf(path+".type", "unknown type %q (expected stdio, http or sse)", s)
+		return "", "", false
+	}
+	return s, urlKey, true
+}
+
+// validateStdio checks the command and args of a stdio server entry.
+func (v *validator) validateStdio(entry map[string]interface{}, path string) {
+	command, isString := entry["command"].(string)
+	switch {
+	case entry["command"] == nil:
+		msg := "command is required for stdio servers"
+		if _, hasURL := entry["url"]; hasURL {
+			msg += ` (set "type" to "http" or "sse" for a remote server)`
+		}
+		v.errorf(path+".command", "%s", msg)
+	case !isString:
+		v.errorf(path+".command", "command must be a string")
+	case strings.TrimSpace(command) == "":
+		v.errorf(path+".command", "command must not be empty")
+	}
+	v.validateStrings(entry["args"], path+".args")
+	if _, ok := entry["url"]; ok {
+		v.warnf(path+".url", "url is ignored for stdio servers")
+	}
+	if _, ok := entry[v.schema.headersKey]; ok {
+		v.warnf(path+"."+v.schema.headersKey, "headers are ignored for stdio servers")
+	}
+}
+
+// validateRemote checks the url and headers of an http or sse server entry.
+func (v *validator) validateRemote(entry map[string]interface{}, path, serverType, urlKey string) {
+	v.validateURL(entry[urlKey], path+"."+urlKey, urlKey, serverType)
+	if _, ok := entry["command"]; ok {
+		v.warnf(path+".command", "command is ignored for %s servers", serverType)
+	}
+	if _, ok := entry["args"]; ok {
+		v.warnf(path+".args", "args are ignored for %s servers", serverType)
+	}
+	v.validateStringMap(entry[v.schema.headersKey], path+"."+v.schema.headersKey)
+}
+
+// validateURL checks that the url of a remote server is an absolute http or
+// https URL.
+func (v *validator) validateURL(value interface{}, path, urlKey, serverType string) {
+	rawURL, isString := value.(string)
+	switch {
+	case value == nil:
+		v.errorf(path, "%s is required for %s servers", urlKey, serverType)
+	case !isString:
+		v.errorf(path, "%s must be a string", urlKey)
+	default:
+		if u, err := url.Parse(rawURL); err != nil {
+			v.errorf(path, "invalid url: %v", err)
+		} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.errorf(path, "url %q must be an absolute http or https URL", rawURL)
+		}
+	}
+}
+
+// validateKeys warns about the keys of a server entry that the client
+// doesn't know.
+func (v *validator) validateKeys(entry map[string]interface{}, path string) {
+	keys := make([]string, 0, len(entry))
+	for key := range entry {
+		if !v.schema.knows(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		v.warnf(jsonPath(path, key), "unknown key %q", key)
+	}
+}
+
+// validateStrings checks that value, if set, is an array of strings.
+func (v *validator) validateStrings(value interface{}, path string) {
+	if value == nil {
+		return
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		v.errorf(path, "must be an array of strings")
+		return
+	}
+	for i, item := range items {
+		if _, ok := item.(string); !ok {
+			v.errorf(fmt.Sprintf("%s[%d]", path, i), "must be a string, not %s", jsonKind(item))
+		}
+	}
+}
+
+// validateStringMap checks that value, if set, is an object of strings.
+func (v *validator) validateStringMap(value interface{}, path string) {
+	if value == nil {
+		return
+	}
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		v.errorf(path, "must be an object of strings")
+		return
+	}
+	for key, item := range values {
+		if _, ok := item.(string); !ok {
+			v.errorf(jsonPath(path, key), "must be a string, not %s (quote the value)", jsonKind(item))
+		}
+	}
+}
+
+// jsonPath appends a key to a JSON path, as .key or ["key"].
+func jsonPath(path, key string) string {
+	if identifierPattern.MatchString(key) {
+		return path + "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return path + "[" + string(quoted) + "]"
+}
+
+// jsonKind names the JSON type of a parsed value.
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	default:
+		return "a string"
+	}
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []Issue
+	}{
+		{
+			name: "valid servers",
+			data: `{
+				"mcpServers": {
+					"local": {"command": "npx", "args": ["-y", "server"], "env": {"TOKEN": "x"}},
+					"remote": {"type": "http", "url": "https://example.com/mcp", "headers": {"Authorization": "Bearer x"}},
+					"events": {"type": "sse", "url": "http://localhost:8080/sse"}
+				}
+			}`,
+			want: nil,
+		},
+		{
+			name: "misspelled type",
+			data: `{"mcpServers": {"remote": {"type": "htpp", "url": "https://example.com/mcp"}}}`,
+			want: []Issue{
+				{Path: "$.mcpServers.remote.type", Severity: SeverityError, Message: `unknown type "htpp" (expected stdio, http or sse)`},
+			},
+		},
+		{
+			name: "sse without url",
+			data: `{"mcpServers": {"events": {"type": "sse"}}}`,
+			want: []Issue{
+				{Path: "$.mcpServers.events.url", Severity: SeverityError, Message: "url is required for sse servers"},
+			},
+		},
+		{
+			name: "url without type",
+			data: `{"mcpServers": {"remote": {"url": "https://example.com/mcp"}}}`,
+			want: []Issue{
+				{Path: "$.mcpServers.remote.command", Severity: SeverityError, Message: `command is required for stdio servers (set "type" to "http" or "sse" for a remote server)`},
+				{Path: "$.mcpServers.remote.url", Severity: SeverityWarning, Message: "url is ignored for stdio servers"},
+			},
+		},
+		{
+			name: "empty command and non-string arg",
+			data: `{"mcpServers": {"local": {"command": " ", "args": ["--port", 8080]}}}`,
+			want: []Issue{
+				{Path: "$.mcpServers.local.args[1]", Severity: SeverityError, Message: "must be a string, not a number"},
+				{Path: "$.mcpServers.local.command", Severity: SeverityError, Message: "command must not be empty"},
+			},
+		},
+		{
+			name: "non-string env value",
+			data: `{"mcpServers": {"local": {"command": "npx", "env": {"PORT": 8080, "DEBUG": true}}}}`,
+			want: []Issue{
+				{Path: "$.mcpServers.local.env.DEBUG", Severity: SeverityError, Message: "must be a string, not a boolean (quote the value)"},
+				{Path: "$.mcpServers.local.env.PORT", Severity: SeverityError, Message: "must be a string, not a number (quote the value)"},
+			},
+		},
+		{
+			name: "relative url and unknown key",
+			data: `{"mcpServers": {"remote": {"type": "http", "url": "example.com/mcp", "timeout": 30}}}`,
+			want: []Issue{
+				{Path: "$.mcpServers.remote.timeout", Severity: SeverityWarning, Message: `unknown key "timeout"`},
+				{Path: "$.mcpServers.remote.url", Severity: SeverityError, Message: `url "example.com/mcp" must be an absolute http or https URL`},
+			},
+		},
+		{
+			name: "project server path",
+			data: `{"projects": {"/Users/me/my project": {"mcpServers": {"my.server": "npx"}}}}`,
+			want: []Issue{
+				{Path: `$.projects["/Users/me/my project"].mcpServers["my.server"]`, Severity: SeverityError, Message: "server must be an object"},
+			},
+		},
+		{
+			name: "mcpServers not an object",
+			data: `{"mcpServers": []}`,
+			want: []Issue{
+				{Path: "$.mcpServers", Severity: SeverityError, Message: "mcpServers must be an object"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Validate([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Validate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		client Client
+		path   string
+		data   string
+		want   []Issue
+	}{
+		{
+			name:   "gemini httpUrl and url",
+			client: Gemini{},
+			path:   "settings.json",
+			data: `{"mcpServers": {
+				"github": {"httpUrl": "api.githubcopilot.com/mcp/", "trust": true},
+				"events": {"url": "http://localhost:8080/sse", "headers": {"X-Key": 1}}
+			}}`,
+			want: []Issue{
+				{Path: "$.mcpServers.events.headers.X-Key", Severity: SeverityError, Message: "must be a string, not a number (quote the value)"},
+				{Path: "$.mcpServers.github.httpUrl", Severity: SeverityError, Message: `url "api.githubcopilot.com/mcp/" must be an absolute http or https URL`},
+			},
+		},
+		{
+			name:   "codex toml",
+			client: Codex{},
+			path:   "config.toml",
+			data: `[mcp_servers.figma]
+url = "https://mcp.figma.com/mcp"
+http_headers = { "X-Team" = "design" }
+startup_timeout_sec = 20
+
+[mcp_servers.fetch]
+comand = "uvx"
+`,
+			want: []Issue{
+				{Path: "$.mcp_servers.fetch.comand", Severity: SeverityWarning, Message: `unknown key "comand"`},
+				{Path: "$.mcp_servers.fetch.command", Severity: SeverityError, Message: "command is required for stdio servers"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateClient(tt.client, tt.path, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("ValidateClient() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ValidateClient() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidate_InvalidJSON(t *testing.T) {
+	if _, err := Validate([]byte(`{"mcpServers": `)); err == nil {
+		t.Error("Validate() error = nil, want error")
+	}
+}
+
+func TestLoad_Issues(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".claude.json")
+	data := `{"mcpServers": {
+		"bad": {"command": "npx", "env": {"PORT": 8080}},
+		"good": {"type": "http", "url": "https://example.com/mcp"}
+	}}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := len(cfg.Servers()); got != 2 {
+		t.Errorf("Load() loaded %d servers, want 2", got)
+	}
+	want := []Issue{
+		{Path: "$.mcpServers.bad.env.PORT", Severity: SeverityError, Message: "must be a string, not a number (quote the value)"},
+	}
+	if diff := cmp.Diff(want, cfg.Issues()); diff != "" {
+		t.Errorf("Issues() mismatch (-want +got):\n%s", diff)
+	}
+}
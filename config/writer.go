@@ -1,10 +1,14 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/nnnkkk7/mcp-tidy/types"
@@ -12,130 +16,541 @@ import (
 
 // Backup creates a backup of the config file.
 // Returns the path to the backup file.
-// Backup filename format: {original}.backup.{YYYYMMDD-HHMMSS}
+// Backup filename format: {original}.backup.{YYYYMMDD-HHMMSS}, with a -N
+// suffix when a backup of the same second exists, so none is overwritten.
 func Backup(configPath string) (string, error) {
 	// Read original content
 	content, err := os.ReadFile(configPath)
 	if err != nil {
 		return "", fmt.Errorf("failed to read config for backup: %w", err)
 	}
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config for backup: %w", err)
+	}
 
 	// Generate backup filename with timestamp
 	timestamp := time.Now().Format("20060102-150405")
-	backupPath := fmt.Sprintf("%s.backup.%s", configPath, timestamp)
+	base := fmt.Sprintf("%s.backup.%s", configPath, timestamp)
 
-	// Write backup file
-	if err := os.WriteFile(backupPath, content, 0o644); err != nil {
-		return "", fmt.Errorf("failed to write backup: %w", err)
+	// Write backup file, never replacing an existing one
+	for n := 1; ; n++ {
+		backupPath := base
+		if n > 1 {
+			backupPath = fmt.Sprintf("%s-%d", base, n)
+		}
+		f, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to write backup: %w", err)
+		}
+		// The backup is as private as the config it copies
+		if err := f.Chmod(info.Mode().Perm()); err != nil {
+			_ = f.Close()
+			return "", fmt.Errorf("failed to write backup: %w", err)
+		}
+		if _, err := f.Write(content); err != nil {
+			_ = f.Close()
+			return "", fmt.Errorf("failed to write backup: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return "", fmt.Errorf("failed to write backup: %w", err)
+		}
+		return backupPath, nil
 	}
-
-	return backupPath, nil
 }
 
 // RemoveServer removes a server from the config file.
 // For global servers, removes from mcpServers.
 // For project servers, removes from projects.{path}.mcpServers.
 func RemoveServer(configPath string, server *types.MCPServer) error {
-	// Read and parse the config file
-	content, err := os.ReadFile(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
+	if err := checkWritable([]types.MCPServer{*server}); err != nil {
+		return err
 	}
+	_, err := updateConfig(configPath, false, false, func(raw map[string]interface{}) {
+		applyChanges(raw, nil, []types.MCPServer{*server})
+	})
+	return err
+}
 
-	var raw map[string]interface{}
-	if err := json.Unmarshal(content, &raw); err != nil {
-		return fmt.Errorf("failed to parse config: %w", err)
+// RemoveServers removes multiple servers from the config file.
+// Creates a single backup before removing all servers.
+func RemoveServers(configPath string, servers []types.MCPServer) (*WriteResult, error) {
+	if len(servers) == 0 {
+		return &WriteResult{}, nil
+	}
+	if err := checkWritable(servers); err != nil {
+		return nil, err
 	}
 
-	// Remove the server based on scope
-	if server.Scope == types.ScopeGlobal {
-		if mcpServers, ok := raw["mcpServers"].(map[string]interface{}); ok {
-			delete(mcpServers, server.Name)
+	return updateConfig(configPath, true, false, func(raw map[string]interface{}) {
+		applyChanges(raw, nil, servers)
+	})
+}
+
+// ApplyChanges adds or updates the servers in upsert and removes the servers
+// in remove, with a single backup and write.
+// Updated servers keep the fields that are empty on the given server (such as
+// env and headers), so values only present in the config are preserved.
+// If the config file does not exist, it is created.
+func ApplyChanges(configPath string, upsert, remove []types.MCPServer) (*WriteResult, error) {
+	if len(upsert) == 0 && len(remove) == 0 {
+		return &WriteResult{}, nil
+	}
+	if err := checkWritable(append(append([]types.MCPServer{}, upsert...), remove...)); err != nil {
+		return nil, err
+	}
+
+	return updateConfig(configPath, true, true, func(raw map[string]interface{}) {
+		applyChanges(raw, upsert, remove)
+	})
+}
+
+// ErrReadOnlyServer is returned when a write would change a server that
+// doesn't live in ~/.claude.json, such as a .mcp.json or managed server.
+var ErrReadOnlyServer = errors.New("server is not stored in ~/.claude.json")
+
+// checkWritable returns ErrReadOnlyServer for servers outside ~/.claude.json.
+func checkWritable(servers []types.MCPServer) error {
+	for i := range servers {
+		switch servers[i].Scope {
+		case types.ScopeMCPJSON:
+			return fmt.Errorf("%s (%s): %w; disable it with 'mcp-tidy disable' instead", servers[i].Name, servers[i].ScopeString(), ErrReadOnlyServer)
+		case types.ScopeManaged:
+			return fmt.Errorf("%s (%s): %w; it is managed by your administrator", servers[i].Name, ManagedMCPFileName, ErrReadOnlyServer)
 		}
-	} else {
-		// Project scope
-		if projects, ok := raw["projects"].(map[string]interface{}); ok {
-			if project, ok := projects[server.ProjectPath].(map[string]interface{}); ok {
-				if mcpServers, ok := project["mcpServers"].(map[string]interface{}); ok {
-					delete(mcpServers, server.Name)
-				}
-			}
+	}
+	return nil
+}
+
+// document describes how updateFile reads, compares and writes a kind of
+// file.
+type document struct {
+	// name names the file in errors.
+	name     string
+	decode   func(path string, content []byte) (map[string]interface{}, error)
+	encode   func(path string, content []byte, raw map[string]interface{}) ([]byte, error)
+	snapshot func(raw map[string]interface{}) (map[serverLocation]json.RawMessage, error)
+}
+
+// configDocument is a client's MCP config, whose changes are server entries.
+var configDocument = document{name: "config", decode: decodeConfig, encode: encodeConfig, snapshot: snapshotServers}
+
+// updateConfig applies edit to the parsed config and writes it back; see
+// updateFile.
+func updateConfig(configPath string, backup, create bool, edit func(raw map[string]interface{})) (*WriteResult, error) {
+	return updateFile(configPath, configDocument, backup, create, edit)
+}
+
+// updateFile applies edit to a parsed file and writes it back.
+//
+// Claude Code rewrites its files while it runs, so the write must not undo
+// its updates: the file is locked against other mcp-tidy processes, and if it
+// changes between reading and writing, the edit is redone on the new content,
+// up to maxWriteAttempts times. With backup, a backup is created before the
+// first write. With create, a missing file is created instead of failing.
+// The result lists every entry the edit changed, as the document snapshots
+// them; if there are none, nothing is written.
+func updateFile(path string, doc document, backup, create bool, edit func(raw map[string]interface{})) (*WriteResult, error) {
+	unlock, err := lock(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	result := &WriteResult{}
+	for attempt := 0; attempt < maxWriteAttempts; attempt++ {
+		err := updateOnce(path, doc, backup, create, edit, result)
+		if errors.Is(err, errModified) {
+			continue
+		}
+		if err != nil {
+			return nil, err
 		}
+		return result, nil
 	}
+	return nil, fmt.Errorf("%w (%d attempts); try again, or quit Claude Code first", ErrConcurrentModification, maxWriteAttempts)
+}
 
-	// Marshal back to JSON with indentation
-	newContent, err := json.MarshalIndent(raw, "", "  ")
+// updateOnce makes one attempt of updateFile, recording the changes and the
+// backup in result. It returns errModified if the file changed since it was
+// read, so the edit must be redone.
+func updateOnce(path string, doc document, backup, create bool, edit func(raw map[string]interface{}), result *WriteResult) error {
+	content, fp, raw, err := readDocument(path, doc, create)
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return err
 	}
 
-	// Write atomically
-	if err := atomicWrite(configPath, newContent); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
+	before, err := doc.snapshot(raw)
+	if err != nil {
+		return err
+	}
+	edit(raw)
+	after, err := doc.snapshot(raw)
+	if err != nil {
+		return err
+	}
+	result.Changes = serverChanges(before, after)
+	if len(result.Changes) == 0 {
+		return nil
 	}
 
-	return nil
+	newContent, err := doc.encode(path, content, raw)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", doc.name, err)
+	}
+
+	if backup && fp.exists {
+		if err := backupOnce(path, result); err != nil {
+			return err
+		}
+	}
+
+	// Write atomically, unless the file changed since it was read
+	err = atomicWriteIf(path, newContent, func() error { return fp.verify(path) })
+	if err != nil && !errors.Is(err, errModified) {
+		return fmt.Errorf("failed to write %s: %w", doc.name, err)
+	}
+	return err
 }
 
-// RemoveServers removes multiple servers from the config file.
-// Creates a single backup before removing all servers.
-func RemoveServers(configPath string, servers []types.MCPServer) error {
-	if len(servers) == 0 {
-		return nil
+// readDocument reads and parses a file with its fingerprint. A missing file
+// parses as empty with create, and is an error otherwise.
+func readDocument(path string, doc document, create bool) ([]byte, fingerprint, map[string]interface{}, error) {
+	content, fp, err := readFile(path)
+	if err != nil {
+		return nil, fp, nil, fmt.Errorf("failed to read %s: %w", doc.name, err)
+	}
+	if !fp.exists && !create {
+		return nil, fp, nil, fmt.Errorf("failed to read %s: %w", doc.name, os.ErrNotExist)
+	}
+
+	raw := make(map[string]interface{})
+	if fp.exists {
+		if raw, err = doc.decode(path, content); err != nil {
+			return nil, fp, nil, fmt.Errorf("failed to parse %s: %w", doc.name, err)
+		}
 	}
+	return content, fp, raw, nil
+}
 
-	// Create backup first
-	backupPath, err := Backup(configPath)
+// backupOnce backs up the file unless an earlier attempt already did.
+func backupOnce(path string, result *WriteResult) error {
+	if result.Backup != "" {
+		return nil
+	}
+	backupPath, err := Backup(path)
 	if err != nil {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
-	fmt.Printf("Backup created: %s\n", backupPath)
+	result.Backup = backupPath
+	return nil
+}
 
-	// Read and parse the config file
-	content, err := os.ReadFile(configPath)
+// decodeConfig parses a config file: TOML for Codex's config.toml, which
+// always gets an mcp_servers object so its servers are found by serversKey,
+// and JSON for every other file.
+func decodeConfig(path string, content []byte) (map[string]interface{}, error) {
+	if !isTOML(path) {
+		raw := make(map[string]interface{})
+		if err := json.Unmarshal(content, &raw); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	}
+	doc, err := parseTOML(content)
 	if err != nil {
-		return fmt.Errorf("failed to read config: %w", err)
+		return nil, err
 	}
+	if _, ok := doc.values[codexServersKey]; !ok {
+		doc.values[codexServersKey] = make(map[string]interface{})
+	}
+	return doc.values, nil
+}
 
-	var raw map[string]interface{}
-	if err := json.Unmarshal(content, &raw); err != nil {
-		return fmt.Errorf("failed to parse config: %w", err)
+// encodeConfig returns the content of a config file after its parsed form
+// was edited. JSON is written in full with indentation; TOML is patched, so
+// only the servers that changed are rewritten.
+func encodeConfig(path string, content []byte, raw map[string]interface{}) ([]byte, error) {
+	if isTOML(path) {
+		return patchTOML(content, raw)
 	}
+	return json.MarshalIndent(raw, "", "  ")
+}
 
-	// Remove each server
-	for i := range servers {
-		if servers[i].Scope == types.ScopeGlobal {
-			if mcpServers, ok := raw["mcpServers"].(map[string]interface{}); ok {
-				delete(mcpServers, servers[i].Name)
+// configJSON returns a config file as JSON, converting TOML.
+func configJSON(path string, content []byte) ([]byte, error) {
+	if !isTOML(path) {
+		return content, nil
+	}
+	raw, err := decodeConfig(path, content)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(raw)
+}
+
+// SubtreeChange is the content of an mcpServers object before and after a change.
+type SubtreeChange struct {
+	// Path locates the object, e.g. mcpServers or projects["/work/app"].mcpServers.
+	Path string
+	// Before and After are the object as indented JSON; Before is nil when
+	// the object does not exist yet.
+	Before []byte
+	After  []byte
+}
+
+// PreviewChanges returns how ApplyChanges would change the mcpServers
+// objects it touches, without writing anything. Objects that end up
+// unchanged are left out. Env and header values are masked so the preview
+// can be shared for review.
+func PreviewChanges(configPath string, upsert, remove []types.MCPServer) ([]SubtreeChange, error) {
+	if err := checkWritable(append(append([]types.MCPServer{}, upsert...), remove...)); err != nil {
+		return nil, err
+	}
+	before := make(map[string]interface{})
+	after := make(map[string]interface{})
+	content, err := os.ReadFile(configPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	default:
+		// Parse twice to get two independent copies
+		if before, err = decodeConfig(configPath, content); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+		if after, err = decodeConfig(configPath, content); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+	}
+
+	applyChanges(after, upsert, remove)
+
+	// One entry per touched object: global first, then projects by path
+	touched := make(map[string]*types.MCPServer)
+	for _, servers := range [][]types.MCPServer{upsert, remove} {
+		for i := range servers {
+			touched[subtreePath(after, &servers[i])] = &servers[i]
+		}
+	}
+	paths := make([]string, 0, len(touched))
+	for path := range touched {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var changes []SubtreeChange
+	for _, path := range paths {
+		change := SubtreeChange{Path: path}
+		if obj := serversObject(before, touched[path], false); obj != nil {
+			if change.Before, err = json.MarshalIndent(maskSecrets(obj), "", "  "); err != nil {
+				return nil, fmt.Errorf("failed to marshal config: %w", err)
 			}
-		} else {
-			if projects, ok := raw["projects"].(map[string]interface{}); ok {
-				if project, ok := projects[servers[i].ProjectPath].(map[string]interface{}); ok {
-					if mcpServers, ok := project["mcpServers"].(map[string]interface{}); ok {
-						delete(mcpServers, servers[i].Name)
-					}
+		}
+		if obj := serversObject(after, touched[path], false); obj != nil {
+			if change.After, err = json.MarshalIndent(maskSecrets(obj), "", "  "); err != nil {
+				return nil, fmt.Errorf("failed to marshal config: %w", err)
+			}
+		}
+		if !bytes.Equal(change.Before, change.After) {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// secretMask replaces env and header values in previews.
+const secretMask = "********"
+
+// maskSecrets returns a copy of an mcpServers object with env and header
+// values masked. ${NAME} references are not secret and are kept.
+func maskSecrets(mcpServers map[string]interface{}) map[string]interface{} {
+	masked := make(map[string]interface{}, len(mcpServers))
+	for name, value := range mcpServers {
+		entry, ok := value.(map[string]interface{})
+		if !ok {
+			masked[name] = value
+			continue
+		}
+		maskedEntry := make(map[string]interface{}, len(entry))
+		for key, field := range entry {
+			maskedEntry[key] = field
+			if key != "env" && key != "headers" {
+				continue
+			}
+			// Parsed entries hold map[string]interface{}, updated ones map[string]string
+			var values map[string]interface{}
+			switch m := field.(type) {
+			case map[string]interface{}:
+				values = m
+			case map[string]string:
+				values = make(map[string]interface{}, len(m))
+				for k, v := range m {
+					values[k] = v
 				}
+			default:
+				continue
 			}
+			maskedValues := make(map[string]interface{}, len(values))
+			for k, v := range values {
+				if str, ok := v.(string); ok && strings.HasPrefix(str, "${") {
+					maskedValues[k] = v
+				} else {
+					maskedValues[k] = secretMask
+				}
+			}
+			maskedEntry[key] = maskedValues
 		}
+		masked[name] = maskedEntry
 	}
+	return masked
+}
 
-	// Marshal back to JSON with indentation
-	newContent, err := json.MarshalIndent(raw, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+// subtreePath returns the location of the mcpServers object a server belongs to.
+func subtreePath(raw map[string]interface{}, server *types.MCPServer) string {
+	if server.Scope == types.ScopeProject {
+		return fmt.Sprintf("projects[%q].mcpServers", server.ProjectPath)
 	}
+	return serversKey(raw)
+}
 
-	// Write atomically
-	if err := atomicWrite(configPath, newContent); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
+const (
+	// vscodeServersKey is the key of the servers object in VS Code's mcp.json.
+	vscodeServersKey = "servers"
+	// codexServersKey is the table of the servers in Codex's config.toml.
+	codexServersKey = "mcp_servers"
+)
+
+// serversKey returns the key of a parsed config's global servers object:
+// "servers" in a VS Code file and "mcp_servers" in a Codex file, which have
+// no mcpServers, and "mcpServers" in every other client's file.
+func serversKey(raw map[string]interface{}) string {
+	if _, ok := raw["mcpServers"]; !ok {
+		for _, key := range []string{vscodeServersKey, codexServersKey} {
+			if _, ok := raw[key].(map[string]interface{}); ok {
+				return key
+			}
+		}
 	}
+	return "mcpServers"
+}
 
-	return nil
+// applyChanges removes and adds or updates servers in a parsed config.
+func applyChanges(raw map[string]interface{}, upsert, remove []types.MCPServer) {
+	// A file without servers gets them under the key its client reads
+	for i := range upsert {
+		key := schemaOf(upsert[i].Client).serversKey
+		if upsert[i].Scope != types.ScopeProject && raw["mcpServers"] == nil && raw[key] == nil {
+			raw[key] = make(map[string]interface{})
+		}
+	}
+	for i := range remove {
+		if mcpServers := serversObject(raw, &remove[i], false); mcpServers != nil {
+			delete(mcpServers, remove[i].Name)
+		}
+	}
+
+	for i := range upsert {
+		mcpServers := serversObject(raw, &upsert[i], true)
+		entry, ok := mcpServers[upsert[i].Name].(map[string]interface{})
+		if !ok {
+			entry = make(map[string]interface{})
+		}
+		setServerFields(entry, &upsert[i])
+		mcpServers[upsert[i].Name] = entry
+	}
+}
+
+// serversObject returns the mcpServers object a server belongs to, which is
+// called "servers" in VS Code's files.
+// With create, missing objects are created; otherwise nil is returned for them.
+func serversObject(raw map[string]interface{}, server *types.MCPServer, create bool) map[string]interface{} {
+	parent := raw
+	if server.Scope == types.ScopeProject {
+		projects, ok := raw["projects"].(map[string]interface{})
+		if !ok {
+			if !create {
+				return nil
+			}
+			projects = make(map[string]interface{})
+			raw["projects"] = projects
+		}
+		project, ok := projects[server.ProjectPath].(map[string]interface{})
+		if !ok {
+			if !create {
+				return nil
+			}
+			project = make(map[string]interface{})
+			projects[server.ProjectPath] = project
+		}
+		parent = project
+	}
+
+	key := "mcpServers"
+	if server.Scope != types.ScopeProject {
+		key = serversKey(raw)
+	}
+	mcpServers, ok := parent[key].(map[string]interface{})
+	if !ok {
+		if !create {
+			return nil
+		}
+		mcpServers = make(map[string]interface{})
+		parent[key] = mcpServers
+	}
+	return mcpServers
+}
+
+// setServerFields writes the connection fields of server into a raw server
+// entry, spelled as the server's client reads them (see entrySchema).
+// Command, args and url are replaced so the entry matches the server exactly;
+// env and headers are only replaced when set.
+func setServerFields(entry map[string]interface{}, server *types.MCPServer) {
+	set := func(key string, value interface{}, empty bool) {
+		if empty {
+			delete(entry, key)
+		} else {
+			entry[key] = value
+		}
+	}
+
+	schema := schemaOf(server.Client)
+	if schema.typed {
+		set("type", server.TypeStr, server.TypeStr == "")
+	}
+	set("command", server.Command, server.Command == "")
+	set("args", server.Args, len(server.Args) == 0)
+	urlKey := "url"
+	if schema.httpURLKey != "" {
+		if server.Type == types.ServerTypeHTTP {
+			urlKey = schema.httpURLKey
+			delete(entry, "url")
+		} else {
+			delete(entry, schema.httpURLKey)
+		}
+	}
+	set(urlKey, server.URL, server.URL == "")
+	if len(server.Env) > 0 {
+		entry["env"] = server.Env
+	}
+	if len(server.Headers) > 0 {
+		entry[schema.headersKey] = server.Headers
+	}
 }
 
 // atomicWrite writes content to a file atomically using a temp file and rename.
 // This prevents data loss if Claude Code reads the file during write.
 func atomicWrite(path string, content []byte) error {
+	return atomicWriteIf(path, content, nil)
+}
+
+// atomicWriteIf is atomicWrite with a precondition, checked right before the
+// rename to keep the window for concurrent changes small. If it fails, the
+// file is left untouched and its error is returned.
+func atomicWriteIf(path string, content []byte, precondition func() error) error {
 	// Create temp file in the same directory
 	dir := filepath.Dir(path)
 	tmpFile, err := os.CreateTemp(dir, "mcp-tidy-*.tmp")
@@ -157,6 +572,24 @@ func atomicWrite(path string, content []byte) error {
 		return fmt.Errorf("failed to close temp file: %w", err)
 	}
 
+	if precondition != nil {
+		if err := precondition(); err != nil {
+			_ = os.Remove(tmpPath)
+			return err
+		}
+	}
+
+	// Keep the file's permissions; new files stay private, as CreateTemp
+	// makes them
+	mode := os.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to set temp file permissions: %w", err)
+	}
+
 	// Rename temp file to target path (atomic on most filesystems)
 	if err := os.Rename(tmpPath, path); err != nil {
 		_ = os.Remove(tmpPath)
@@ -3,11 +3,12 @@ package config
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
-	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/nnnkkk7/mcp-tidy/types"
@@ -96,10 +97,7 @@ func TestBackup_NoOverwrite(t *testing.T) {
 		t.Fatalf("first backup failed: %v", err)
 	}
 
-	// Wait a moment to ensure different timestamps
-	time.Sleep(time.Second)
-
-	// Change the content
+	// Change the content, within the same second
 	if err := os.WriteFile(configPath, []byte(`{"test": false}`), 0o644); err != nil {
 		t.Fatalf("failed to update test config: %v", err)
 	}
@@ -123,6 +121,33 @@ func TestBackup_NoOverwrite(t *testing.T) {
 	}
 }
 
+func TestBackup_Permissions(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, mode := range []os.FileMode{0o600, 0o640, 0o664} {
+		configPath := filepath.Join(dir, fmt.Sprintf("claude-%o.json", mode))
+		if err := os.WriteFile(configPath, []byte(`{}`), mode); err != nil {
+			t.Fatal(err)
+		}
+		// WriteFile's mode is subject to the umask
+		if err := os.Chmod(configPath, mode); err != nil {
+			t.Fatal(err)
+		}
+
+		backupPath, err := Backup(configPath)
+		if err != nil {
+			t.Fatalf("Backup() error = %v", err)
+		}
+		info, err := os.Stat(backupPath)
+		if err != nil {
+			t.Fatalf("failed to stat backup: %v", err)
+		}
+		if got := info.Mode().Perm(); got != mode {
+			t.Errorf("Backup() of a %o file permissions = %o, want %o", mode, got, mode)
+		}
+	}
+}
+
 func TestRemoveServer(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -254,6 +279,332 @@ func TestRemoveServer(t *testing.T) {
 	}
 }
 
+func TestApplyChanges(t *testing.T) {
+	tests := []struct {
+		name          string
+		initialConfig string
+		upsert        []types.MCPServer
+		remove        []types.MCPServer
+		wantConfig    map[string]interface{}
+	}{
+		{
+			name: "add, update and remove servers",
+			initialConfig: `{
+				"numStartups": 3,
+				"mcpServers": {
+					"github": {"type": "stdio", "command": "npx", "args": ["github-mcp@1.0.0"], "env": {"GITHUB_TOKEN": "secret"}},
+					"puppeteer": {"type": "stdio", "command": "npx"}
+				}
+			}`,
+			upsert: []types.MCPServer{
+				{Name: "github", TypeStr: "stdio", Command: "npx", Args: []string{"github-mcp@2.0.0"}, Scope: types.ScopeGlobal},
+				{Name: "context7", TypeStr: "http", URL: "https://mcp.context7.com/mcp", Scope: types.ScopeGlobal},
+			},
+			remove: []types.MCPServer{
+				{Name: "puppeteer", Scope: types.ScopeGlobal},
+			},
+			wantConfig: map[string]interface{}{
+				"numStartups": float64(3),
+				"mcpServers": map[string]interface{}{
+					"github": map[string]interface{}{
+						"type": "stdio", "command": "npx", "args": []interface{}{"github-mcp@2.0.0"},
+						"env": map[string]interface{}{"GITHUB_TOKEN": "secret"},
+					},
+					"context7": map[string]interface{}{"type": "http", "url": "https://mcp.context7.com/mcp"},
+				},
+			},
+		},
+		{
+			name:          "add project server to a new project",
+			initialConfig: `{"mcpServers": {}}`,
+			upsert: []types.MCPServer{
+				{Name: "serena", TypeStr: "stdio", Command: "uvx", Scope: types.ScopeProject, ProjectPath: "/path/to/project"},
+			},
+			wantConfig: map[string]interface{}{
+				"mcpServers": map[string]interface{}{},
+				"projects": map[string]interface{}{
+					"/path/to/project": map[string]interface{}{
+						"mcpServers": map[string]interface{}{
+							"serena": map[string]interface{}{"type": "stdio", "command": "uvx"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:          "switching to a url drops the command",
+			initialConfig: `{"mcpServers": {"docs": {"type": "stdio", "command": "npx", "args": ["docs-mcp"]}}}`,
+			upsert: []types.MCPServer{
+				{Name: "docs", TypeStr: "http", URL: "https://docs.example.com/mcp", Scope: types.ScopeGlobal},
+			},
+			wantConfig: map[string]interface{}{
+				"mcpServers": map[string]interface{}{
+					"docs": map[string]interface{}{"type": "http", "url": "https://docs.example.com/mcp"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "claude.json")
+			if err := os.WriteFile(configPath, []byte(tt.initialConfig), 0o644); err != nil {
+				t.Fatalf("failed to write initial config: %v", err)
+			}
+
+			if _, err := ApplyChanges(configPath, tt.upsert, tt.remove); err != nil {
+				t.Fatalf("ApplyChanges() unexpected error: %v", err)
+			}
+
+			result, err := os.ReadFile(configPath)
+			if err != nil {
+				t.Fatalf("failed to read result: %v", err)
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(result, &got); err != nil {
+				t.Fatalf("failed to parse result: %v", err)
+			}
+
+			if diff := cmp.Diff(tt.wantConfig, got); diff != "" {
+				t.Errorf("ApplyChanges() result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyChanges_CreatesConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "claude.json")
+
+	upsert := []types.MCPServer{{Name: "context7", TypeStr: "http", URL: "https://mcp.context7.com/mcp", Scope: types.ScopeGlobal}}
+	if _, err := ApplyChanges(configPath, upsert, nil); err != nil {
+		t.Fatalf("ApplyChanges() unexpected error: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if _, ok := cfg.GetServer("context7"); !ok {
+		t.Error("expected context7 in the created config")
+	}
+}
+
+func TestApplyChanges_VSCode(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "mcp.json")
+	initial := `{"inputs": [{"type": "promptString", "id": "token"}], "servers": {"fetch": {"type": "stdio", "command": "uvx"}}}`
+	if err := os.WriteFile(configPath, []byte(initial), 0o644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	upsert := []types.MCPServer{{Name: "context7", TypeStr: "http", URL: "https://mcp.context7.com/mcp", Scope: types.ScopeGlobal, Client: ClientVSCode}}
+	remove := []types.MCPServer{{Name: "fetch", Scope: types.ScopeGlobal, Client: ClientVSCode}}
+	if _, err := ApplyChanges(configPath, upsert, remove); err != nil {
+		t.Fatalf("ApplyChanges() unexpected error: %v", err)
+	}
+
+	result, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read result: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(result, &got); err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	want := map[string]interface{}{
+		"inputs":  []interface{}{map[string]interface{}{"type": "promptString", "id": "token"}},
+		"servers": map[string]interface{}{"context7": map[string]interface{}{"type": "http", "url": "https://mcp.context7.com/mcp"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ApplyChanges() result mismatch (-want +got):\n%s", diff)
+	}
+
+	// A new VS Code file gets a servers object too
+	newPath := filepath.Join(t.TempDir(), "mcp.json")
+	if _, err := ApplyChanges(newPath, upsert, nil); err != nil {
+		t.Fatalf("ApplyChanges() unexpected error: %v", err)
+	}
+	cfg, err := VSCode{}.Load(newPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if _, ok := cfg.GetServer("context7"); !ok {
+		t.Errorf("expected context7 in the created config, got %s", cfg.RawContent())
+	}
+}
+
+func TestApplyChanges_Gemini(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "settings.json")
+	initial := `{"theme": "Default", "mcpServers": {"events": {"url": "http://localhost:8080/sse", "timeout": 30000}}}`
+	if err := os.WriteFile(configPath, []byte(initial), 0o644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	// Gemini CLI has no type: http servers go in httpUrl, sse servers in url
+	upsert := []types.MCPServer{
+		{Name: "events", Type: types.ServerTypeHTTP, URL: "http://localhost:8080/mcp", Scope: types.ScopeGlobal, Client: ClientGemini},
+		{Name: "docs", Type: types.ServerTypeSSE, TypeStr: "sse", URL: "https://docs.example.com/sse", Headers: map[string]string{"X-Key": "k"}, Scope: types.ScopeGlobal, Client: ClientGemini},
+	}
+	if _, err := ApplyChanges(configPath, upsert, nil); err != nil {
+		t.Fatalf("ApplyChanges() unexpected error: %v", err)
+	}
+
+	result, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read result: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(result, &got); err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	want := map[string]interface{}{
+		"theme": "Default",
+		"mcpServers": map[string]interface{}{
+			"events": map[string]interface{}{"httpUrl": "http://localhost:8080/mcp", "timeout": float64(30000)},
+			"docs":   map[string]interface{}{"url": "https://docs.example.com/sse", "headers": map[string]interface{}{"X-Key": "k"}},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ApplyChanges() result mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestApplyChanges_Codex(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	initial := `model = "o3" # default model
+
+# Docs lookups
+[mcp_servers.context7]
+command = "npx"
+args = ["-y", "@upstash/context7-mcp"]
+
+[mcp_servers.puppeteer]
+command = "npx"
+`
+	if err := os.WriteFile(configPath, []byte(initial), 0o644); err != nil {
+		t.Fatalf("failed to write test config: %v", err)
+	}
+
+	upsert := []types.MCPServer{{Name: "figma", Type: types.ServerTypeHTTP, URL: "https://mcp.figma.com/mcp", Scope: types.ScopeGlobal, Client: ClientCodex}}
+	remove := []types.MCPServer{{Name: "context7", Scope: types.ScopeGlobal, Client: ClientCodex}}
+	result, err := ApplyChanges(configPath, upsert, remove)
+	if err != nil {
+		t.Fatalf("ApplyChanges() unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read result: %v", err)
+	}
+	want := `model = "o3" # default model
+
+[mcp_servers.puppeteer]
+command = "npx"
+
+[mcp_servers.figma]
+url = "https://mcp.figma.com/mcp"
+`
+	if diff := cmp.Diff(want, string(content)); diff != "" {
+		t.Errorf("ApplyChanges() result mismatch (-want +got):\n%s", diff)
+	}
+
+	// Undo restores the file's servers
+	if _, conflicts, err := Revert(configPath, result.Changes); err != nil || len(conflicts) != 0 {
+		t.Fatalf("Revert() conflicts = %v, error = %v", conflicts, err)
+	}
+	cfg, err := Codex{}.Load(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	got := make([]string, 0, len(cfg.Servers()))
+	for _, s := range cfg.Servers() {
+		got = append(got, s.Name)
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff([]string{"context7", "puppeteer"}, got); diff != "" {
+		t.Errorf("servers after Revert() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPreviewChanges(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "claude.json")
+	initial := `{
+		"mcpServers": {
+			"github": {"type": "stdio", "command": "npx", "env": {"GITHUB_TOKEN": "secret", "OWNER": "${OWNER}"}},
+			"puppeteer": {"type": "stdio", "command": "npx"}
+		},
+		"projects": {"/work/app": {"mcpServers": {"serena": {"command": "uvx"}}}}
+	}`
+	if err := os.WriteFile(configPath, []byte(initial), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	remove := []types.MCPServer{
+		{Name: "puppeteer", Scope: types.ScopeGlobal},
+		{Name: "missing", Scope: types.ScopeProject, ProjectPath: "/work/app"},
+	}
+	upsert := []types.MCPServer{
+		{Name: "serena", Command: "uvx", Env: map[string]string{"API_KEY": "secret"}, Scope: types.ScopeProject, ProjectPath: "/work/other"},
+	}
+	changes, err := PreviewChanges(configPath, upsert, remove)
+	if err != nil {
+		t.Fatalf("PreviewChanges() unexpected error: %v", err)
+	}
+
+	want := []SubtreeChange{
+		{
+			Path: "mcpServers",
+			Before: []byte(`{
+  "github": {
+    "command": "npx",
+    "env": {
+      "GITHUB_TOKEN": "********",
+      "OWNER": "${OWNER}"
+    },
+    "type": "stdio"
+  },
+  "puppeteer": {
+    "command": "npx",
+    "type": "stdio"
+  }
+}`),
+			After: []byte(`{
+  "github": {
+    "command": "npx",
+    "env": {
+      "GITHUB_TOKEN": "********",
+      "OWNER": "${OWNER}"
+    },
+    "type": "stdio"
+  }
+}`),
+		},
+		{
+			Path: `projects["/work/other"].mcpServers`,
+			After: []byte(`{
+  "serena": {
+    "command": "uvx",
+    "env": {
+      "API_KEY": "********"
+    }
+  }
+}`),
+		},
+	}
+	if diff := cmp.Diff(want, changes); diff != "" {
+		t.Errorf("PreviewChanges() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Nothing is written
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if string(content) != initial {
+		t.Error("PreviewChanges() modified the config")
+	}
+}
+
 func TestAtomicWrite(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "mcp-tidy-test-*")
 	if err != nil {
@@ -286,3 +637,43 @@ func TestAtomicWrite(t *testing.T) {
 		}
 	}
 }
+
+func TestAtomicWrite_Permissions(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		existing os.FileMode // 0 for a new file
+		want     os.FileMode
+	}{
+		{name: "new file", want: 0o600},
+		{name: "private file", existing: 0o600, want: 0o600},
+		{name: "shared file", existing: 0o664, want: 0o664},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".json")
+			if tt.existing != 0 {
+				if err := os.WriteFile(path, []byte(`{}`), tt.existing); err != nil {
+					t.Fatal(err)
+				}
+				// WriteFile's mode is subject to the umask
+				if err := os.Chmod(path, tt.existing); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := atomicWrite(path, []byte(`{"test": "permissions"}`)); err != nil {
+				t.Fatalf("atomicWrite() failed: %v", err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("failed to stat result: %v", err)
+			}
+			if got := info.Mode().Perm(); got != tt.want {
+				t.Errorf("atomicWrite() permissions = %o, want %o", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Package diff produces unified diffs of text.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// op is a single line of an edit script: ' ' keeps, '-' deletes and '+' inserts the line.
+type op struct {
+	kind byte
+	text string
+}
+
+// Unified returns a unified diff of from and to with the given number of
+// context lines, or "" when they are equal. The labels are used in the
+// "---" and "+++" header lines.
+func Unified(fromLabel, toLabel string, from, to []byte, context int) string {
+	ops := editScript(splitLines(string(from)), splitLines(string(to)))
+
+	// Line numbers (0-based) in from and to before each op
+	fromLine := make([]int, len(ops)+1)
+	toLine := make([]int, len(ops)+1)
+	var changed []int
+	for i, o := range ops {
+		fromLine[i+1], toLine[i+1] = fromLine[i], toLine[i]
+		if o.kind != '+' {
+			fromLine[i+1]++
+		}
+		if o.kind != '-' {
+			toLine[i+1]++
+		}
+		if o.kind != ' ' {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromLabel, toLabel)
+	for start := 0; start < len(changed); {
+		// Changes closer than twice the context share a hunk
+		end := start
+		for end+1 < len(changed) && changed[end+1]-changed[end] <= 2*context+1 {
+			end++
+		}
+		first := max(changed[start]-context, 0)
+		last := min(changed[end]+context+1, len(ops))
+
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(fromLine[first], fromLine[last]-fromLine[first]),
+			hunkRange(toLine[first], toLine[last]-toLine[first]))
+		for _, o := range ops[first:last] {
+			b.WriteByte(o.kind)
+			b.WriteString(o.text)
+			b.WriteByte('\n')
+		}
+		start = end + 1
+	}
+	return b.String()
+}
+
+// hunkRange formats the start and length of a hunk. An empty range starts at
+// the line before it, as in GNU diff.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits text into lines without their line endings.
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// editScript returns a shortest edit script turning a into b, based on the
+// longest common subsequence. Deletions come before insertions.
+func editScript(a, b []string) []op {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
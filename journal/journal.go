@@ -0,0 +1,120 @@
+// Package journal records the changes mcp-tidy makes to the config, so they
+// can be listed and undone.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+)
+
+// FileName is the name of the journal file in the mcp-tidy config directory.
+const FileName = "journal.jsonl"
+
+// Entry is one recorded operation.
+type Entry struct {
+	// ID is the 1-based position of the entry in the journal. It is not stored.
+	ID      int                   `json:"-"`
+	Time    time.Time             `json:"time"`
+	Command string                `json:"command"`
+	Config  string                `json:"config"`
+	Backup  string                `json:"backup,omitempty"`
+	Changes []config.ServerChange `json:"changes"`
+	// UndoOf is the ID of the entry this operation undid, if it was an undo.
+	UndoOf int `json:"undoOf,omitempty"`
+}
+
+// DefaultPath returns the default journal path, in the user config directory
+// (e.g. ~/.config/mcp-tidy/journal.jsonl on Linux).
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		homeDir, _ := os.UserHomeDir()
+		dir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(dir, "mcp-tidy", FileName)
+}
+
+// Append adds an entry to the journal, creating it if needed. The journal
+// holds the full previous server definitions, including secrets, so it is
+// only readable by the user.
+func Append(path string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return f.Close()
+}
+
+// Load reads all entries of the journal, oldest first. A missing journal has
+// no entries.
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse journal %s line %d: %w", path, line, err)
+		}
+		entry.ID = len(entries) + 1
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// UndoneBy returns, for every undone entry ID, the ID of the entry that undid it.
+func UndoneBy(entries []Entry) map[int]int {
+	undone := make(map[int]int)
+	for i := range entries {
+		if entries[i].UndoOf > 0 {
+			undone[entries[i].UndoOf] = entries[i].ID
+		}
+	}
+	return undone
+}
+
+// LastUndoable returns the most recent entry that is neither undone nor an
+// undo itself, or nil if there is none.
+func LastUndoable(entries []Entry) *Entry {
+	undone := UndoneBy(entries)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].UndoOf == 0 && undone[entries[i].ID] == 0 {
+			return &entries[i]
+		}
+	}
+	return nil
+}
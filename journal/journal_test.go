@@ -0,0 +1,85 @@
+package journal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/config"
+)
+
+func TestAppendLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp-tidy", FileName)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	entries := []Entry{
+		{
+			Time: now, Command: "mcp-tidy remove puppeteer", Config: "/home/me/.claude.json", Backup: "/home/me/.claude.json.backup.1",
+			Changes: []config.ServerChange{{Name: "puppeteer", Scope: "global", Before: json.RawMessage(`{"command":"npx"}`)}},
+		},
+		{
+			Time: now.Add(time.Minute), Command: "mcp-tidy undo", Config: "/home/me/.claude.json", UndoOf: 1,
+			Changes: []config.ServerChange{{Name: "puppeteer", Scope: "global", After: json.RawMessage(`{"command":"npx"}`)}},
+		},
+	}
+	for i := range entries {
+		if err := Append(path, &entries[i]); err != nil {
+			t.Fatalf("Append() unexpected error: %v", err)
+		}
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	entries[0].ID, entries[1].ID = 1, 2
+	if diff := cmp.Diff(entries, got); diff != "" {
+		t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat journal: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("journal permissions = %o, want 600", perm)
+	}
+}
+
+func TestLoad_NotExist(t *testing.T) {
+	entries, err := Load(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Load() = %v, want no entries", entries)
+	}
+}
+
+func TestLastUndoable(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []Entry
+		want    int
+	}{
+		{name: "empty", want: 0},
+		{name: "last entry", entries: []Entry{{ID: 1}, {ID: 2}}, want: 2},
+		{name: "skips undone and undo entries", entries: []Entry{{ID: 1}, {ID: 2}, {ID: 3, UndoOf: 2}}, want: 1},
+		{name: "everything undone", entries: []Entry{{ID: 1}, {ID: 2, UndoOf: 1}}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := 0
+			if entry := LastUndoable(tt.entries); entry != nil {
+				got = entry.ID
+			}
+			if got != tt.want {
+				t.Errorf("LastUndoable() = #%d, want #%d", got, tt.want)
+			}
+		})
+	}
+}
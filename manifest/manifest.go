@@ -0,0 +1,223 @@
+// Package manifest compares MCP server configurations with a team manifest.
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the default name of a manifest file.
+const FileName = ".mcp-manifest.yaml"
+
+// Server is a server expected by the manifest.
+type Server struct {
+	Name string `yaml:"name"`
+	// Scope is "global" (the default) or "project".
+	Scope string `yaml:"scope,omitempty"`
+	// Project is the project path of a project-scoped server. Relative paths
+	// are resolved against the directory of the manifest.
+	Project string   `yaml:"project,omitempty"`
+	Type    string   `yaml:"type,omitempty"`
+	Command string   `yaml:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty"`
+	URL     string   `yaml:"url,omitempty"`
+}
+
+// Manifest is the expected set of servers.
+type Manifest struct {
+	Servers []Server `yaml:"servers"`
+	// Client names the client whose config the manifest is compared with,
+	// as in types.MCPServer.Client; empty means Claude Code.
+	Client string `yaml:"-"`
+}
+
+// Kind is the kind of difference between the manifest and the config.
+type Kind int
+
+const (
+	// KindMissing is a server in the manifest that is not configured.
+	KindMissing Kind = iota
+	// KindExtra is a configured server that is not in the manifest.
+	KindExtra
+	// KindChanged is a server configured differently than in the manifest.
+	KindChanged
+)
+
+// String returns the string representation of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindMissing:
+		return "missing"
+	case KindExtra:
+		return "extra"
+	case KindChanged:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+// Drift is a single difference between the manifest and the config.
+type Drift struct {
+	Kind Kind
+	// Server is the server as the manifest expects it (missing, changed)
+	// or as it is configured (extra).
+	Server types.MCPServer
+	// Changes describes the changed fields of a changed server.
+	Changes []string
+}
+
+// Load reads and validates a manifest file. Relative project paths are
+// resolved against the manifest's directory.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	m := &Manifest{}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	baseDir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	if err := m.normalize(baseDir); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// normalize fills in defaults, resolves project paths and validates entries.
+func (m *Manifest) normalize(baseDir string) error {
+	seen := make(map[string]bool)
+	for i := range m.Servers {
+		s := &m.Servers[i]
+		if s.Name == "" {
+			return fmt.Errorf("servers[%d]: name is required", i)
+		}
+		if s.Scope == "" {
+			s.Scope = types.ScopeGlobal.String()
+			if s.Project != "" {
+				s.Scope = types.ScopeProject.String()
+			}
+		}
+
+		switch s.Scope {
+		case types.ScopeGlobal.String():
+			if s.Project != "" {
+				return fmt.Errorf("servers[%d] (%s): project is only allowed for project scope", i, s.Name)
+			}
+		case types.ScopeProject.String():
+			if s.Project == "" {
+				return fmt.Errorf("servers[%d] (%s): project is required for project scope", i, s.Name)
+			}
+			if !filepath.IsAbs(s.Project) {
+				s.Project = filepath.Join(baseDir, s.Project)
+			}
+			s.Project = filepath.Clean(s.Project)
+		default:
+			return fmt.Errorf("servers[%d] (%s): invalid scope %q (expected global or project)", i, s.Name, s.Scope)
+		}
+
+		if s.Command == "" && s.URL == "" {
+			return fmt.Errorf("servers[%d] (%s): command or url is required", i, s.Name)
+		}
+		if s.Type == "" {
+			s.Type = types.ServerTypeStdio.String()
+			if s.URL != "" {
+				s.Type = types.ServerTypeHTTP.String()
+			}
+		}
+
+		server := s.toMCPServer()
+		key := server.Key()
+		if seen[key] {
+			return fmt.Errorf("servers[%d]: duplicate server %s", i, s.Name)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// toMCPServer converts a manifest entry into a server configuration.
+func (s *Server) toMCPServer() types.MCPServer {
+	server := types.MCPServer{
+		Name:    s.Name,
+		Type:    types.ParseServerType(s.Type),
+		TypeStr: s.Type,
+		Command: s.Command,
+		Args:    s.Args,
+		URL:     s.URL,
+		Scope:   types.ScopeGlobal,
+	}
+	if s.Scope == types.ScopeProject.String() {
+		server.Scope = types.ScopeProject
+		server.ProjectPath = s.Project
+	}
+	return server
+}
+
+// Expected returns the servers expected by the manifest.
+func (m *Manifest) Expected() []types.MCPServer {
+	servers := make([]types.MCPServer, 0, len(m.Servers))
+	for i := range m.Servers {
+		server := m.Servers[i].toMCPServer()
+		server.Client = m.Client
+		servers = append(servers, server)
+	}
+	return servers
+}
+
+// Compare reports how the configured servers differ from the manifest.
+// Global servers are always compared; project servers only for the projects
+// the manifest lists, so unrelated projects are not reported as extra.
+// The result is sorted by server key.
+func (m *Manifest) Compare(configured []types.MCPServer) []Drift {
+	expected := make(map[string]types.MCPServer, len(m.Servers))
+	projects := make(map[string]bool)
+	for _, s := range m.Expected() {
+		expected[s.Key()] = s
+		if s.Scope == types.ScopeProject {
+			projects[s.ProjectPath] = true
+		}
+	}
+
+	var drifts []Drift
+	actual := make(map[string]bool, len(configured))
+	for i := range configured {
+		server := configured[i]
+		if server.Scope == types.ScopeProject && !projects[server.ProjectPath] {
+			continue
+		}
+		key := server.Key()
+		actual[key] = true
+
+		want, ok := expected[key]
+		if !ok {
+			drifts = append(drifts, Drift{Kind: KindExtra, Server: server})
+			continue
+		}
+		if changes := server.ConnectionChanges(&want); len(changes) > 0 {
+			drifts = append(drifts, Drift{Kind: KindChanged, Server: want, Changes: changes})
+		}
+	}
+
+	for key, want := range expected {
+		if !actual[key] {
+			drifts = append(drifts, Drift{Kind: KindMissing, Server: want})
+		}
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Server.Key() < drifts[j].Server.Key()
+	})
+	return drifts
+}
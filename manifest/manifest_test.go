@@ -0,0 +1,112 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeManifest(t, `servers:
+  - name: context7
+    url: https://mcp.context7.com/mcp
+  - name: serena
+    project: app
+    command: uvx
+    args: [serena]
+`)
+
+	m, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	want := []types.MCPServer{
+		{Name: "context7", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://mcp.context7.com/mcp", Scope: types.ScopeGlobal},
+		{
+			Name: "serena", Type: types.ServerTypeStdio, TypeStr: "stdio", Command: "uvx", Args: []string{"serena"},
+			Scope: types.ScopeProject, ProjectPath: filepath.Join(filepath.Dir(path), "app"),
+		},
+	}
+	if diff := cmp.Diff(want, m.Expected()); diff != "" {
+		t.Errorf("Expected() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "missing name", content: "servers:\n  - command: npx\n"},
+		{name: "missing command and url", content: "servers:\n  - name: a\n"},
+		{name: "invalid scope", content: "servers:\n  - name: a\n    command: npx\n    scope: user\n"},
+		{name: "project scope without project", content: "servers:\n  - name: a\n    command: npx\n    scope: project\n"},
+		{name: "global scope with project", content: "servers:\n  - name: a\n    command: npx\n    scope: global\n    project: /p\n"},
+		{name: "duplicate", content: "servers:\n  - name: a\n    command: npx\n  - name: a\n    command: uvx\n"},
+		{name: "invalid yaml", content: "servers: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(writeManifest(t, tt.content)); err == nil {
+				t.Error("Load() expected an error")
+			}
+		})
+	}
+}
+
+func TestManifest_Compare(t *testing.T) {
+	m := &Manifest{Servers: []Server{
+		{Name: "context7", Scope: "global", Type: "http", URL: "https://mcp.context7.com/mcp"},
+		{Name: "github", Scope: "global", Type: "stdio", Command: "npx", Args: []string{"github-mcp@2.0.0"}},
+		{Name: "serena", Scope: "project", Project: "/work/app", Type: "stdio", Command: "uvx", Args: []string{"serena"}},
+	}}
+
+	configured := []types.MCPServer{
+		{Name: "github", TypeStr: "stdio", Command: "npx", Args: []string{"github-mcp@1.0.0"}, Env: map[string]string{"TOKEN": "x"}, Scope: types.ScopeGlobal},
+		{Name: "puppeteer", TypeStr: "stdio", Command: "npx", Scope: types.ScopeGlobal},
+		{Name: "serena", Command: "uvx", Args: []string{"serena"}, Scope: types.ScopeProject, ProjectPath: "/work/app"},
+		{Name: "other", Command: "npx", Scope: types.ScopeProject, ProjectPath: "/work/unrelated"},
+	}
+
+	got := m.Compare(configured)
+
+	want := []Drift{
+		{Kind: KindMissing, Server: types.MCPServer{Name: "context7", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://mcp.context7.com/mcp", Scope: types.ScopeGlobal}},
+		{
+			Kind:    KindChanged,
+			Server:  types.MCPServer{Name: "github", TypeStr: "stdio", Command: "npx", Args: []string{"github-mcp@2.0.0"}, Scope: types.ScopeGlobal},
+			Changes: []string{"args: [github-mcp@1.0.0] → [github-mcp@2.0.0]"},
+		},
+		{Kind: KindExtra, Server: configured[1]},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Compare() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestManifest_Compare_InSync(t *testing.T) {
+	m := &Manifest{Servers: []Server{
+		{Name: "context7", Scope: "global", Type: "http", URL: "https://mcp.context7.com/mcp"},
+	}}
+	configured := []types.MCPServer{
+		{Name: "context7", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://mcp.context7.com/mcp", Scope: types.ScopeGlobal},
+	}
+
+	if got := m.Compare(configured); len(got) != 0 {
+		t.Errorf("Compare() = %v, want no drift", got)
+	}
+}
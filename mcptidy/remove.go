@@ -0,0 +1,224 @@
+package mcptidy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/selection"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// errNothingToRemove is returned when a plan has no servers to remove.
+var errNothingToRemove = errors.New("no servers to remove")
+
+// RemovalOptions selects the servers PlanRemoval considers and removes.
+type RemovalOptions struct {
+	// Scope keeps the servers of a scope: "global" or "project". Empty keeps both.
+	Scope string
+	// Project keeps the servers of a project path.
+	Project string
+	// Unused keeps the servers flagged as unused.
+	Unused bool
+	// Selectors pick the servers to remove from the candidates, in the
+	// syntax of selection.ParseSelectors: names, globs, 'unused', 'global',
+	// 'project:/path' and exclusions (!name). Numbers of the interactive
+	// list are rejected.
+	Selectors []string
+	// All removes every candidate when there are no selectors.
+	All bool
+}
+
+// EmptyReason tells why a removal plan has no candidates.
+type EmptyReason int
+
+const (
+	// NotEmpty means the plan has candidates.
+	NotEmpty EmptyReason = iota
+	// NoServers means no servers but managed ones are configured.
+	NoServers
+	// NoneInScope means no servers are configured in the scope and project.
+	NoneInScope
+	// AllProtected means every server in the scope is protected by the policy.
+	AllProtected
+	// NoneUnused means no server in the scope is flagged as unused.
+	NoneUnused
+)
+
+// String returns the string representation of the reason, as in JSON output.
+func (r EmptyReason) String() string {
+	switch r {
+	case NoServers:
+		return "no-servers"
+	case NoneInScope:
+		return "none-in-scope"
+	case AllProtected:
+		return "all-protected"
+	case NoneUnused:
+		return "none-unused"
+	default:
+		return "not-empty"
+	}
+}
+
+// RemovalPlan is the servers a removal would take out of a source's config.
+type RemovalPlan struct {
+	Source *Source
+	// Managed and Protected are the servers skipped because they are
+	// deployed through managed-mcp.json or protected by the policy.
+	Managed   []types.MCPServer
+	Protected []types.MCPServer
+	// Candidates is the servers that may be removed, and Empty tells why
+	// there are none.
+	Candidates []types.MCPServer
+	Empty      EmptyReason
+	// Remove is the servers to remove: those picked by the selectors, or
+	// every candidate with RemovalOptions.All. Callers that let the user
+	// pick from the candidates set it themselves.
+	Remove []types.MCPServer
+}
+
+// PlanRemoval decides which of the inventory's servers can be removed and
+// picks those the options select. Managed servers can't be removed and
+// protected ones never are.
+func PlanRemoval(ctx context.Context, inv *Inventory, usage *Usage, opts RemovalOptions) (*RemovalPlan, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	switch opts.Scope {
+	case "", types.ScopeGlobal.String(), types.ScopeProject.String():
+	default:
+		return nil, fmt.Errorf("invalid scope %q (expected global or project)", opts.Scope)
+	}
+	if opts.Unused && !inv.Source.Client.HasTranscripts() {
+		return nil, fmt.Errorf("%s keeps no transcripts, so its unused servers can't be found", inv.Source.Client.Title())
+	}
+
+	plan := &RemovalPlan{Source: inv.Source}
+	plan.findCandidates(inv.Servers, usage.Verdicts, opts)
+	if plan.Empty != NotEmpty {
+		return plan, nil
+	}
+
+	switch {
+	case len(opts.Selectors) > 0:
+		selected, err := selection.ParseSelectors(strings.Join(opts.Selectors, " "), plan.Candidates, usage.StatsByName(), usage.Verdicts)
+		if err != nil {
+			return nil, err
+		}
+		plan.Remove = make([]types.MCPServer, 0, len(selected))
+		for _, idx := range selected {
+			plan.Remove = append(plan.Remove, plan.Candidates[idx])
+		}
+	case opts.All:
+		plan.Remove = plan.Candidates
+	}
+	return plan, nil
+}
+
+// findCandidates sets the managed, protected and candidate servers of the
+// plan, or why there are no candidates.
+func (p *RemovalPlan) findCandidates(all []types.MCPServer, verdicts map[string]types.UnusedVerdict, opts RemovalOptions) {
+	var servers []types.MCPServer
+	for i := range all {
+		if all[i].Scope == types.ScopeManaged {
+			p.Managed = append(p.Managed, all[i])
+			continue
+		}
+		servers = append(servers, all[i])
+	}
+	if len(servers) == 0 {
+		p.Empty = NoServers
+		return
+	}
+
+	servers = filterServersByScope(servers, opts.Scope, opts.Project)
+	if len(servers) == 0 {
+		p.Empty = NoneInScope
+		return
+	}
+
+	var unprotected []types.MCPServer
+	for i := range servers {
+		if verdicts[servers[i].Key()].Protected {
+			p.Protected = append(p.Protected, servers[i])
+			continue
+		}
+		unprotected = append(unprotected, servers[i])
+	}
+	if len(unprotected) == 0 {
+		p.Empty = AllProtected
+		return
+	}
+
+	p.Candidates = filterServersForRemoval(unprotected, verdicts, opts.Unused)
+	if opts.Unused && len(p.Candidates) == 0 {
+		p.Empty = NoneUnused
+	}
+}
+
+// filterServersByScope keeps the servers in the given scope and project.
+// Empty scope and project keep every server.
+func filterServersByScope(servers []types.MCPServer, scope, project string) []types.MCPServer {
+	if project != "" {
+		if abs, err := filepath.Abs(project); err == nil {
+			project = abs
+		}
+	}
+
+	var result []types.MCPServer
+	for i := range servers {
+		if scope != "" && servers[i].Scope.String() != scope {
+			continue
+		}
+		if project != "" && (servers[i].Scope != types.ScopeProject || servers[i].ProjectPath != project) {
+			continue
+		}
+		result = append(result, servers[i])
+	}
+	return result
+}
+
+// filterServersForRemoval returns the servers that may be removed: with
+// unused, only those flagged as unused, or nil if there are none.
+func filterServersForRemoval(servers []types.MCPServer, verdicts map[string]types.UnusedVerdict, unused bool) []types.MCPServer {
+	if !unused {
+		return servers
+	}
+
+	var flagged []types.MCPServer
+	for i := range servers {
+		if verdicts[servers[i].Key()].Flagged {
+			flagged = append(flagged, servers[i])
+		}
+	}
+	return flagged
+}
+
+// PreviewRemoval returns the objects of the config that applying the plan
+// would change, without writing anything.
+func PreviewRemoval(ctx context.Context, plan *RemovalPlan) ([]config.SubtreeChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(plan.Remove) == 0 {
+		return nil, errNothingToRemove
+	}
+	return config.PreviewChanges(plan.Source.ConfigPath, nil, plan.Remove)
+}
+
+// ApplyRemoval removes the plan's servers from the source's config, taking
+// a backup first. The result lists the changes, which can be recorded in
+// the journal so they can be undone.
+func ApplyRemoval(ctx context.Context, plan *RemovalPlan) (*config.WriteResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(plan.Remove) == 0 {
+		return nil, errNothingToRemove
+	}
+	return config.RemoveServers(plan.Source.ConfigPath, plan.Remove)
+}
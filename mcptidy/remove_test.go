@@ -0,0 +1,316 @@
+package mcptidy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestPlanRemoval(t *testing.T) {
+	inv := &Inventory{
+		Source: &Source{Client: config.ClaudeCode{}},
+		Servers: []types.MCPServer{
+			{Name: "context7", Scope: types.ScopeGlobal},
+			{Name: "puppeteer", Scope: types.ScopeGlobal},
+			{Name: "github", Scope: types.ScopeGlobal},
+			{Name: "puppeteer-extra", Scope: types.ScopeProject, ProjectPath: "/project"},
+			{Name: "corp-search", Scope: types.ScopeManaged},
+		},
+	}
+	usage := &Usage{
+		Stats: []types.ServerStats{{Name: "context7", Calls: 100, LastUsed: time.Now()}},
+		Verdicts: map[string]types.UnusedVerdict{
+			"global:context7":                  {Reason: "100 calls in 30d"},
+			"global:puppeteer":                 {Flagged: true, Reason: "no calls in 30d"},
+			"global:github":                    {Protected: true, Reason: "protected by policy (github)"},
+			"project:/project:puppeteer-extra": {Flagged: true, Reason: "no calls in 30d"},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		inv            *Inventory
+		opts           RemovalOptions
+		wantCandidates []string
+		wantRemove     []string
+		wantProtected  []string
+		wantEmpty      EmptyReason
+		wantErr        bool
+	}{
+		{
+			name:           "every unprotected server is a candidate",
+			wantCandidates: []string{"context7", "puppeteer", "puppeteer-extra"},
+			wantProtected:  []string{"github"},
+		},
+		{
+			name:           "by scope",
+			opts:           RemovalOptions{Scope: "project"},
+			wantCandidates: []string{"puppeteer-extra"},
+		},
+		{
+			// context7 is used, so --all-unused leaves it out
+			name:           "all unused",
+			opts:           RemovalOptions{Unused: true, All: true},
+			wantCandidates: []string{"puppeteer", "puppeteer-extra"},
+			wantRemove:     []string{"puppeteer", "puppeteer-extra"},
+			wantProtected:  []string{"github"},
+		},
+		{
+			name:           "selectors",
+			opts:           RemovalOptions{Selectors: []string{"puppeteer*", "!puppeteer-extra"}},
+			wantCandidates: []string{"context7", "puppeteer", "puppeteer-extra"},
+			wantRemove:     []string{"puppeteer"},
+			wantProtected:  []string{"github"},
+		},
+		{
+			name:      "unknown project",
+			opts:      RemovalOptions{Project: "/nowhere"},
+			wantEmpty: NoneInScope,
+		},
+		{
+			name:          "all protected",
+			inv:           &Inventory{Source: inv.Source, Servers: inv.Servers[2:3]},
+			wantProtected: []string{"github"},
+			wantEmpty:     AllProtected,
+		},
+		{
+			name:      "nothing unused",
+			opts:      RemovalOptions{Scope: "global", Unused: true},
+			inv:       &Inventory{Source: inv.Source, Servers: inv.Servers[:1]},
+			wantEmpty: NoneUnused,
+		},
+		{
+			name:      "only managed servers",
+			inv:       &Inventory{Source: inv.Source, Servers: inv.Servers[4:]},
+			wantEmpty: NoServers,
+		},
+		{name: "unknown name is an error", opts: RemovalOptions{Selectors: []string{"nope"}}, wantErr: true},
+		// Nobody saw a numbered list, so a number could pick any server
+		{name: "numbers are an error", opts: RemovalOptions{Selectors: []string{"2"}}, wantErr: true},
+		{name: "ranges are an error", opts: RemovalOptions{Selectors: []string{"all", "!1-2"}}, wantErr: true},
+		{name: "invalid scope", opts: RemovalOptions{Scope: "local"}, wantErr: true},
+		{
+			name:    "unused of a client without transcripts",
+			inv:     &Inventory{Source: &Source{Client: config.Cursor{}}},
+			opts:    RemovalOptions{Unused: true},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := inv
+			if tt.inv != nil {
+				in = tt.inv
+			}
+
+			plan, err := PlanRemoval(context.Background(), in, usage, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PlanRemoval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if diff := cmp.Diff(tt.wantCandidates, names(plan.Candidates)); diff != "" {
+				t.Errorf("PlanRemoval() candidates mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantRemove, names(plan.Remove)); diff != "" {
+				t.Errorf("PlanRemoval() remove mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantProtected, names(plan.Protected)); diff != "" {
+				t.Errorf("PlanRemoval() protected mismatch (-want +got):\n%s", diff)
+			}
+			if plan.Empty != tt.wantEmpty {
+				t.Errorf("PlanRemoval() empty = %v, want %v", plan.Empty, tt.wantEmpty)
+			}
+			if tt.opts.Unused {
+				for i := range plan.Remove {
+					if !usage.Verdicts[plan.Remove[i].Key()].Flagged {
+						t.Errorf("PlanRemoval() with Unused removes %s, which is not unused", plan.Remove[i].Name)
+					}
+				}
+			}
+			if in != inv {
+				return
+			}
+			if diff := cmp.Diff([]string{"corp-search"}, names(plan.Managed)); diff != "" {
+				t.Errorf("PlanRemoval() managed mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterServersByScope(t *testing.T) {
+	servers := []types.MCPServer{
+		{Name: "context7", Scope: types.ScopeGlobal},
+		{Name: "serena", Scope: types.ScopeProject, ProjectPath: "/project-a"},
+		{Name: "puppeteer", Scope: types.ScopeProject, ProjectPath: "/project-b"},
+	}
+
+	tests := []struct {
+		name      string
+		scope     string
+		project   string
+		wantNames []string
+	}{
+		{name: "no filter", wantNames: []string{"context7", "serena", "puppeteer"}},
+		{name: "global scope", scope: "global", wantNames: []string{"context7"}},
+		{name: "project scope", scope: "project", wantNames: []string{"serena", "puppeteer"}},
+		{name: "single project", project: "/project-b", wantNames: []string{"puppeteer"}},
+		{name: "unknown project", project: "/nowhere", wantNames: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.wantNames, names(filterServersByScope(servers, tt.scope, tt.project))); diff != "" {
+				t.Errorf("filterServersByScope() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyRemoval(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".claude.json")
+	content, err := os.ReadFile("../testdata/claude.json")
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+	if err := os.WriteFile(configPath, content, 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	src := &Source{Client: config.ClaudeCode{}, ConfigPath: configPath}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	plan := &RemovalPlan{Source: src, Remove: cfg.Servers()[:1]}
+
+	changes, err := PreviewRemoval(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("PreviewRemoval() error = %v", err)
+	}
+	if len(changes) != 1 {
+		t.Errorf("PreviewRemoval() returned %d changes, want 1", len(changes))
+	}
+
+	result, err := ApplyRemoval(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("ApplyRemoval() error = %v", err)
+	}
+	if len(result.Changes) != 1 || result.Backup == "" {
+		t.Errorf("ApplyRemoval() = %+v, want one change and a backup", result)
+	}
+
+	after, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("failed to reload config: %v", err)
+	}
+	if len(after.Servers()) != len(cfg.Servers())-1 {
+		t.Errorf("got %d servers after removal, want %d", len(after.Servers()), len(cfg.Servers())-1)
+	}
+
+	if _, err := ApplyRemoval(context.Background(), &RemovalPlan{Source: src}); err == nil {
+		t.Error("ApplyRemoval() of an empty plan error = nil, want an error")
+	}
+}
+
+// names returns the names of the servers.
+func names(servers []types.MCPServer) []string {
+	var result []string
+	for i := range servers {
+		result = append(result, servers[i].Name)
+	}
+	return result
+}
+
+func TestFilterServersForRemoval(t *testing.T) {
+	tests := []struct {
+		name         string
+		servers      []types.MCPServer
+		verdicts     map[string]types.UnusedVerdict
+		removeUnused bool
+		wantNames    []string
+	}{
+		{
+			name: "returns all servers when removeUnused is false",
+			servers: []types.MCPServer{
+				{Name: "used", Scope: types.ScopeGlobal},
+				{Name: "unused", Scope: types.ScopeGlobal},
+			},
+			verdicts: map[string]types.UnusedVerdict{
+				"global:unused": {Flagged: true},
+			},
+			removeUnused: false,
+			wantNames:    []string{"used", "unused"},
+		},
+		{
+			name: "filters only flagged servers when removeUnused is true",
+			servers: []types.MCPServer{
+				{Name: "used", Scope: types.ScopeGlobal},
+				{Name: "unused-no-calls", Scope: types.ScopeGlobal},
+				{Name: "below-minimum", Scope: types.ScopeProject, ProjectPath: "/project"},
+			},
+			verdicts: map[string]types.UnusedVerdict{
+				"global:used":                    {Reason: "100 calls in 30d"},
+				"global:unused-no-calls":         {Flagged: true, Reason: "no calls in 30d"},
+				"project:/project:below-minimum": {Flagged: true, Reason: "2 calls in 30d, below minimum of 5"},
+			},
+			removeUnused: true,
+			wantNames:    []string{"unused-no-calls", "below-minimum"},
+		},
+		{
+			name: "allowed servers are not flagged",
+			servers: []types.MCPServer{
+				{Name: "rarely-used", Scope: types.ScopeGlobal},
+				{Name: "old", Scope: types.ScopeGlobal},
+			},
+			verdicts: map[string]types.UnusedVerdict{
+				"global:rarely-used": {Reason: "allowed by policy (rarely-*)"},
+				"global:old":         {Flagged: true, Reason: "no calls in 30d"},
+			},
+			removeUnused: true,
+			wantNames:    []string{"old"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := filterServersForRemoval(tt.servers, tt.verdicts, tt.removeUnused)
+
+			var gotNames []string
+			for _, s := range result {
+				gotNames = append(gotNames, s.Name)
+			}
+			if diff := cmp.Diff(tt.wantNames, gotNames); diff != "" {
+				t.Errorf("filterServersForRemoval() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterServersForRemoval_NoUnusedServers(t *testing.T) {
+	servers := []types.MCPServer{
+		{Name: "active1", Scope: types.ScopeGlobal},
+		{Name: "active2", Scope: types.ScopeProject, ProjectPath: "/project"},
+	}
+
+	verdicts := map[string]types.UnusedVerdict{
+		"global:active1":           {Reason: "100 calls in 30d"},
+		"project:/project:active2": {Reason: "50 calls in 30d"},
+	}
+
+	result := filterServersForRemoval(servers, verdicts, true)
+
+	// Should return nil when no unused servers
+	if result != nil {
+		t.Errorf("expected nil when no unused servers, got %d servers", len(result))
+	}
+}
@@ -0,0 +1,198 @@
+package mcptidy
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// Sort orders accepted by Report.Sort.
+const (
+	SortByCalls    = "calls"
+	SortByName     = "name"
+	SortByLastUsed = "last-used"
+)
+
+// Report joins the configured servers with their usage.
+type Report struct {
+	// Stats holds one entry per server name: every server that was called,
+	// and every configured server whose usage can be measured, with 0 calls
+	// if it wasn't called.
+	Stats []ServerUsage
+	// Servers holds one entry per configured server.
+	Servers    []ServerVerdict
+	Categories Categories
+	TotalCalls int
+	// Period is the period measured; 0 means all time.
+	Period      time.Duration
+	PeriodLabel string
+}
+
+// ServerUsage is the usage of a server name.
+type ServerUsage struct {
+	types.ServerStats
+	// Unused reports whether every server of this name is flagged as
+	// unused, or, for a name that isn't configured, whether it wasn't
+	// called in the period.
+	Unused   bool
+	Category types.UsageCategory
+}
+
+// ServerVerdict is a configured server and why it was or wasn't flagged as unused.
+type ServerVerdict struct {
+	Server  types.MCPServer
+	Verdict types.UnusedVerdict
+}
+
+// Categories counts the server names in each usage category, and the
+// configured servers whose usage is unavailable.
+type Categories struct {
+	Used          int `json:"used"`
+	Unused        int `json:"unused"`
+	NotConfigured int `json:"notConfigured"`
+	Unavailable   int `json:"unavailable"`
+}
+
+// Join assigns each server name of the usage and the configured servers to a
+// usage category.
+// A name configured in several scopes counts as unused only if every one is flagged.
+// Servers whose usage is unavailable are only counted as such, as their calls
+// can't be told apart from calls to a Claude Code server of the same name.
+func Join(servers []types.MCPServer, usage *Usage) *Report {
+	stats := mergeConfiguredServers(append([]types.ServerStats{}, usage.Stats...), measurableServers(servers))
+	report := &Report{
+		Stats:       make([]ServerUsage, len(stats)),
+		Servers:     make([]ServerVerdict, 0, len(servers)),
+		Period:      usage.Period,
+		PeriodLabel: usage.PeriodLabel,
+	}
+
+	statsMap := usage.StatsByName()
+	configured := make(map[string]bool, len(servers))
+	used := make(map[string]bool, len(servers))
+	for i := range servers {
+		verdict := usage.Verdict(&servers[i], statsMap[servers[i].Name])
+		switch {
+		case verdict.Unavailable:
+			report.Categories.Unavailable++
+		case !verdict.Flagged:
+			configured[servers[i].Name] = true
+			used[servers[i].Name] = true
+		default:
+			configured[servers[i].Name] = true
+		}
+		report.Servers = append(report.Servers, ServerVerdict{Server: servers[i], Verdict: verdict})
+	}
+
+	for i, s := range stats {
+		report.TotalCalls += s.Calls
+
+		category := types.CategoryNotConfigured
+		unused := s.IsUnused(usage.Period)
+		if configured[s.Name] {
+			category = types.CategoryUnused
+			unused = !used[s.Name]
+			if used[s.Name] {
+				category = types.CategoryUsed
+			}
+		}
+
+		switch category {
+		case types.CategoryUsed:
+			report.Categories.Used++
+		case types.CategoryUnused:
+			report.Categories.Unused++
+		case types.CategoryNotConfigured:
+			report.Categories.NotConfigured++
+		}
+
+		report.Stats[i] = ServerUsage{ServerStats: s, Unused: unused, Category: category}
+	}
+
+	return report
+}
+
+// mergeConfiguredServers adds configured servers that don't appear in stats.
+// Servers that appear in stats but not in the config are kept, so the result
+// covers every usage category (see types.UsageCategory).
+func mergeConfiguredServers(stats []types.ServerStats, servers []types.MCPServer) []types.ServerStats {
+	// Create a map of existing stats by name
+	statsMap := make(map[string]bool)
+	for _, s := range stats {
+		statsMap[s.Name] = true
+	}
+
+	// Add configured servers that don't have stats
+	for i := range servers {
+		if !statsMap[servers[i].Name] {
+			stats = append(stats, types.ServerStats{
+				Name:  servers[i].Name,
+				Calls: 0,
+				// LastUsed is zero value (never used)
+			})
+			statsMap[servers[i].Name] = true // prevent duplicates
+		}
+	}
+
+	return stats
+}
+
+// Sort orders the stats by SortByCalls (the default), SortByName or
+// SortByLastUsed.
+func (r *Report) Sort(by string) {
+	byName := make(map[string]ServerUsage, len(r.Stats))
+	for _, s := range r.Stats {
+		byName[s.Name] = s
+	}
+	stats := r.ServerStats()
+	sortStats(stats, by)
+	for i := range stats {
+		r.Stats[i] = byName[stats[i].Name]
+	}
+}
+
+// sortStats orders stats as Report.Sort does.
+func sortStats(stats []types.ServerStats, by string) {
+	switch by {
+	case SortByName:
+		sort.Slice(stats, func(i, j int) bool {
+			return stats[i].Name < stats[j].Name
+		})
+	case SortByLastUsed:
+		sort.Slice(stats, func(i, j int) bool {
+			return stats[i].LastUsed.After(stats[j].LastUsed)
+		})
+	default:
+		sort.Slice(stats, func(i, j int) bool {
+			return stats[i].Calls > stats[j].Calls
+		})
+	}
+}
+
+// ServerStats returns the stats without their categories, in their order.
+func (r *Report) ServerStats() []types.ServerStats {
+	stats := make([]types.ServerStats, len(r.Stats))
+	for i := range r.Stats {
+		stats[i] = r.Stats[i].ServerStats
+	}
+	return stats
+}
+
+// StatsByName returns the stats keyed by server name.
+func (r *Report) StatsByName() map[string]types.ServerStats {
+	m := make(map[string]types.ServerStats, len(r.Stats))
+	for i := range r.Stats {
+		m[r.Stats[i].Name] = r.Stats[i].ServerStats
+	}
+	return m
+}
+
+// Verdicts returns the verdicts of the configured servers keyed by MCPServer.Key().
+func (r *Report) Verdicts() map[string]types.UnusedVerdict {
+	m := make(map[string]types.UnusedVerdict, len(r.Servers))
+	for i := range r.Servers {
+		m[r.Servers[i].Server.Key()] = r.Servers[i].Verdict
+	}
+	return m
+}
@@ -0,0 +1,239 @@
+package mcptidy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestJoin(t *testing.T) {
+	now := time.Now()
+	desktop := types.MCPServer{Name: "serena", Scope: types.ScopeGlobal, Client: config.ClientClaudeDesktop}
+	servers := []types.MCPServer{
+		{Name: "context7", Scope: types.ScopeGlobal},
+		{Name: "puppeteer", Scope: types.ScopeGlobal},
+		{Name: "puppeteer", Scope: types.ScopeProject, ProjectPath: "/project"},
+		{Name: "github", Scope: types.ScopeGlobal},
+		desktop,
+	}
+	usage := &Usage{
+		Stats: []types.ServerStats{
+			{Name: "context7", Calls: 100, LastUsed: now},
+			{Name: "plugin-server", Calls: 7, LastUsed: now},
+		},
+		Verdicts: map[string]types.UnusedVerdict{
+			"global:context7":            {Reason: "100 calls in 30d"},
+			"global:puppeteer":           {Flagged: true, Reason: "no calls in 30d"},
+			"project:/project:puppeteer": {Reason: "allowed by policy (puppeteer)"},
+			desktop.Key():                {Unavailable: true, Reason: "usage unavailable"},
+		},
+		Period:      30 * 24 * time.Hour,
+		PeriodLabel: "30d",
+	}
+
+	report := Join(servers, usage)
+
+	// github has no verdict, so it falls back to the period check; the
+	// Desktop server's usage can't be measured, so it gets no stats
+	wantStats := []ServerUsage{
+		{ServerStats: types.ServerStats{Name: "context7", Calls: 100, LastUsed: now}, Category: types.CategoryUsed},
+		{ServerStats: types.ServerStats{Name: "plugin-server", Calls: 7, LastUsed: now}, Category: types.CategoryNotConfigured},
+		{ServerStats: types.ServerStats{Name: "puppeteer"}, Category: types.CategoryUsed},
+		{ServerStats: types.ServerStats{Name: "github"}, Unused: true, Category: types.CategoryUnused},
+	}
+	if diff := cmp.Diff(wantStats, report.Stats); diff != "" {
+		t.Errorf("Join() stats mismatch (-want +got):\n%s", diff)
+	}
+
+	wantCategories := Categories{Used: 2, Unused: 1, NotConfigured: 1, Unavailable: 1}
+	if diff := cmp.Diff(wantCategories, report.Categories); diff != "" {
+		t.Errorf("Join() categories mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(107, report.TotalCalls); diff != "" {
+		t.Errorf("Join() TotalCalls mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(types.UnusedVerdict{Flagged: true}, report.Verdicts()["global:github"]); diff != "" {
+		t.Errorf("Join() github verdict mismatch (-want +got):\n%s", diff)
+	}
+	if len(report.Servers) != len(servers) {
+		t.Errorf("Join() returned %d server verdicts, want %d", len(report.Servers), len(servers))
+	}
+}
+
+func TestReport_Sort(t *testing.T) {
+	now := time.Now()
+	stats := []ServerUsage{
+		{ServerStats: types.ServerStats{Name: "zebra", Calls: 10, LastUsed: now.Add(-1 * time.Hour)}},
+		{ServerStats: types.ServerStats{Name: "alpha", Calls: 100, LastUsed: now.Add(-24 * time.Hour)}},
+		{ServerStats: types.ServerStats{Name: "beta", Calls: 50, LastUsed: now}},
+	}
+
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		{by: SortByCalls, want: []string{"alpha", "beta", "zebra"}},
+		{by: "", want: []string{"alpha", "beta", "zebra"}},
+		{by: SortByName, want: []string{"alpha", "beta", "zebra"}},
+		{by: SortByLastUsed, want: []string{"beta", "zebra", "alpha"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			report := &Report{Stats: append([]ServerUsage{}, stats...)}
+			report.Sort(tt.by)
+
+			var got []string
+			for _, s := range report.ServerStats() {
+				got = append(got, s.Name)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Sort(%q) mismatch (-want +got):\n%s", tt.by, diff)
+			}
+		})
+	}
+}
+
+func TestSortStats(t *testing.T) {
+	stats := []types.ServerStats{
+		{Name: "zebra", Calls: 10, LastUsed: time.Now().Add(-1 * time.Hour)},
+		{Name: "alpha", Calls: 100, LastUsed: time.Now().Add(-24 * time.Hour)},
+		{Name: "beta", Calls: 50, LastUsed: time.Now()},
+	}
+
+	tests := []struct {
+		name      string
+		sortBy    string
+		wantFirst string
+	}{
+		{"sort by calls (default)", "calls", "alpha"},
+		{"sort by name", "name", "alpha"},
+		{"sort by last-used", "last-used", "beta"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Make a copy to avoid mutating original
+			statsCopy := make([]types.ServerStats, len(stats))
+			copy(statsCopy, stats)
+
+			sortStats(statsCopy, tt.sortBy)
+
+			if statsCopy[0].Name != tt.wantFirst {
+				t.Errorf("sortStats(%q) first element = %q, want %q",
+					tt.sortBy, statsCopy[0].Name, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestMergeConfiguredServers(t *testing.T) {
+	tests := []struct {
+		name        string
+		stats       []types.ServerStats
+		servers     []types.MCPServer
+		wantCount   int
+		wantServers []string
+	}{
+		{
+			name: "adds configured server with no stats",
+			stats: []types.ServerStats{
+				{Name: "context7", Calls: 10},
+			},
+			servers: []types.MCPServer{
+				{Name: "context7"},
+				{Name: "serena"},
+				{Name: "puppeteer"},
+			},
+			wantCount:   3,
+			wantServers: []string{"context7", "serena", "puppeteer"},
+		},
+		{
+			name:  "all configured servers have no stats",
+			stats: []types.ServerStats{},
+			servers: []types.MCPServer{
+				{Name: "new-server"},
+				{Name: "another-server"},
+			},
+			wantCount:   2,
+			wantServers: []string{"new-server", "another-server"},
+		},
+		{
+			name: "no new servers to add",
+			stats: []types.ServerStats{
+				{Name: "context7", Calls: 10},
+				{Name: "serena", Calls: 5},
+			},
+			servers: []types.MCPServer{
+				{Name: "context7"},
+				{Name: "serena"},
+			},
+			wantCount:   2,
+			wantServers: []string{"context7", "serena"},
+		},
+		{
+			name: "duplicate server names in config are handled",
+			stats: []types.ServerStats{
+				{Name: "context7", Calls: 10},
+			},
+			servers: []types.MCPServer{
+				{Name: "context7", Scope: types.ScopeGlobal},
+				{Name: "context7", Scope: types.ScopeProject}, // same name, different scope
+				{Name: "serena"},
+			},
+			wantCount:   2, // context7 appears once, serena once
+			wantServers: []string{"context7", "serena"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := mergeConfiguredServers(tt.stats, tt.servers)
+
+			if len(result) != tt.wantCount {
+				t.Errorf("mergeConfiguredServers() returned %d servers, want %d", len(result), tt.wantCount)
+			}
+
+			// Check all expected servers are present
+			serverNames := make(map[string]bool)
+			for _, s := range result {
+				serverNames[s.Name] = true
+			}
+
+			for _, name := range tt.wantServers {
+				if !serverNames[name] {
+					t.Errorf("expected server %q not found in result", name)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeConfiguredServers_ZeroCalls(t *testing.T) {
+	// Verify that newly added servers have 0 calls
+	stats := []types.ServerStats{
+		{Name: "context7", Calls: 100},
+	}
+	servers := []types.MCPServer{
+		{Name: "context7"},
+		{Name: "unused-server"},
+	}
+
+	result := mergeConfiguredServers(stats, servers)
+
+	for _, s := range result {
+		if s.Name == "unused-server" {
+			if s.Calls != 0 {
+				t.Errorf("unused-server should have 0 calls, got %d", s.Calls)
+			}
+			if !s.LastUsed.IsZero() {
+				t.Errorf("unused-server should have zero LastUsed, got %v", s.LastUsed)
+			}
+			return
+		}
+	}
+	t.Error("unused-server not found in result")
+}
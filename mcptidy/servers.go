@@ -0,0 +1,153 @@
+package mcptidy
+
+import (
+	"context"
+	"os"
+	"slices"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// LoadOptions selects the servers LoadServers adds to those of the config file.
+type LoadOptions struct {
+	// ProjectFiles adds the servers of the projects' config files: .mcp.json
+	// for Claude Code, the client's own file otherwise. For Claude Code, the
+	// projects are those of the config and WorkDir; for the other clients,
+	// only WorkDir.
+	ProjectFiles bool
+	// WorkDir is a project read in addition to the config's, usually the
+	// current directory. Empty reads none.
+	WorkDir string
+	// OtherClients adds the servers of every client other than Claude Code:
+	// those of its default config file and those of its config files in the
+	// projects. It only applies to Claude Code.
+	OtherClients bool
+}
+
+// Warning is a file that was skipped, or read in part, while loading servers.
+type Warning struct {
+	// Path is the file, if known.
+	Path string
+	// Err is set when the file was skipped.
+	Err error
+	// Issues lists the entries that were skipped when the file was read.
+	Issues []config.Issue
+}
+
+// Inventory is the servers of a source.
+type Inventory struct {
+	Source *Source
+	// Config is the source's config file.
+	Config *config.Config
+	// Servers is every server found, starting with those of the config.
+	Servers  []types.MCPServer
+	Warnings []Warning
+}
+
+// LoadServers reads the servers of the source's config file and, as the
+// options select, of the other files that configure servers. For Claude
+// Code, the servers deployed through managed-mcp.json are always added.
+//
+// An error is returned only if the config file can't be read; files that
+// can't be read otherwise are skipped and reported as warnings.
+func LoadServers(ctx context.Context, src *Source, opts LoadOptions) (*Inventory, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	cfg, err := src.Client.Load(src.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &Inventory{Source: src, Config: cfg}
+	inv.addIssues(src.ConfigPath, cfg)
+	inv.Servers = append(inv.Servers, cfg.Servers()...)
+
+	if src.Client.Name() != config.ClientClaudeCode {
+		if opts.ProjectFiles {
+			inv.addProjectServers(ctx, src.Client, projectDirs(nil, opts.WorkDir))
+		}
+		return inv, ctx.Err()
+	}
+
+	managed, err := config.LoadManagedServers(config.DefaultManagedDir())
+	if err != nil {
+		inv.Warnings = append(inv.Warnings, Warning{Err: err})
+	}
+	inv.Servers = append(inv.Servers, managed...)
+
+	projects := projectDirs(cfg.ProjectPaths(), opts.WorkDir)
+	if opts.ProjectFiles {
+		for _, project := range projects {
+			if ctx.Err() != nil {
+				break
+			}
+			shared, err := config.LoadMCPJSON(project, src.SettingsPath)
+			if err != nil {
+				inv.Warnings = append(inv.Warnings, Warning{Err: err})
+				continue
+			}
+			inv.Servers = append(inv.Servers, shared...)
+		}
+	}
+
+	if opts.OtherClients {
+		inv.addOtherClientServers(ctx, projects)
+	}
+	return inv, ctx.Err()
+}
+
+// addIssues records the entries of a config file that were skipped.
+func (inv *Inventory) addIssues(path string, cfg *config.Config) {
+	if issues := cfg.Issues(); len(issues) > 0 {
+		inv.Warnings = append(inv.Warnings, Warning{Path: path, Issues: issues})
+	}
+}
+
+// addOtherClientServers adds the servers of every client other than Claude
+// Code: those of its config file, if it exists, and those of its config
+// files in the given projects.
+func (inv *Inventory) addOtherClientServers(ctx context.Context, projects []string) {
+	for _, client := range config.Clients() {
+		if client.Name() == config.ClientClaudeCode || ctx.Err() != nil {
+			continue
+		}
+		if path := client.DefaultConfigPath(); path != "" {
+			if _, err := os.Stat(path); err == nil {
+				cfg, err := client.Load(path)
+				if err != nil {
+					inv.Warnings = append(inv.Warnings, Warning{Path: path, Err: err})
+				} else {
+					inv.addIssues(path, cfg)
+					inv.Servers = append(inv.Servers, cfg.Servers()...)
+				}
+			}
+		}
+		inv.addProjectServers(ctx, client, projects)
+	}
+}
+
+// addProjectServers adds the servers of a client's config files in the projects.
+func (inv *Inventory) addProjectServers(ctx context.Context, client config.Client, projects []string) {
+	for _, project := range projects {
+		if ctx.Err() != nil {
+			return
+		}
+		shared, err := config.LoadProjectServers(client, project)
+		if err != nil {
+			inv.Warnings = append(inv.Warnings, Warning{Err: err})
+			continue
+		}
+		inv.Servers = append(inv.Servers, shared...)
+	}
+}
+
+// projectDirs returns the project paths with the work directory added.
+func projectDirs(projects []string, workDir string) []string {
+	if workDir != "" && !slices.Contains(projects, workDir) {
+		projects = append(projects, workDir)
+	}
+	return projects
+}
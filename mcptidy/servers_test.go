@@ -0,0 +1,114 @@
+package mcptidy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/config"
+)
+
+func TestLoadServers(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project")
+	work := filepath.Join(dir, "work")
+	broken := filepath.Join(dir, "broken")
+	managedDir := filepath.Join(dir, "managed")
+	desktopPath := filepath.Join(dir, "claude_desktop_config.json")
+	configPath := filepath.Join(dir, ".claude.json")
+
+	writeFile(t, configPath, `{
+		"mcpServers": {"context7": {"command": "npx"}},
+		"projects": {
+			"`+project+`": {"mcpServers": {"serena": {"command": "uvx"}}},
+			"`+broken+`": {}
+		}
+	}`)
+	writeFile(t, filepath.Join(project, config.MCPJSONFileName), `{"mcpServers": {"slack": {"command": "npx"}}}`)
+	writeFile(t, filepath.Join(broken, config.MCPJSONFileName), `{"mcpServers": `)
+	writeFile(t, filepath.Join(work, ".cursor", "mcp.json"), `{"mcpServers": {"figma": {"url": "https://mcp.figma.com/mcp"}}}`)
+	writeFile(t, filepath.Join(managedDir, config.ManagedMCPFileName), `{"mcpServers": {"corp-search": {"command": "corp-search"}}}`)
+	writeFile(t, desktopPath, `{"mcpServers": {"puppeteer": {"command": "npx"}}}`)
+
+	t.Setenv("HOME", dir)
+	t.Setenv(config.ClaudeConfigDirEnv, dir)
+	t.Setenv(config.ManagedDirEnv, managedDir)
+	t.Setenv(config.DesktopConfigPathEnv, desktopPath)
+	for _, env := range []string{config.CursorConfigPathEnv, config.VSCodeConfigPathEnv, config.CodexConfigPathEnv, config.GeminiConfigPathEnv} {
+		t.Setenv(env, filepath.Join(dir, "missing"))
+	}
+
+	tests := []struct {
+		name         string
+		src          *Source
+		opts         LoadOptions
+		want         []string
+		wantWarnings int
+	}{
+		{
+			name: "config and managed servers",
+			src:  &Source{Client: config.ClaudeCode{}, ConfigPath: configPath},
+			want: []string{"global:context7", "project:" + project + ":serena", "managed:corp-search"},
+		},
+		{
+			name:         "project files",
+			src:          &Source{Client: config.ClaudeCode{}, ConfigPath: configPath},
+			opts:         LoadOptions{ProjectFiles: true, WorkDir: work},
+			want:         []string{"global:context7", "project:" + project + ":serena", "managed:corp-search", "mcpjson:" + project + ":slack"},
+			wantWarnings: 1,
+		},
+		{
+			name: "other clients",
+			src:  &Source{Client: config.ClaudeCode{}, ConfigPath: configPath},
+			opts: LoadOptions{OtherClients: true, WorkDir: work},
+			want: []string{
+				"global:context7", "project:" + project + ":serena", "managed:corp-search",
+				"claude-desktop:global:puppeteer", "cursor:project:" + work + ":figma",
+			},
+		},
+		{
+			name: "client's own project files",
+			src:  &Source{Client: config.ClaudeDesktop{}, ConfigPath: desktopPath},
+			opts: LoadOptions{ProjectFiles: true, WorkDir: work},
+			want: []string{"claude-desktop:global:puppeteer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := LoadServers(context.Background(), tt.src, tt.opts)
+			if err != nil {
+				t.Fatalf("LoadServers() error = %v", err)
+			}
+
+			var got []string
+			for i := range inv.Servers {
+				got = append(got, inv.Servers[i].Key())
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("LoadServers() mismatch (-want +got):\n%s", diff)
+			}
+			if len(inv.Warnings) != tt.wantWarnings {
+				t.Errorf("LoadServers() returned %d warnings, want %d: %+v", len(inv.Warnings), tt.wantWarnings, inv.Warnings)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LoadServers(ctx, &Source{Client: config.ClaudeCode{}, ConfigPath: configPath}, LoadOptions{}); err == nil {
+		t.Error("LoadServers() with a canceled context error = nil, want an error")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,192 @@
+// Package mcptidy is the library behind the mcp-tidy command. It finds the
+// MCP servers a client is configured with, measures how much they were used
+// in Claude Code's transcripts, joins the two into a report, and plans and
+// applies the removal of servers.
+//
+// A typical run resolves a Source, loads its servers, collects their usage
+// and joins the results:
+//
+//	src, err := mcptidy.ResolveSource(ctx, mcptidy.SourceOptions{})
+//	inv, err := mcptidy.LoadServers(ctx, src, mcptidy.LoadOptions{ProjectFiles: true})
+//	usage, err := mcptidy.CollectUsage(ctx, src, inv.Servers, mcptidy.UsageOptions{Period: "90d"})
+//	report := mcptidy.Join(inv.Servers, usage)
+//
+// Nothing is printed: problems that don't stop a run, such as a project file
+// that can't be read, are returned as warnings instead.
+package mcptidy
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/policy"
+	"github.com/nnnkkk7/mcp-tidy/profile"
+	"github.com/nnnkkk7/mcp-tidy/transcript"
+)
+
+// ProfileEnv selects a profile when SourceOptions.Profile is empty.
+const ProfileEnv = "MCP_TIDY_PROFILE"
+
+// Source is the client, config file and transcript directories mcp-tidy
+// works on.
+type Source struct {
+	Client config.Client
+	// Profile is the name of the selected profile, if any.
+	Profile         string
+	ConfigPath      string
+	TranscriptPaths []string
+	// SettingsPath is the user settings file, which can approve or block
+	// .mcp.json servers.
+	SettingsPath string
+}
+
+// Label describes the source for messages, naming the profile if there is one.
+func (s *Source) Label() string {
+	if s.Profile == "" {
+		return s.ConfigPath
+	}
+	return fmt.Sprintf("profile %s (%s)", s.Profile, s.ConfigPath)
+}
+
+// SourceOptions selects a Source. The zero value selects Claude Code's
+// default config and transcripts.
+type SourceOptions struct {
+	// Client is the client's name, e.g. "cursor". Empty means Claude Code.
+	Client string
+	// Profile is a profile of the user policy file. Empty means the one
+	// named by $MCP_TIDY_PROFILE, if any.
+	Profile string
+	// ConfigPath and TranscriptPaths replace the paths of the profile or
+	// the defaults.
+	ConfigPath      string
+	TranscriptPaths []string
+}
+
+// ResolveSource returns the source selected by the options and the
+// environment. The config path and transcript directories of the options take
+// precedence over the profile, which takes precedence over the other
+// environment variables.
+//
+// Transcript directories given explicitly must exist, as a mistyped path
+// would otherwise make every server look unused; only the default directory
+// may be missing.
+func ResolveSource(ctx context.Context, opts SourceOptions) (*Source, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	client, err := config.ParseClient(opts.Client)
+	if err != nil {
+		return nil, err
+	}
+	if !client.HasTranscripts() {
+		return resolveClientSource(client, opts)
+	}
+
+	name := opts.Profile
+	if name == "" {
+		name = os.Getenv(ProfileEnv)
+	}
+
+	src := &Source{Client: client, SettingsPath: config.UserSettingsPath()}
+	explicit := true
+	if name != "" {
+		registry, err := loadProfiles()
+		if err != nil {
+			return nil, err
+		}
+		p, err := registry.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		src.Profile, src.ConfigPath, src.TranscriptPaths = name, p.ConfigPath(), p.TranscriptPaths()
+		if settingsPath := p.SettingsPath(); settingsPath != "" {
+			src.SettingsPath = settingsPath
+		}
+	} else {
+		src.ConfigPath = config.DefaultConfigPath()
+		src.TranscriptPaths = transcript.DefaultTranscriptPaths()
+		explicit = os.Getenv(transcript.PathsEnv) != ""
+	}
+
+	if opts.ConfigPath != "" {
+		src.ConfigPath = opts.ConfigPath
+	}
+	if len(opts.TranscriptPaths) > 0 {
+		src.TranscriptPaths, explicit = opts.TranscriptPaths, true
+	}
+	if explicit {
+		if err := checkTranscriptPaths(src.TranscriptPaths); err != nil {
+			return nil, err
+		}
+	}
+	return src, nil
+}
+
+// resolveClientSource returns the source of a client other than Claude Code.
+// Profiles and transcripts are Claude Code's, so they don't apply.
+func resolveClientSource(client config.Client, opts SourceOptions) (*Source, error) {
+	if opts.Profile != "" {
+		return nil, fmt.Errorf("profiles are Claude Code's, so they cannot be used with %s", client.Title())
+	}
+	if len(opts.TranscriptPaths) > 0 {
+		return nil, fmt.Errorf("%s keeps no transcripts, so transcript directories cannot be used with it", client.Title())
+	}
+
+	src := &Source{Client: client, ConfigPath: client.DefaultConfigPath()}
+	if opts.ConfigPath != "" {
+		src.ConfigPath = opts.ConfigPath
+	}
+	return src, nil
+}
+
+// ProfileSources returns the source of every profile in the user policy
+// file, in the order of their names.
+func ProfileSources(ctx context.Context) ([]Source, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	registry, err := loadProfiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(registry) == 0 {
+		return nil, fmt.Errorf("no profiles configured (add a 'profiles:' section to %s)", policy.DefaultUserPath())
+	}
+
+	sources := make([]Source, 0, len(registry))
+	for _, name := range registry.Names() {
+		p, _ := registry.Get(name)
+		src := Source{Client: config.ClaudeCode{}, Profile: name, ConfigPath: p.ConfigPath(), TranscriptPaths: p.TranscriptPaths(), SettingsPath: p.SettingsPath()}
+		if src.SettingsPath == "" {
+			src.SettingsPath = config.UserSettingsPath()
+		}
+		if err := checkTranscriptPaths(src.TranscriptPaths); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
+
+// loadProfiles reads the profile registry from the user policy file.
+func loadProfiles() (profile.Registry, error) {
+	p, err := policy.LoadFile(policy.DefaultUserPath())
+	if err != nil {
+		return nil, err
+	}
+	return p.Profiles, nil
+}
+
+// checkTranscriptPaths returns an error if a transcript directory doesn't exist.
+func checkTranscriptPaths(paths []string) error {
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("transcript directory not found: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package mcptidy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/policy"
+	"github.com/nnnkkk7/mcp-tidy/transcript"
+)
+
+func TestResolveSource(t *testing.T) {
+	existing := t.TempDir()
+	missing := filepath.Join(t.TempDir(), "missing")
+	work := t.TempDir()
+	if err := os.Mkdir(filepath.Join(work, "projects"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("CLAUDE_CONFIG_DIR", "/srv/claude")
+	t.Setenv(config.ConfigPathEnv, "")
+	policyPath := filepath.Join(configHome, "mcp-tidy", policy.FileName)
+	if err := os.MkdirAll(filepath.Dir(policyPath), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	policyYAML := "profiles:\n  work:\n    configDir: " + work + "\n  broken:\n    configDir: " + missing + "\n"
+	if err := os.WriteFile(policyPath, []byte(policyYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.DesktopConfigPathEnv, "/tmp/desktop.json")
+	userSettings := filepath.Join("/srv/claude", "settings.json")
+
+	tests := []struct {
+		name        string
+		client      string
+		configPath  string
+		transcripts []string
+		profile     string
+		profileEnv  string
+		env         string
+		want        *Source
+		wantErr     bool
+	}{
+		{
+			name: "default transcripts may be missing",
+			want: &Source{Client: config.ClaudeCode{}, ConfigPath: filepath.Join("/srv/claude", ".claude.json"), TranscriptPaths: []string{filepath.Join("/srv/claude", "projects")}, SettingsPath: userSettings},
+		},
+		{
+			name:        "options",
+			configPath:  "/tmp/exported.json",
+			transcripts: []string{existing},
+			env:         missing,
+			want:        &Source{Client: config.ClaudeCode{}, ConfigPath: "/tmp/exported.json", TranscriptPaths: []string{existing}, SettingsPath: userSettings},
+		},
+		{
+			name: "env",
+			env:  existing,
+			want: &Source{Client: config.ClaudeCode{}, ConfigPath: filepath.Join("/srv/claude", ".claude.json"), TranscriptPaths: []string{existing}, SettingsPath: userSettings},
+		},
+		{
+			name:    "profile",
+			profile: "work",
+			want:    &Source{Client: config.ClaudeCode{}, Profile: "work", ConfigPath: filepath.Join(work, ".claude.json"), TranscriptPaths: []string{filepath.Join(work, "projects")}, SettingsPath: filepath.Join(work, "settings.json")},
+		},
+		{
+			name:       "profile from env, config path wins",
+			profileEnv: "work",
+			configPath: "/tmp/exported.json",
+			want:       &Source{Client: config.ClaudeCode{}, Profile: "work", ConfigPath: "/tmp/exported.json", TranscriptPaths: []string{filepath.Join(work, "projects")}, SettingsPath: filepath.Join(work, "settings.json")},
+		},
+		{
+			name:   "desktop",
+			client: config.ClientClaudeDesktop,
+			env:    missing,
+			want:   &Source{Client: config.ClaudeDesktop{}, ConfigPath: "/tmp/desktop.json"},
+		},
+		{name: "desktop with profile", client: config.ClientClaudeDesktop, profile: "work", wantErr: true},
+		{name: "desktop with transcripts", client: config.ClientClaudeDesktop, transcripts: []string{existing}, wantErr: true},
+		{name: "unknown client", client: "zed", wantErr: true},
+		{name: "unknown profile", profile: "personal", wantErr: true},
+		{name: "profile with missing transcripts", profile: "broken", wantErr: true},
+		{name: "missing option directory", transcripts: []string{existing, missing}, wantErr: true},
+		{name: "missing env directory", env: missing, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ProfileEnv, tt.profileEnv)
+			t.Setenv(transcript.PathsEnv, tt.env)
+
+			opts := SourceOptions{Client: tt.client, Profile: tt.profile, ConfigPath: tt.configPath, TranscriptPaths: tt.transcripts}
+			got, err := ResolveSource(context.Background(), opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ResolveSource() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
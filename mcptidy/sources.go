@@ -0,0 +1,110 @@
+package mcptidy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/transcript"
+	"github.com/nnnkkk7/mcp-tidy/types"
+	"github.com/nnnkkk7/mcp-tidy/usagelog"
+)
+
+// UsageSource yields the MCP tool calls recorded somewhere: Claude Code's
+// transcripts, a hook-written log, an OpenTelemetry export or a proxy log.
+// Calls need ServerName, ToolName and Timestamp; ServerName may be the raw
+// name from the tool name, it is resolved against the configured servers.
+//
+// A source that doesn't exist yet yields an error wrapping fs.ErrNotExist,
+// which CollectUsage treats as no calls.
+type UsageSource interface {
+	// Name describes the source for messages.
+	Name() string
+	// Calls yields the calls of the source. It stops at the first error.
+	Calls(ctx context.Context) iter.Seq2[types.ToolCall, error]
+}
+
+// DefaultSources returns the usage sources of src: its transcripts, and the
+// calls its sessions recorded in the usage log of 'mcp-tidy hook'.
+func DefaultSources(src *Source) []UsageSource {
+	if len(src.TranscriptPaths) == 0 {
+		return nil
+	}
+	return []UsageSource{
+		transcript.Transcripts{Paths: src.TranscriptPaths},
+		usagelog.Log{Path: usagelog.DefaultPath(), Roots: src.TranscriptPaths},
+	}
+}
+
+// MergeSources returns a source yielding the calls of every source in
+// turn. A call whose ID was already yielded, by an earlier source or the
+// same one, is dropped, so sources recording the same calls can be
+// combined. Sources that don't exist are skipped; an error wrapping
+// fs.ErrNotExist is yielded only when none exists.
+func MergeSources(sources ...UsageSource) UsageSource {
+	return mergedSource(sources)
+}
+
+type mergedSource []UsageSource
+
+func (m mergedSource) Name() string {
+	names := make([]string, 0, len(m))
+	for _, s := range m {
+		names = append(names, s.Name())
+	}
+	return strings.Join(names, "; ")
+}
+
+func (m mergedSource) Calls(ctx context.Context) iter.Seq2[types.ToolCall, error] {
+	return func(yield func(types.ToolCall, error) bool) {
+		seen := make(map[string]bool)
+		found := false
+		for _, s := range m {
+			missing := false
+			for call, err := range s.Calls(ctx) {
+				if errors.Is(err, fs.ErrNotExist) {
+					missing = true
+					break
+				}
+				if err != nil {
+					yield(types.ToolCall{}, fmt.Errorf("failed to read %s: %w", s.Name(), err))
+					return
+				}
+				if call.ID != "" {
+					if seen[call.ID] {
+						continue
+					}
+					seen[call.ID] = true
+				}
+				if !yield(call, nil) {
+					return
+				}
+			}
+			if !missing {
+				found = true
+			}
+		}
+		if !found {
+			yield(types.ToolCall{}, fmt.Errorf("no usage source found (%s): %w", m.Name(), fs.ErrNotExist))
+		}
+	}
+}
+
+// collectCalls reads every call of a source, treating a source that
+// doesn't exist as one without calls.
+func collectCalls(ctx context.Context, source UsageSource) ([]types.ToolCall, error) {
+	var calls []types.ToolCall
+	for call, err := range source.Calls(ctx) {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	return calls, ctx.Err()
+}
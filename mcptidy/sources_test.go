@@ -0,0 +1,180 @@
+package mcptidy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/transcript"
+	"github.com/nnnkkk7/mcp-tidy/types"
+	"github.com/nnnkkk7/mcp-tidy/usagelog"
+)
+
+// fakeSource is a UsageSource yielding fixed calls, then err if set.
+type fakeSource struct {
+	calls []types.ToolCall
+	err   error
+}
+
+func (f fakeSource) Name() string { return "fake" }
+
+func (f fakeSource) Calls(context.Context) iter.Seq2[types.ToolCall, error] {
+	return func(yield func(types.ToolCall, error) bool) {
+		for _, call := range f.calls {
+			if !yield(call, nil) {
+				return
+			}
+		}
+		if f.err != nil {
+			yield(types.ToolCall{}, f.err)
+		}
+	}
+}
+
+func TestMergeSources(t *testing.T) {
+	call := func(id, server string) types.ToolCall {
+		return types.ToolCall{ID: id, ServerName: server, ToolName: "query"}
+	}
+	missing := fakeSource{err: fmt.Errorf("no log: %w", fs.ErrNotExist)}
+
+	tests := []struct {
+		name        string
+		sources     []UsageSource
+		wantServers []string
+		wantErr     error
+	}{
+		{
+			name: "calls with the same ID are counted once",
+			sources: []UsageSource{
+				fakeSource{calls: []types.ToolCall{call("toolu_01", "context7"), call("toolu_02", "serena")}},
+				fakeSource{calls: []types.ToolCall{call("toolu_02", "serena"), call("toolu_03", "github")}},
+			},
+			wantServers: []string{"context7", "serena", "github"},
+		},
+		{
+			name: "a call repeated within one source is counted once",
+			sources: []UsageSource{
+				fakeSource{calls: []types.ToolCall{call("toolu_01", "context7"), call("toolu_01", "context7"), call("toolu_02", "serena")}},
+			},
+			wantServers: []string{"context7", "serena"},
+		},
+		{
+			name: "calls without an ID are kept",
+			sources: []UsageSource{
+				fakeSource{calls: []types.ToolCall{call("", "context7")}},
+				fakeSource{calls: []types.ToolCall{call("", "context7")}},
+			},
+			wantServers: []string{"context7", "context7"},
+		},
+		{
+			name:        "missing sources are skipped",
+			sources:     []UsageSource{missing, fakeSource{calls: []types.ToolCall{call("toolu_01", "context7")}}},
+			wantServers: []string{"context7"},
+		},
+		{
+			name:    "every source missing",
+			sources: []UsageSource{missing, missing},
+			wantErr: fs.ErrNotExist,
+		},
+		{
+			name:        "errors stop the merge",
+			sources:     []UsageSource{fakeSource{calls: []types.ToolCall{call("toolu_01", "context7")}, err: fs.ErrPermission}, missing},
+			wantServers: []string{"context7"},
+			wantErr:     fs.ErrPermission,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotServers []string
+			var gotErr error
+			for call, err := range MergeSources(tt.sources...).Calls(context.Background()) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				gotServers = append(gotServers, call.ServerName)
+			}
+
+			if !errors.Is(gotErr, tt.wantErr) || (gotErr == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Calls() error = %v, want %v", gotErr, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantServers, gotServers); diff != "" {
+				t.Errorf("Calls() servers mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCollectUsage_Sources(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	src := &Source{Client: config.ClaudeCode{}, TranscriptPaths: []string{"../testdata/projects"}}
+	servers := []types.MCPServer{{Name: "context7", Scope: types.ScopeGlobal}}
+
+	// Without a usage log, the default sources count what the transcripts
+	// alone count, each source deduplicated the same way
+	all, err := CollectUsage(context.Background(), src, servers, UsageOptions{Period: "all"})
+	if err != nil {
+		t.Fatalf("CollectUsage() error = %v", err)
+	}
+	only := []UsageSource{transcript.Transcripts{Paths: src.TranscriptPaths}}
+	transcripts, err := CollectUsage(context.Background(), src, servers, UsageOptions{Period: "all", Sources: only})
+	if err != nil {
+		t.Fatalf("CollectUsage() error = %v", err)
+	}
+	sortByName := cmpopts.SortSlices(func(a, b types.ServerStats) bool { return a.Name < b.Name })
+	if diff := cmp.Diff(all.Stats, transcripts.Stats, sortByName); diff != "" {
+		t.Errorf("CollectUsage() of the transcripts mismatch with all sources (-all +transcripts):\n%s", diff)
+	}
+
+	// The usage log is a default source; calls of other profiles' sessions
+	// are left out
+	now := time.Now()
+	for _, r := range []usagelog.Record{
+		{Time: now, ID: "toolu_02", Server: "context7", Tool: "query-docs", Root: "../testdata/projects"},
+		{Time: now, ID: "toolu_50", Server: "context7", Tool: "query-docs", Root: "../testdata/projects"},
+		{Time: now, ID: "toolu_51", Server: "context7", Tool: "query-docs", Root: "/elsewhere/projects"},
+	} {
+		if err := usagelog.Append(usagelog.DefaultPath(), &r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	usage, err := CollectUsage(context.Background(), src, servers, UsageOptions{Period: "all"})
+	if err != nil {
+		t.Fatalf("CollectUsage() error = %v", err)
+	}
+	if got := usage.StatsByName()["context7"].Calls; got != 4 {
+		t.Errorf("CollectUsage() with the usage log context7 calls = %d, want 4", got)
+	}
+
+	// toolu_01 is also in the transcripts, so only the proxy's second
+	// call adds to them
+	proxy := fakeSource{calls: []types.ToolCall{
+		{ID: "toolu_01", ServerName: "context7", ToolName: "resolve-library-id", Timestamp: now},
+		{ID: "proxy-1", ServerName: "context7", ToolName: "query-docs", Timestamp: now},
+	}}
+	sources := []UsageSource{transcript.Transcripts{Paths: src.TranscriptPaths}, proxy}
+
+	usage, err = CollectUsage(context.Background(), src, servers, UsageOptions{Period: "all", Sources: sources})
+	if err != nil {
+		t.Fatalf("CollectUsage() error = %v", err)
+	}
+	if got := usage.StatsByName()["context7"].Calls; got != 4 {
+		t.Errorf("CollectUsage() context7 calls = %d, want 4", got)
+	}
+
+	usage, err = CollectUsage(context.Background(), src, servers, UsageOptions{Period: "all", Sources: []UsageSource{}})
+	if err != nil {
+		t.Fatalf("CollectUsage() without sources error = %v", err)
+	}
+	if len(usage.Stats) != 0 {
+		t.Errorf("CollectUsage() without sources stats = %+v, want none", usage.Stats)
+	}
+}
@@ -0,0 +1,127 @@
+package mcptidy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/policy"
+	"github.com/nnnkkk7/mcp-tidy/transcript"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// DefaultPeriod is the period usage is measured over when neither the
+// options nor the policy set one.
+const DefaultPeriod = "30d"
+
+// UsageOptions configures CollectUsage.
+type UsageOptions struct {
+	// Period is the period usage is measured over, such as 7d, 30d, 12w or
+	// all; see types.ParsePeriodDuration.
+	// Empty means the default period of the user policy, or DefaultPeriod.
+	Period string
+	// Sources are the usage sources to read, merged with MergeSources.
+	// Nil means DefaultSources.
+	Sources []UsageSource
+}
+
+// Usage is the usage statistics and policy verdicts of a set of servers.
+type Usage struct {
+	// Stats holds the calls in the period of every server that was called,
+	// including servers that aren't configured.
+	Stats []types.ServerStats
+	// Verdicts is keyed by MCPServer.Key().
+	Verdicts map[string]types.UnusedVerdict
+	// Period is the period measured; 0 means all time.
+	Period      time.Duration
+	PeriodLabel string
+}
+
+// CollectUsage reads the usage sources and the policy files and decides
+// for every server whether it is unused. Servers of clients without
+// transcripts get a verdict marked Unavailable.
+func CollectUsage(ctx context.Context, src *Source, servers []types.MCPServer, opts UsageOptions) (*Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	set, err := policy.Load(policy.DefaultUserPath(), servers)
+	if err != nil {
+		return nil, err
+	}
+
+	period := opts.Period
+	if period == "" {
+		period = DefaultPeriod
+	}
+	d, err := types.ParsePeriodDuration(period)
+	if err != nil {
+		return nil, err
+	}
+	usage := &Usage{Period: d, PeriodLabel: period}
+	if d, label, ok := set.DefaultPeriod(); ok && opts.Period == "" {
+		usage.Period, usage.PeriodLabel = d, label
+	}
+
+	sources := opts.Sources
+	if sources == nil {
+		sources = DefaultSources(src)
+	}
+	// Without any source nothing has been used yet
+	calls, err := collectCalls(ctx, MergeSources(sources...))
+	if err != nil {
+		return nil, err
+	}
+	calls = transcript.NewMatcherForServers(measurableServers(servers)).ResolveCalls(calls)
+	usage.Stats = transcript.AggregateStats(transcript.FilterByDuration(calls, usage.Period))
+
+	timestamps := make(map[string][]time.Time)
+	for _, call := range calls {
+		timestamps[call.ServerName] = append(timestamps[call.ServerName], call.Timestamp)
+	}
+
+	now := time.Now()
+	usage.Verdicts = make(map[string]types.UnusedVerdict, len(servers))
+	for i := range servers {
+		verdict := set.Evaluate(&servers[i], timestamps[servers[i].Name], usage.Period, now)
+		if client, err := config.ParseClient(servers[i].Client); err == nil && !client.HasTranscripts() {
+			verdict.Flagged, verdict.Unavailable = false, true
+			if !verdict.Protected {
+				verdict.Reason = fmt.Sprintf("usage unavailable (%s keeps no transcripts)", client.Title())
+			}
+		}
+		usage.Verdicts[servers[i].Key()] = verdict
+	}
+
+	return usage, nil
+}
+
+// measurableServers returns the servers whose client keeps transcripts.
+func measurableServers(servers []types.MCPServer) []types.MCPServer {
+	var result []types.MCPServer
+	for i := range servers {
+		if client, err := config.ParseClient(servers[i].Client); err == nil && client.HasTranscripts() {
+			result = append(result, servers[i])
+		}
+	}
+	return result
+}
+
+// StatsByName returns the stats keyed by server name.
+func (u *Usage) StatsByName() map[string]types.ServerStats {
+	m := make(map[string]types.ServerStats, len(u.Stats))
+	for _, s := range u.Stats {
+		m[s.Name] = s
+	}
+	return m
+}
+
+// Verdict returns the verdict for a server, falling back to the period
+// check when the server was not evaluated.
+func (u *Usage) Verdict(server *types.MCPServer, stat types.ServerStats) types.UnusedVerdict {
+	if v, ok := u.Verdicts[server.Key()]; ok {
+		return v
+	}
+	return types.UnusedVerdict{Flagged: stat.IsUnused(u.Period)}
+}
@@ -0,0 +1,102 @@
+package mcptidy
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/policy"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestCollectUsage(t *testing.T) {
+	src := &Source{Client: config.ClaudeCode{}, TranscriptPaths: []string{"../testdata/projects"}}
+	desktop := types.MCPServer{Name: "serena", Scope: types.ScopeGlobal, Client: config.ClientClaudeDesktop}
+	servers := []types.MCPServer{
+		{Name: "context7", Scope: types.ScopeGlobal},
+		{Name: "github", Scope: types.ScopeGlobal},
+		desktop,
+	}
+
+	tests := []struct {
+		name       string
+		policy     string
+		opts       UsageOptions
+		wantLabel  string
+		wantPeriod time.Duration
+		wantCalls  map[string]int
+		wantFlag   map[string]bool
+	}{
+		{
+			name:       "default period",
+			wantLabel:  DefaultPeriod,
+			wantPeriod: 30 * 24 * time.Hour,
+			wantCalls:  map[string]int{},
+			wantFlag:   map[string]bool{"global:context7": true, "global:github": true},
+		},
+		{
+			name:      "all time",
+			opts:      UsageOptions{Period: "all"},
+			wantLabel: "all",
+			wantCalls: map[string]int{"context7": 3, "serena": 2},
+			wantFlag:  map[string]bool{"global:github": true},
+		},
+		{
+			name:      "policy period",
+			policy:    "period: all\nprotect: [github]\n",
+			wantLabel: "all",
+			wantCalls: map[string]int{"context7": 3, "serena": 2},
+			wantFlag:  map[string]bool{},
+		},
+		{
+			name:       "options override the policy",
+			policy:     "period: all\n",
+			opts:       UsageOptions{Period: "7d"},
+			wantLabel:  "7d",
+			wantPeriod: 7 * 24 * time.Hour,
+			wantCalls:  map[string]int{},
+			wantFlag:   map[string]bool{"global:context7": true, "global:github": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configHome := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", configHome)
+			if tt.policy != "" {
+				writeFile(t, filepath.Join(configHome, "mcp-tidy", policy.FileName), tt.policy)
+			}
+
+			usage, err := CollectUsage(context.Background(), src, servers, tt.opts)
+			if err != nil {
+				t.Fatalf("CollectUsage() error = %v", err)
+			}
+
+			if usage.PeriodLabel != tt.wantLabel || usage.Period != tt.wantPeriod {
+				t.Errorf("CollectUsage() period = %v (%s), want %v (%s)", usage.Period, usage.PeriodLabel, tt.wantPeriod, tt.wantLabel)
+			}
+			calls := make(map[string]int)
+			for _, s := range usage.Stats {
+				calls[s.Name] = s.Calls
+			}
+			if diff := cmp.Diff(tt.wantCalls, calls); diff != "" {
+				t.Errorf("CollectUsage() calls mismatch (-want +got):\n%s", diff)
+			}
+			flagged := make(map[string]bool)
+			for key, v := range usage.Verdicts {
+				if v.Flagged {
+					flagged[key] = true
+				}
+			}
+			if diff := cmp.Diff(tt.wantFlag, flagged); diff != "" {
+				t.Errorf("CollectUsage() flagged mismatch (-want +got):\n%s", diff)
+			}
+			if v := usage.Verdicts[desktop.Key()]; !v.Unavailable {
+				t.Errorf("CollectUsage() verdict of a Desktop server = %+v, want it unavailable", v)
+			}
+		})
+	}
+}
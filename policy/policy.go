@@ -0,0 +1,338 @@
+// Package policy decides which MCP servers count as unused, based on .mcp-tidy.yaml files.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/nnnkkk7/mcp-tidy/check"
+	"github.com/nnnkkk7/mcp-tidy/profile"
+	"github.com/nnnkkk7/mcp-tidy/types"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the policy file, both in the user config dir and in projects.
+const FileName = ".mcp-tidy.yaml"
+
+// Thresholds decide when a server counts as unused: it is flagged when it has
+// fewer than MinCalls calls within Period.
+type Thresholds struct {
+	Period   string `yaml:"period,omitempty"`
+	MinCalls *int   `yaml:"minCalls,omitempty"`
+}
+
+// Rule applies thresholds to servers whose name matches a glob pattern.
+type Rule struct {
+	Match      string `yaml:"match"`
+	Thresholds `yaml:",inline"`
+}
+
+// Policy is the content of a .mcp-tidy.yaml file.
+type Policy struct {
+	// Period is the default period. In the user file it is used when no
+	// --period flag is given; in a project file it applies to the project's servers.
+	Period   string `yaml:"period,omitempty"`
+	MinCalls *int   `yaml:"minCalls,omitempty"`
+
+	// Protect lists name patterns that are never flagged and never removed.
+	Protect []string `yaml:"protect,omitempty"`
+	// Allow lists name patterns that are never flagged as unused.
+	Allow []string `yaml:"allow,omitempty"`
+
+	// Scopes holds thresholds keyed by "global", "project", "mcpjson", "managed" or a project path.
+	Scopes map[string]Thresholds `yaml:"scopes,omitempty"`
+	// Servers holds per-server rules; later matching rules override earlier ones.
+	Servers []Rule `yaml:"servers,omitempty"`
+
+	// Check holds the rules for the check command.
+	Check check.Rules `yaml:"check,omitempty"`
+
+	// Profiles holds the Claude Code profiles by name. Only the user file's
+	// profiles are used.
+	Profiles profile.Registry `yaml:"profiles,omitempty"`
+}
+
+// Set is the user policy combined with the policies of individual projects.
+type Set struct {
+	user     *Policy
+	projects map[string]*Policy // project path -> policy
+}
+
+// DefaultUserPath returns the path to the user-level policy file.
+func DefaultUserPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "mcp-tidy", FileName)
+}
+
+// LoadFile reads and validates a policy file.
+// If the file does not exist, returns an empty policy (not an error).
+func LoadFile(path string) (*Policy, error) {
+	p := &Policy{}
+	if path == "" {
+		return p, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return p, nil
+		}
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Load reads the user policy and the policy file of every project that
+// has project-scoped servers.
+func Load(userPath string, servers []types.MCPServer) (*Set, error) {
+	user, err := LoadFile(userPath)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &Set{user: user, projects: make(map[string]*Policy)}
+	for i := range servers {
+		projectPath := servers[i].ProjectPath
+		if !servers[i].InProject() {
+			continue
+		}
+		if _, ok := set.projects[projectPath]; ok {
+			continue
+		}
+		p, err := LoadFile(filepath.Join(projectPath, FileName))
+		if err != nil {
+			return nil, err
+		}
+		set.projects[projectPath] = p
+	}
+
+	return set, nil
+}
+
+// DefaultPeriod returns the period configured in the user policy,
+// and whether one is configured.
+func (s *Set) DefaultPeriod() (time.Duration, string, bool) {
+	if s == nil || s.user.Period == "" {
+		return 0, "", false
+	}
+	d, _ := types.ParsePeriodDuration(s.user.Period) // validated at load time
+	return d, s.user.Period, true
+}
+
+// Evaluate decides whether a server counts as unused.
+// usage holds the timestamps of the server's calls; defaultPeriod applies
+// unless the policy sets a period for the server (0 means all time).
+func (s *Set) Evaluate(server *types.MCPServer, usage []time.Time, defaultPeriod time.Duration, now time.Time) types.UnusedVerdict {
+	if pattern, ok := s.Protected(server); ok {
+		return types.UnusedVerdict{Protected: true, Reason: fmt.Sprintf("protected by policy (%s)", pattern)}
+	}
+	for _, p := range s.applicable(server) {
+		if pattern, ok := matchAny(p.Allow, server.Name); ok {
+			return types.UnusedVerdict{Reason: fmt.Sprintf("allowed by policy (%s)", pattern)}
+		}
+	}
+
+	period, minCalls, source := s.thresholds(server, defaultPeriod)
+
+	calls := 0
+	for _, ts := range usage {
+		if period <= 0 || ts.After(now.Add(-period)) {
+			calls++
+		}
+	}
+
+	var reason string
+	switch {
+	case calls == 0:
+		reason = fmt.Sprintf("no calls %s", periodPhrase(period))
+	case calls < minCalls:
+		reason = fmt.Sprintf("%d calls %s, below minimum of %d", calls, periodPhrase(period), minCalls)
+	default:
+		reason = fmt.Sprintf("%d calls %s", calls, periodPhrase(period))
+	}
+	if source != "" {
+		reason += fmt.Sprintf(" (%s)", source)
+	}
+
+	return types.UnusedVerdict{Flagged: calls < minCalls, Reason: reason}
+}
+
+// Protected reports whether a server is protected from removal, and by which pattern.
+func (s *Set) Protected(server *types.MCPServer) (string, bool) {
+	for _, p := range s.applicable(server) {
+		if pattern, ok := matchAny(p.Protect, server.Name); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// applicable returns the policies that apply to a server, least specific first.
+func (s *Set) applicable(server *types.MCPServer) []*Policy {
+	if s == nil {
+		return nil
+	}
+	policies := []*Policy{s.user}
+	if server.InProject() {
+		if p, ok := s.projects[server.ProjectPath]; ok {
+			policies = append(policies, p)
+		}
+	}
+	return policies
+}
+
+// thresholds resolves the period and minimum calls for a server, and names
+// the most specific policy entry that set them.
+func (s *Set) thresholds(server *types.MCPServer, defaultPeriod time.Duration) (time.Duration, int, string) {
+	period, minCalls, source := defaultPeriod, 1, ""
+	if s == nil {
+		return period, minCalls, source
+	}
+
+	apply := func(t Thresholds, from string) {
+		if t.Period != "" {
+			period, _ = types.ParsePeriodDuration(t.Period) // validated at load time
+			source = from
+		}
+		if t.MinCalls != nil {
+			minCalls = *t.MinCalls
+			source = from
+		}
+	}
+
+	// The user file's top-level period is the default period, already
+	// reflected in defaultPeriod, so only its minimum applies here.
+	apply(Thresholds{MinCalls: s.user.MinCalls}, "policy default")
+	apply(s.user.Scopes[server.Scope.String()], "scope "+server.Scope.String())
+	if server.InProject() {
+		apply(s.user.Scopes[server.ProjectPath], "scope "+server.ProjectPath)
+	}
+	applyRules(s.user.Servers, server.Name, apply)
+
+	if server.InProject() {
+		if p, ok := s.projects[server.ProjectPath]; ok {
+			apply(Thresholds{Period: p.Period, MinCalls: p.MinCalls}, "project policy")
+			applyRules(p.Servers, server.Name, apply)
+		}
+	}
+
+	return period, minCalls, source
+}
+
+// applyRules applies every rule matching name, in order.
+func applyRules(rules []Rule, name string, apply func(Thresholds, string)) {
+	for _, rule := range rules {
+		if ok, _ := path.Match(rule.Match, name); ok {
+			apply(rule.Thresholds, "rule "+rule.Match)
+		}
+	}
+}
+
+// matchAny returns the first pattern that matches name.
+func matchAny(patterns []string, name string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// validate checks periods, thresholds and patterns.
+func (p *Policy) validate() error {
+	if err := validateThresholds(Thresholds{Period: p.Period, MinCalls: p.MinCalls}, "top level"); err != nil {
+		return err
+	}
+	for key, t := range p.Scopes {
+		if err := validateThresholds(t, "scope "+key); err != nil {
+			return err
+		}
+	}
+	if err := p.validateRules(); err != nil {
+		return err
+	}
+	if err := p.validatePatterns(); err != nil {
+		return err
+	}
+	if err := p.Check.Validate(); err != nil {
+		return fmt.Errorf("check: %w", err)
+	}
+	if err := p.Profiles.Validate(); err != nil {
+		return fmt.Errorf("profiles: %w", err)
+	}
+	return nil
+}
+
+// validateThresholds checks one set of thresholds; where names it in errors.
+func validateThresholds(t Thresholds, where string) error {
+	if t.Period != "" {
+		if _, err := types.ParsePeriodDuration(t.Period); err != nil {
+			return fmt.Errorf("%s: %w", where, err)
+		}
+	}
+	if t.MinCalls != nil && *t.MinCalls < 0 {
+		return fmt.Errorf("%s: minCalls must not be negative", where)
+	}
+	return nil
+}
+
+// validateRules checks the match pattern and thresholds of each server rule.
+func (p *Policy) validateRules() error {
+	for i, rule := range p.Servers {
+		if rule.Match == "" {
+			return fmt.Errorf("servers[%d]: match is required", i)
+		}
+		if _, err := path.Match(rule.Match, ""); err != nil {
+			return fmt.Errorf("servers[%d]: invalid pattern %q", i, rule.Match)
+		}
+		if err := validateThresholds(rule.Thresholds, fmt.Sprintf("servers[%d]", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePatterns checks the protect and allow patterns.
+func (p *Policy) validatePatterns() error {
+	for _, pattern := range append(append([]string{}, p.Protect...), p.Allow...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// FormatPeriod formats a period the way it is written in policy files.
+func FormatPeriod(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d <= 0:
+		return "all"
+	case d%day == 0:
+		return fmt.Sprintf("%dd", d/day)
+	default:
+		return d.String()
+	}
+}
+
+// periodPhrase describes a period for use in a reason.
+func periodPhrase(d time.Duration) string {
+	if d <= 0 {
+		return "(all time)"
+	}
+	return "in " + FormatPeriod(d)
+}
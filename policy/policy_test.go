@@ -0,0 +1,252 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/profile"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestSet_Evaluate(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	global := func(name string) *types.MCPServer {
+		return &types.MCPServer{Name: name, Scope: types.ScopeGlobal}
+	}
+	project := func(name string) *types.MCPServer {
+		return &types.MCPServer{Name: name, Scope: types.ScopeProject, ProjectPath: "/work/app"}
+	}
+
+	tests := []struct {
+		name          string
+		set           *Set
+		server        *types.MCPServer
+		usage         []time.Time
+		defaultPeriod time.Duration
+		want          types.UnusedVerdict
+	}{
+		{
+			name:          "nil set falls back to the default period",
+			server:        global("context7"),
+			defaultPeriod: 30 * day,
+			want:          types.UnusedVerdict{Flagged: true, Reason: "no calls in 30d"},
+		},
+		{
+			name:          "calls outside the period do not count",
+			set:           &Set{user: &Policy{}},
+			server:        global("context7"),
+			usage:         []time.Time{now.Add(-40 * day), now.Add(-2 * day)},
+			defaultPeriod: 30 * day,
+			want:          types.UnusedVerdict{Reason: "1 calls in 30d"},
+		},
+		{
+			name:          "all time counts every call",
+			set:           &Set{user: &Policy{}},
+			server:        global("context7"),
+			usage:         []time.Time{now.Add(-400 * day)},
+			defaultPeriod: 0,
+			want:          types.UnusedVerdict{Reason: "1 calls (all time)"},
+		},
+		{
+			name:   "protected servers are never flagged",
+			set:    &Set{user: &Policy{Protect: []string{"github*"}}},
+			server: global("github-enterprise"),
+			want:   types.UnusedVerdict{Protected: true, Reason: "protected by policy (github*)"},
+		},
+		{
+			name:   "allowed servers are not flagged",
+			set:    &Set{user: &Policy{Allow: []string{"rarely-*"}}},
+			server: global("rarely-used"),
+			want:   types.UnusedVerdict{Reason: "allowed by policy (rarely-*)"},
+		},
+		{
+			name:          "user minCalls applies to every server",
+			set:           &Set{user: &Policy{MinCalls: intPtr(3)}},
+			server:        global("context7"),
+			usage:         []time.Time{now.Add(-time.Hour), now.Add(-2 * time.Hour)},
+			defaultPeriod: 30 * day,
+			want:          types.UnusedVerdict{Flagged: true, Reason: "2 calls in 30d, below minimum of 3 (policy default)"},
+		},
+		{
+			name: "scope thresholds override the default period",
+			set: &Set{user: &Policy{Scopes: map[string]Thresholds{
+				"project": {Period: "90d"},
+			}}},
+			server:        project("serena"),
+			usage:         []time.Time{now.Add(-60 * day)},
+			defaultPeriod: 30 * day,
+			want:          types.UnusedVerdict{Reason: "1 calls in 90d (scope project)"},
+		},
+		{
+			name: "project path scope is more specific than the scope",
+			set: &Set{user: &Policy{Scopes: map[string]Thresholds{
+				"project":   {Period: "90d"},
+				"/work/app": {Period: "7d"},
+			}}},
+			server:        project("serena"),
+			usage:         []time.Time{now.Add(-60 * day)},
+			defaultPeriod: 30 * day,
+			want:          types.UnusedVerdict{Flagged: true, Reason: "no calls in 7d (scope /work/app)"},
+		},
+		{
+			name: "later matching rules override earlier ones",
+			set: &Set{user: &Policy{Servers: []Rule{
+				{Match: "*", Thresholds: Thresholds{MinCalls: intPtr(10)}},
+				{Match: "puppeteer", Thresholds: Thresholds{Period: "all", MinCalls: intPtr(1)}},
+			}}},
+			server:        global("puppeteer"),
+			usage:         []time.Time{now.Add(-300 * day)},
+			defaultPeriod: 30 * day,
+			want:          types.UnusedVerdict{Reason: "1 calls (all time) (rule puppeteer)"},
+		},
+		{
+			name: "project policy overrides the user policy",
+			set: &Set{
+				user: &Policy{MinCalls: intPtr(5)},
+				projects: map[string]*Policy{
+					"/work/app": {Period: "14d", MinCalls: intPtr(1)},
+				},
+			},
+			server:        project("serena"),
+			usage:         []time.Time{now.Add(-day)},
+			defaultPeriod: 30 * day,
+			want:          types.UnusedVerdict{Reason: "1 calls in 14d (project policy)"},
+		},
+		{
+			name: "project policy can protect its servers",
+			set: &Set{
+				user: &Policy{},
+				projects: map[string]*Policy{
+					"/work/app": {Protect: []string{"serena"}},
+				},
+			},
+			server: project("serena"),
+			want:   types.UnusedVerdict{Protected: true, Reason: "protected by policy (serena)"},
+		},
+		{
+			name: "project policy does not apply to global servers",
+			set: &Set{
+				user: &Policy{},
+				projects: map[string]*Policy{
+					"/work/app": {Protect: []string{"serena"}},
+				},
+			},
+			server:        global("serena"),
+			defaultPeriod: 30 * day,
+			want:          types.UnusedVerdict{Flagged: true, Reason: "no calls in 30d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.set.Evaluate(tt.server, tt.usage, tt.defaultPeriod, now)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Evaluate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *Policy
+		wantErr bool
+	}{
+		{
+			name: "full policy",
+			content: `period: 60d
+minCalls: 2
+protect: [github]
+allow: ["rarely-*"]
+scopes:
+  project:
+    period: 90d
+servers:
+  - match: "puppeteer*"
+    minCalls: 5
+`,
+			want: &Policy{
+				Period:   "60d",
+				MinCalls: intPtr(2),
+				Protect:  []string{"github"},
+				Allow:    []string{"rarely-*"},
+				Scopes:   map[string]Thresholds{"project": {Period: "90d"}},
+				Servers:  []Rule{{Match: "puppeteer*", Thresholds: Thresholds{MinCalls: intPtr(5)}}},
+			},
+		},
+		{name: "invalid period", content: "period: soon\n", wantErr: true},
+		{name: "negative minCalls", content: "scopes:\n  global:\n    minCalls: -1\n", wantErr: true},
+		{name: "rule without match", content: "servers:\n  - minCalls: 1\n", wantErr: true},
+		{name: "invalid pattern", content: "protect: [\"[\"]\n", wantErr: true},
+		{name: "invalid yaml", content: "protect: [\n", wantErr: true},
+		{
+			name:    "profiles",
+			content: "profiles:\n  work:\n    configDir: /srv/claude-work\n",
+			want:    &Policy{Profiles: profile.Registry{"work": {ConfigDir: "/srv/claude-work"}}},
+		},
+		{name: "profile without location", content: "profiles:\n  work:\n    config: /srv/work.json\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), FileName)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("failed to write policy: %v", err)
+			}
+
+			got, err := LoadFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("LoadFile() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoadFile_NotExist(t *testing.T) {
+	got, err := LoadFile(filepath.Join(t.TempDir(), FileName))
+	if err != nil {
+		t.Fatalf("LoadFile() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(&Policy{}, got); diff != "" {
+		t.Errorf("LoadFile() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoad_ProjectPolicies(t *testing.T) {
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, FileName), []byte("protect: [serena]\n"), 0o600); err != nil {
+		t.Fatalf("failed to write policy: %v", err)
+	}
+
+	servers := []types.MCPServer{
+		{Name: "context7", Scope: types.ScopeGlobal},
+		{Name: "serena", Scope: types.ScopeProject, ProjectPath: projectDir},
+	}
+
+	set, err := Load("", servers)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	got := set.Evaluate(&servers[1], nil, 30*24*time.Hour, time.Now())
+	if !got.Protected {
+		t.Errorf("expected serena to be protected by the project policy, got %+v", got)
+	}
+	if _, _, ok := set.DefaultPeriod(); ok {
+		t.Error("expected no default period without a user policy")
+	}
+}
@@ -0,0 +1,102 @@
+// Package profile resolves named Claude Code profiles, such as a work and a
+// personal setup that each have their own config file and transcripts.
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Profile locates the files of one Claude Code profile.
+type Profile struct {
+	// ConfigDir is the profile's Claude config directory, as Claude Code is
+	// pointed to with CLAUDE_CONFIG_DIR. It holds .claude.json and projects/.
+	ConfigDir string `yaml:"configDir,omitempty"`
+	// Config overrides the config file.
+	Config string `yaml:"config,omitempty"`
+	// Transcripts overrides the transcript directories.
+	Transcripts []string `yaml:"transcripts,omitempty"`
+}
+
+// ConfigPath returns the profile's config file.
+func (p *Profile) ConfigPath() string {
+	if p.Config != "" {
+		return expandHome(p.Config)
+	}
+	return filepath.Join(expandHome(p.ConfigDir), ".claude.json")
+}
+
+// TranscriptPaths returns the profile's transcript directories.
+func (p *Profile) TranscriptPaths() []string {
+	if len(p.Transcripts) == 0 {
+		return []string{filepath.Join(expandHome(p.ConfigDir), "projects")}
+	}
+	paths := make([]string, len(p.Transcripts))
+	for i, path := range p.Transcripts {
+		paths[i] = expandHome(path)
+	}
+	return paths
+}
+
+// SettingsPath returns the profile's user settings file, or "" when the
+// profile has no config directory and the default settings apply.
+func (p *Profile) SettingsPath() string {
+	if p.ConfigDir == "" {
+		return ""
+	}
+	return filepath.Join(expandHome(p.ConfigDir), "settings.json")
+}
+
+// Registry holds the profiles by name.
+type Registry map[string]Profile
+
+// Names returns the profile names in alphabetical order.
+func (r Registry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the named profile.
+func (r Registry) Get(name string) (*Profile, error) {
+	p, ok := r[name]
+	if !ok {
+		if len(r) == 0 {
+			return nil, fmt.Errorf("unknown profile %q (no profiles are configured)", name)
+		}
+		return nil, fmt.Errorf("unknown profile %q (expected one of %s)", name, strings.Join(r.Names(), ", "))
+	}
+	return &p, nil
+}
+
+// Validate checks that every profile can be located.
+func (r Registry) Validate() error {
+	for _, name := range r.Names() {
+		p := r[name]
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("profile name must not be empty")
+		}
+		if p.ConfigDir == "" && (p.Config == "" || len(p.Transcripts) == 0) {
+			return fmt.Errorf("profile %s: configDir, or both config and transcripts, is required", name)
+		}
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~ with the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
@@ -0,0 +1,100 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProfile_Paths(t *testing.T) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("failed to get home dir: %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		profile         Profile
+		wantConfig      string
+		wantTranscripts []string
+	}{
+		{
+			name:            "config dir",
+			profile:         Profile{ConfigDir: "/srv/claude-work"},
+			wantConfig:      filepath.Join("/srv/claude-work", ".claude.json"),
+			wantTranscripts: []string{filepath.Join("/srv/claude-work", "projects")},
+		},
+		{
+			name:            "home directory is expanded",
+			profile:         Profile{ConfigDir: "~/.claude-work"},
+			wantConfig:      filepath.Join(homeDir, ".claude-work", ".claude.json"),
+			wantTranscripts: []string{filepath.Join(homeDir, ".claude-work", "projects")},
+		},
+		{
+			name:            "explicit files",
+			profile:         Profile{Config: "~/.claude.json", Transcripts: []string{"~/.claude/projects", "/mnt/old/projects"}},
+			wantConfig:      filepath.Join(homeDir, ".claude.json"),
+			wantTranscripts: []string{filepath.Join(homeDir, ".claude", "projects"), "/mnt/old/projects"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.wantConfig, tt.profile.ConfigPath()); diff != "" {
+				t.Errorf("ConfigPath() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantTranscripts, tt.profile.TranscriptPaths()); diff != "" {
+				t.Errorf("TranscriptPaths() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRegistry_Get(t *testing.T) {
+	r := Registry{
+		"work":     {ConfigDir: "/srv/work"},
+		"personal": {ConfigDir: "/srv/personal"},
+	}
+
+	if diff := cmp.Diff([]string{"personal", "work"}, r.Names()); diff != "" {
+		t.Errorf("Names() mismatch (-want +got):\n%s", diff)
+	}
+
+	p, err := r.Get("work")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if p.ConfigDir != "/srv/work" {
+		t.Errorf("Get() = %+v, want the work profile", p)
+	}
+
+	_, err = r.Get("home")
+	if err == nil || err.Error() != `unknown profile "home" (expected one of personal, work)` {
+		t.Errorf("Get() error = %v", err)
+	}
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry Registry
+		wantErr  bool
+	}{
+		{name: "empty", registry: nil},
+		{name: "config dir", registry: Registry{"work": {ConfigDir: "/srv/work"}}},
+		{name: "config and transcripts", registry: Registry{"work": {Config: "/srv/work.json", Transcripts: []string{"/srv/projects"}}}},
+		{name: "config only", registry: Registry{"work": {Config: "/srv/work.json"}}, wantErr: true},
+		{name: "nothing", registry: Registry{"work": {}}, wantErr: true},
+		{name: "empty name", registry: Registry{"": {ConfigDir: "/srv/work"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.registry.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Package selection implements the grammar for picking servers from a
+// list, shared by the interactive prompt and command-line arguments.
+package selection
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// Help describes the selection grammar accepted by Parse.
+const Help = `numbers (1 3), ranges (1-5), 'all', names or globs (puppeteer*),
+  'unused', 'used', 'global', 'project:/path'; prefix with '!' to exclude (all !3)`
+
+// Parse resolves a selection expression against servers and returns
+// the selected indices in ascending order.
+//
+// Tokens are separated by spaces or commas:
+//
+//	N, N-M         server numbers as shown in the prompt (1-based)
+//	all            every server
+//	NAME, GLOB     server names, exact or as a glob pattern (puppeteer*)
+//	name:NAME      a server name that clashes with a keyword
+//	unused, used   servers flagged / not flagged as unused (see verdicts)
+//	global         globally configured servers
+//	project:PATH   servers of a project (PATH may be a glob)
+//	!TOKEN         exclude the servers matched by TOKEN
+//
+// If the expression only contains exclusions, they are applied to all servers.
+// Tokens that match nothing are reported as errors rather than ignored.
+// verdicts (keyed by MCPServer.Key()) decide what is unused; servers without a
+// verdict are unused when they have no calls in stats.
+func Parse(input string, servers []types.MCPServer, stats map[string]types.ServerStats, verdicts map[string]types.UnusedVerdict) ([]int, error) {
+	return parseSelection(input, servers, stats, verdicts, true)
+}
+
+// ParseSelectors is Parse for selectors given without showing the
+// numbered list, such as command-line arguments. Numbers and ranges are
+// rejected, as they would pick whichever servers happen to sort there.
+func ParseSelectors(input string, servers []types.MCPServer, stats map[string]types.ServerStats, verdicts map[string]types.UnusedVerdict) ([]int, error) {
+	return parseSelection(input, servers, stats, verdicts, false)
+}
+
+// parseSelection implements Parse; numbers reports whether numbers
+// and ranges are accepted.
+func parseSelection(input string, servers []types.MCPServer, stats map[string]types.ServerStats, verdicts map[string]types.UnusedVerdict, numbers bool) ([]int, error) {
+	tokens := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty selection")
+	}
+
+	included := make(map[int]bool)
+	excluded := make(map[int]bool)
+	hasInclude := false
+
+	for _, token := range tokens {
+		exclude := strings.HasPrefix(token, "!")
+		token = strings.TrimPrefix(token, "!")
+		if token == "" {
+			return nil, fmt.Errorf("'!' must be followed by a selector")
+		}
+
+		if !numbers && isNumberToken(token) {
+			return nil, fmt.Errorf("%q selects servers by their number in the interactive list; use names instead (name:%s for a server named %s)", token, token, token)
+		}
+		matched, err := matchSelectionToken(token, servers, stats, verdicts)
+		if err != nil {
+			return nil, err
+		}
+
+		target := included
+		if exclude {
+			target = excluded
+		} else {
+			hasInclude = true
+		}
+		for _, idx := range matched {
+			target[idx] = true
+		}
+	}
+
+	if !hasInclude {
+		for i := range servers {
+			included[i] = true
+		}
+	}
+	return selectedIndices(included, excluded)
+}
+
+// selectedIndices returns the included indices that aren't excluded, in
+// ascending order.
+func selectedIndices(included, excluded map[int]bool) ([]int, error) {
+	var result []int
+	for idx := range included {
+		if !excluded[idx] {
+			result = append(result, idx)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("selection matches no servers")
+	}
+	sort.Ints(result)
+
+	return result, nil
+}
+
+// matchSelectionToken returns the indices of the servers matched by a single token.
+func matchSelectionToken(token string, servers []types.MCPServer, stats map[string]types.ServerStats, verdicts map[string]types.UnusedVerdict) ([]int, error) {
+	lower := strings.ToLower(token)
+
+	switch {
+	case lower == "all":
+		return matchServers(servers, func(*types.MCPServer) bool { return true }), nil
+	case lower == "unused":
+		return matchServers(servers, func(s *types.MCPServer) bool { return IsUnused(s, stats, verdicts) }), nil
+	case lower == "used":
+		return matchServers(servers, func(s *types.MCPServer) bool { return !IsUnused(s, stats, verdicts) }), nil
+	case lower == "global":
+		return matchServers(servers, func(s *types.MCPServer) bool { return s.Scope == types.ScopeGlobal }), nil
+	case strings.HasPrefix(lower, "project:"):
+		return matchProjectToken(token[len("project:"):], servers)
+	case strings.HasPrefix(lower, "name:"):
+		return matchNameToken(token[len("name:"):], servers)
+	case isNumberToken(token):
+		return matchNumberToken(token, len(servers))
+	default:
+		return matchNameToken(token, servers)
+	}
+}
+
+// matchNumberToken resolves "N" or "N-M" to server indices.
+func matchNumberToken(token string, count int) ([]int, error) {
+	from, to, isRange := strings.Cut(token, "-")
+	start, err := strconv.Atoi(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", from)
+	}
+	end := start
+	if isRange {
+		end, err = strconv.Atoi(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q", token)
+		}
+	}
+
+	if start > end {
+		return nil, fmt.Errorf("invalid range %q: start is after end", token)
+	}
+	if start < 1 || end > count {
+		return nil, fmt.Errorf("%q is out of range (1-%d)", token, count)
+	}
+
+	indices := make([]int, 0, end-start+1)
+	for n := start; n <= end; n++ {
+		indices = append(indices, n-1)
+	}
+	return indices, nil
+}
+
+// matchNameToken matches server names exactly or as a glob pattern (case-insensitive).
+func matchNameToken(pattern string, servers []types.MCPServer) ([]int, error) {
+	lower := strings.ToLower(pattern)
+	if _, err := path.Match(lower, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	indices := matchServers(servers, func(s *types.MCPServer) bool {
+		ok, _ := path.Match(lower, strings.ToLower(s.Name))
+		return ok
+	})
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("no server matches %q", pattern)
+	}
+	return indices, nil
+}
+
+// matchProjectToken matches project-scoped servers by project path or glob.
+func matchProjectToken(pattern string, servers []types.MCPServer) ([]int, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("'project:' must be followed by a path")
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	indices := matchServers(servers, func(s *types.MCPServer) bool {
+		if s.Scope != types.ScopeProject {
+			return false
+		}
+		ok, _ := path.Match(pattern, s.ProjectPath)
+		return ok || strings.TrimSuffix(pattern, "/") == s.ProjectPath
+	})
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("no project matches %q", pattern)
+	}
+	return indices, nil
+}
+
+// matchServers returns the indices of the servers for which match returns true.
+func matchServers(servers []types.MCPServer, match func(*types.MCPServer) bool) []int {
+	var indices []int
+	for i := range servers {
+		if match(&servers[i]) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// isNumberToken reports whether token looks like "N" or "N-M".
+func isNumberToken(token string) bool {
+	if token == "" || token[0] < '0' || token[0] > '9' {
+		return false
+	}
+	for _, r := range token {
+		if (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsUnused reports whether a server is flagged as unused by its verdict or,
+// without a verdict, has no calls in the stats period. It is what the
+// 'unused' selector matches.
+func IsUnused(server *types.MCPServer, stats map[string]types.ServerStats, verdicts map[string]types.UnusedVerdict) bool {
+	if verdict, ok := verdicts[server.Key()]; ok {
+		return verdict.Flagged
+	}
+	stat, ok := stats[server.Name]
+	return !ok || stat.Calls == 0
+}
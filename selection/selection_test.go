@@ -0,0 +1,135 @@
+package selection
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestParseSelection(t *testing.T) {
+	servers := []types.MCPServer{
+		{Name: "context7", Scope: types.ScopeGlobal},
+		{Name: "puppeteer", Scope: types.ScopeGlobal},
+		{Name: "puppeteer-extra", Scope: types.ScopeProject, ProjectPath: "/work/app"},
+		{Name: "serena", Scope: types.ScopeProject, ProjectPath: "/work/app"},
+		{Name: "all", Scope: types.ScopeProject, ProjectPath: "/work/lib"},
+	}
+	stats := map[string]types.ServerStats{
+		"context7": {Name: "context7", Calls: 100},
+		"serena":   {Name: "serena", Calls: 3},
+		"all":      {Name: "all", Calls: 0},
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []int
+		wantErr string
+	}{
+		{name: "single number", input: "2", want: []int{1}},
+		{name: "space separated numbers", input: "1 3", want: []int{0, 2}},
+		{name: "comma list", input: "1,3, 5", want: []int{0, 2, 4}},
+		{name: "range", input: "2-4", want: []int{1, 2, 3}},
+		{name: "all", input: "all", want: []int{0, 1, 2, 3, 4}},
+		{name: "all with exclusion", input: "all !3", want: []int{0, 1, 3, 4}},
+		{name: "exclusions only apply to all", input: "!1 !2-3", want: []int{3, 4}},
+		{name: "exact name", input: "serena", want: []int{3}},
+		{name: "name is case-insensitive", input: "SERENA", want: []int{3}},
+		{name: "glob", input: "puppeteer*", want: []int{1, 2}},
+		{name: "glob with exclusion", input: "puppeteer* !puppeteer-extra", want: []int{1}},
+		{name: "unused keyword", input: "unused", want: []int{1, 2, 4}},
+		{name: "used keyword", input: "used", want: []int{0, 3}},
+		{name: "global keyword", input: "global", want: []int{0, 1}},
+		{name: "project keyword", input: "project:/work/app", want: []int{2, 3}},
+		{name: "project glob", input: "project:/work/*", want: []int{2, 3, 4}},
+		{name: "name prefix for keyword clash", input: "name:all", want: []int{4}},
+		{name: "duplicates collapse", input: "2 2 puppeteer", want: []int{1}},
+		{name: "empty input", input: "  ", wantErr: "empty selection"},
+		{name: "out of range", input: "1 9", wantErr: `"9" is out of range (1-5)`},
+		{name: "zero is out of range", input: "0", wantErr: `"0" is out of range (1-5)`},
+		{name: "reversed range", input: "4-2", wantErr: "start is after end"},
+		{name: "malformed range", input: "1-", wantErr: `invalid range "1-"`},
+		{name: "unknown name", input: "nope", wantErr: `no server matches "nope"`},
+		{name: "unknown project", input: "project:/nowhere", wantErr: `no project matches "/nowhere"`},
+		{name: "bare exclusion", input: "all !", wantErr: "'!' must be followed by a selector"},
+		{name: "invalid glob", input: "pup[", wantErr: `invalid pattern "pup["`},
+		{name: "everything excluded", input: "global !global", wantErr: "selection matches no servers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input, servers, stats, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse(%q) error = %v, want %q", tt.input, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.input, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Parse(%q) mismatch (-want +got):\n%s", tt.input, diff)
+			}
+		})
+	}
+}
+
+func TestParseSelection_Verdicts(t *testing.T) {
+	servers := []types.MCPServer{
+		{Name: "context7", Scope: types.ScopeGlobal},
+		{Name: "rarely-used", Scope: types.ScopeGlobal},
+	}
+	stats := map[string]types.ServerStats{
+		"context7": {Name: "context7", Calls: 2},
+	}
+	// Policy says context7 is below its minimum and rarely-used is allowed.
+	verdicts := map[string]types.UnusedVerdict{
+		"global:context7":    {Flagged: true, Reason: "2 calls in 30d, below minimum of 5"},
+		"global:rarely-used": {Reason: "allowed by policy (rarely-*)"},
+	}
+
+	got, err := Parse("unused", servers, stats, verdicts)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]int{0}, got); diff != "" {
+		t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseSelectors(t *testing.T) {
+	servers := []types.MCPServer{
+		{Name: "context7", Scope: types.ScopeGlobal},
+		{Name: "puppeteer", Scope: types.ScopeGlobal},
+		{Name: "2", Scope: types.ScopeGlobal},
+	}
+
+	tests := []struct {
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{input: "puppeteer", want: []int{1}},
+		{input: "all !context7", want: []int{1, 2}},
+		{input: "unused", want: []int{0, 1, 2}},
+		{input: "name:2", want: []int{2}},
+		{input: "2", wantErr: true},
+		{input: "1-3", wantErr: true},
+		{input: "all !1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSelectors(tt.input, servers, nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSelectors(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseSelectors(%q) mismatch (-want +got):\n%s", tt.input, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package transcript
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// NormalizeServerName applies the normalization Claude Code uses when it builds
+// mcp__{server}__{tool} names: every character outside [a-zA-Z0-9_-] becomes "_".
+// Claude Code replaces per UTF-16 code unit, so characters outside the Basic
+// Multilingual Plane (e.g. emoji) become two underscores.
+func NormalizeServerName(name string) string {
+	var b strings.Builder
+	b.Grow(len(name))
+
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		case r > 0xFFFF:
+			b.WriteString("__")
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	return b.String()
+}
+
+// Matcher maps the server part of MCP tool names back to configured server names.
+type Matcher struct {
+	names    map[string]string // normalized name -> configured name
+	prefixes []string          // normalized names, longest first
+}
+
+// NewMatcher creates a Matcher for the given configured server names.
+// When several names normalize to the same value, the name that is already
+// normalized wins, then the lexicographically smallest one.
+func NewMatcher(names []string) *Matcher {
+	m := &Matcher{names: make(map[string]string)}
+
+	sorted := make([]string, len(names))
+	copy(sorted, names)
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		normalized := NormalizeServerName(name)
+		existing, ok := m.names[normalized]
+		if !ok {
+			m.names[normalized] = name
+			m.prefixes = append(m.prefixes, normalized)
+			continue
+		}
+		if existing != normalized && name == normalized {
+			m.names[normalized] = name
+		}
+	}
+
+	sort.SliceStable(m.prefixes, func(i, j int) bool {
+		return len(m.prefixes[i]) > len(m.prefixes[j])
+	})
+
+	return m
+}
+
+// NewMatcherForServers creates a Matcher for the names of the given servers.
+func NewMatcherForServers(servers []types.MCPServer) *Matcher {
+	names := make([]string, 0, len(servers))
+	for i := range servers {
+		names = append(names, servers[i].Name)
+	}
+	return NewMatcher(names)
+}
+
+// Match resolves an MCP tool name (mcp__{server}__{tool}) to a configured server
+// name and a tool name. The longest matching configured prefix wins, so server
+// names containing "__" are split correctly. Tool names that match no configured
+// server fall back to ExtractServerName.
+func (m *Matcher) Match(toolName string) (string, string, bool) {
+	if !strings.HasPrefix(toolName, "mcp__") {
+		return "", "", false
+	}
+	rest := strings.TrimPrefix(toolName, "mcp__")
+
+	for _, prefix := range m.prefixes {
+		tool, ok := strings.CutPrefix(rest, prefix+"__")
+		if ok && tool != "" {
+			return m.names[prefix], tool, true
+		}
+	}
+
+	return ExtractServerName(toolName)
+}
+
+// ResolveCalls returns a copy of calls with server and tool names resolved
+// through the matcher.
+func (m *Matcher) ResolveCalls(calls []types.ToolCall) []types.ToolCall {
+	resolved := make([]types.ToolCall, len(calls))
+	for i, call := range calls {
+		resolved[i] = call
+		server, tool, ok := m.Match("mcp__" + call.ServerName + "__" + call.ToolName)
+		if ok {
+			resolved[i].ServerName = server
+			resolved[i].ToolName = tool
+		}
+	}
+	return resolved
+}
@@ -0,0 +1,165 @@
+package transcript
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestNormalizeServerName(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "plain name unchanged", input: "context7", want: "context7"},
+		{name: "hyphen and underscore kept", input: "my-server_2", want: "my-server_2"},
+		{name: "dot replaced", input: "my.server", want: "my_server"},
+		{name: "space replaced, case kept", input: "My Server", want: "My_Server"},
+		{name: "slash and colon replaced", input: "org/repo:tag", want: "org_repo_tag"},
+		{name: "non-ascii letter replaced", input: "café", want: "caf_"},
+		{name: "emoji becomes two underscores", input: "rocket🚀", want: "rocket__"},
+		{name: "double underscore kept", input: "a__b", want: "a__b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeServerName(tt.input)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("NormalizeServerName(%q) mismatch (-want +got):\n%s", tt.input, diff)
+			}
+		})
+	}
+}
+
+func TestMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured []string
+		toolName   string
+		wantServer string
+		wantTool   string
+		wantOK     bool
+	}{
+		{
+			name:       "plain name",
+			configured: []string{"context7"},
+			toolName:   "mcp__context7__query-docs",
+			wantServer: "context7",
+			wantTool:   "query-docs",
+			wantOK:     true,
+		},
+		{
+			name:       "dotted name maps back",
+			configured: []string{"my.server"},
+			toolName:   "mcp__my_server__do_thing",
+			wantServer: "my.server",
+			wantTool:   "do_thing",
+			wantOK:     true,
+		},
+		{
+			name:       "name with space maps back",
+			configured: []string{"My Server"},
+			toolName:   "mcp__My_Server__search",
+			wantServer: "My Server",
+			wantTool:   "search",
+			wantOK:     true,
+		},
+		{
+			name:       "double underscore in server name",
+			configured: []string{"a__b"},
+			toolName:   "mcp__a__b__tool",
+			wantServer: "a__b",
+			wantTool:   "tool",
+			wantOK:     true,
+		},
+		{
+			name:       "longest configured prefix wins",
+			configured: []string{"a", "a__b"},
+			toolName:   "mcp__a__b__tool",
+			wantServer: "a__b",
+			wantTool:   "tool",
+			wantOK:     true,
+		},
+		{
+			name:       "shorter prefix still matches its own tools",
+			configured: []string{"a", "a__b"},
+			toolName:   "mcp__a__other",
+			wantServer: "a",
+			wantTool:   "other",
+			wantOK:     true,
+		},
+		{
+			name:       "already normalized name wins a collision",
+			configured: []string{"my.server", "my_server"},
+			toolName:   "mcp__my_server__tool",
+			wantServer: "my_server",
+			wantTool:   "tool",
+			wantOK:     true,
+		},
+		{
+			name:       "unconfigured server falls back to first split",
+			configured: []string{"context7"},
+			toolName:   "mcp__plugin__x__y",
+			wantServer: "plugin",
+			wantTool:   "x__y",
+			wantOK:     true,
+		},
+		{
+			name:       "prefix without tool does not match",
+			configured: []string{"context7"},
+			toolName:   "mcp__context7__",
+			wantOK:     false,
+		},
+		{
+			name:       "non-mcp tool",
+			configured: []string{"context7"},
+			toolName:   "Read",
+			wantOK:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMatcher(tt.configured)
+			gotServer, gotTool, gotOK := m.Match(tt.toolName)
+			if gotOK != tt.wantOK {
+				t.Fatalf("Match(%q) ok = %v, want %v", tt.toolName, gotOK, tt.wantOK)
+			}
+			if gotServer != tt.wantServer {
+				t.Errorf("Match(%q) server = %q, want %q", tt.toolName, gotServer, tt.wantServer)
+			}
+			if gotTool != tt.wantTool {
+				t.Errorf("Match(%q) tool = %q, want %q", tt.toolName, gotTool, tt.wantTool)
+			}
+		})
+	}
+}
+
+func TestMatcher_ResolveCalls(t *testing.T) {
+	now := time.Now()
+	m := NewMatcherForServers([]types.MCPServer{
+		{Name: "my.server"},
+		{Name: "a__b"},
+	})
+
+	// Calls as produced by ParseLine, which splits at the first "__".
+	calls := []types.ToolCall{
+		{ServerName: "my_server", ToolName: "search", Timestamp: now},
+		{ServerName: "a", ToolName: "b__tool", Timestamp: now},
+		{ServerName: "other", ToolName: "tool", Timestamp: now},
+	}
+
+	got := m.ResolveCalls(calls)
+	want := []types.ToolCall{
+		{ServerName: "my.server", ToolName: "search", Timestamp: now},
+		{ServerName: "a__b", ToolName: "tool", Timestamp: now},
+		{ServerName: "other", ToolName: "tool", Timestamp: now},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ResolveCalls() mismatch (-want +got):\n%s", diff)
+	}
+}
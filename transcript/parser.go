@@ -36,12 +36,40 @@ type content struct {
 	Input map[string]interface{} `json:"input"`
 }
 
-// DefaultTranscriptPath returns the default path to Claude transcript logs.
+const (
+	// PathsEnv overrides the transcript directories, as a list separated by
+	// the OS path list separator (":" on Unix, ";" on Windows).
+	PathsEnv = "MCP_TIDY_TRANSCRIPTS"
+	// claudeConfigDirEnv is the variable Claude Code uses to move its
+	// configuration directory (~/.claude) elsewhere.
+	claudeConfigDirEnv = "CLAUDE_CONFIG_DIR"
+)
+
+// DefaultTranscriptPath returns the default path to Claude transcript logs:
+// $CLAUDE_CONFIG_DIR/projects or ~/.claude/projects.
 func DefaultTranscriptPath() string {
+	if dir := os.Getenv(claudeConfigDirEnv); dir != "" {
+		return filepath.Join(dir, "projects")
+	}
 	homeDir, _ := os.UserHomeDir()
 	return filepath.Join(homeDir, ".claude", "projects")
 }
 
+// DefaultTranscriptPaths returns the transcript directories to read:
+// those listed in $MCP_TIDY_TRANSCRIPTS, or DefaultTranscriptPath.
+func DefaultTranscriptPaths() []string {
+	var paths []string
+	for _, path := range filepath.SplitList(os.Getenv(PathsEnv)) {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return []string{DefaultTranscriptPath()}
+	}
+	return paths
+}
+
 // ExtractServerName extracts the server name and tool name from an MCP tool name.
 // MCP tool names follow the pattern: mcp__{server}__{tool}
 // Returns (serverName, toolName, ok).
@@ -121,6 +149,7 @@ func ParseLine(line string) ([]types.ToolCall, error) {
 		}
 
 		calls = append(calls, types.ToolCall{
+			ID:         c.ID,
 			ServerName: serverName,
 			ToolName:   toolName,
 			Timestamp:  timestamp,
@@ -179,8 +208,21 @@ func ParseFile(filePath string) ([]types.ToolCall, error) {
 // ParseDirectory parses all JSONL files in a directory and its subdirectories.
 func ParseDirectory(dirPath string) ([]types.ToolCall, error) {
 	var allCalls []types.ToolCall
+	err := walkDirectory(dirPath, func(calls []types.ToolCall) error {
+		allCalls = append(allCalls, calls...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+	return allCalls, nil
+}
 
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+// walkDirectory parses the JSONL files in a directory and its
+// subdirectories one at a time, passing the calls of each to fn. An error
+// returned by fn stops the walk and is returned.
+func walkDirectory(dirPath string, fn func(calls []types.ToolCall) error) error {
+	return filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -207,14 +249,8 @@ func ParseDirectory(dirPath string) ([]types.ToolCall, error) {
 			return nil
 		}
 
-		allCalls = append(allCalls, calls...)
-		return nil
+		return fn(calls)
 	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to walk directory: %w", err)
-	}
-
-	return allCalls, nil
 }
 
 // AggregateStats aggregates tool calls into per-server statistics.
@@ -254,8 +290,16 @@ func FilterByPeriod(calls []types.ToolCall, period types.Period) []types.ToolCal
 	if period == types.PeriodAll {
 		return calls
 	}
+	return FilterByDuration(calls, period.Duration())
+}
+
+// FilterByDuration keeps the tool calls made within the given duration.
+// A non-positive duration keeps all calls.
+func FilterByDuration(calls []types.ToolCall, duration time.Duration) []types.ToolCall {
+	if duration <= 0 {
+		return calls
+	}
 
-	duration := period.Duration()
 	cutoff := time.Now().Add(-duration)
 
 	var filtered []types.ToolCall
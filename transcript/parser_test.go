@@ -3,6 +3,7 @@ package transcript
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -90,6 +91,7 @@ func TestParseLine(t *testing.T) {
 			line: `{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"toolu_01","name":"mcp__context7__query","input":{}}]},"timestamp":"2025-01-01T10:00:00Z"}`,
 			wantCalls: []types.ToolCall{
 				{
+					ID:         "toolu_01",
 					ServerName: "context7",
 					ToolName:   "query",
 					Timestamp:  time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
@@ -306,6 +308,7 @@ func TestFilterByPeriod(t *testing.T) {
 }
 
 func TestDefaultTranscriptPath(t *testing.T) {
+	t.Setenv(claudeConfigDirEnv, "")
 	homeDir, _ := os.UserHomeDir()
 	want := filepath.Join(homeDir, ".claude", "projects")
 	got := DefaultTranscriptPath()
@@ -313,4 +316,24 @@ func TestDefaultTranscriptPath(t *testing.T) {
 	if got != want {
 		t.Errorf("DefaultTranscriptPath() = %v, want %v", got, want)
 	}
+
+	t.Setenv(claudeConfigDirEnv, "/srv/claude")
+	if got, want := DefaultTranscriptPath(), filepath.Join("/srv/claude", "projects"); got != want {
+		t.Errorf("DefaultTranscriptPath() with %s = %v, want %v", claudeConfigDirEnv, got, want)
+	}
+}
+
+func TestDefaultTranscriptPaths(t *testing.T) {
+	t.Setenv(claudeConfigDirEnv, "/srv/claude")
+
+	t.Setenv(PathsEnv, "")
+	if diff := cmp.Diff([]string{filepath.Join("/srv/claude", "projects")}, DefaultTranscriptPaths()); diff != "" {
+		t.Errorf("DefaultTranscriptPaths() mismatch (-want +got):\n%s", diff)
+	}
+
+	list := strings.Join([]string{"/a/projects", "", "/b/projects"}, string(filepath.ListSeparator))
+	t.Setenv(PathsEnv, list)
+	if diff := cmp.Diff([]string{"/a/projects", "/b/projects"}, DefaultTranscriptPaths()); diff != "" {
+		t.Errorf("DefaultTranscriptPaths() mismatch (-want +got):\n%s", diff)
+	}
 }
@@ -0,0 +1,62 @@
+package transcript
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// errStopped ends a walk when the consumer of Calls stops early.
+var errStopped = errors.New("stopped")
+
+// Transcripts reads the MCP tool calls of Claude Code's JSONL transcripts in
+// a set of directories. It is the default mcptidy.UsageSource.
+type Transcripts struct {
+	Paths []string
+}
+
+// Name describes the source for messages.
+func (t Transcripts) Name() string {
+	return "transcripts in " + strings.Join(t.Paths, ", ")
+}
+
+// Calls yields the calls of the transcripts, reading one file at a time.
+// Missing directories are skipped; an error wrapping fs.ErrNotExist is
+// yielded only when none exists. Files that can't be parsed are skipped
+// with a warning.
+func (t Transcripts) Calls(ctx context.Context) iter.Seq2[types.ToolCall, error] {
+	return func(yield func(types.ToolCall, error) bool) {
+		found := false
+		for _, dirPath := range t.Paths {
+			err := walkDirectory(dirPath, func(calls []types.ToolCall) error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				for _, call := range calls {
+					if !yield(call, nil) {
+						return errStopped
+					}
+				}
+				return nil
+			})
+			switch {
+			case errors.Is(err, errStopped):
+				return
+			case errors.Is(err, fs.ErrNotExist):
+				continue
+			case err != nil:
+				yield(types.ToolCall{}, fmt.Errorf("failed to walk directory: %w", err))
+				return
+			}
+			found = true
+		}
+		if !found && len(t.Paths) > 0 {
+			yield(types.ToolCall{}, fmt.Errorf("no transcript directory found (%s): %w", strings.Join(t.Paths, ", "), fs.ErrNotExist))
+		}
+	}
+}
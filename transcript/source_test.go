@@ -0,0 +1,76 @@
+package transcript
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTranscripts_Calls(t *testing.T) {
+	tests := []struct {
+		name      string
+		paths     []string
+		stopAfter int
+		wantIDs   []string
+		wantErr   error
+	}{
+		{
+			name:    "every call",
+			paths:   []string{"../testdata/projects"},
+			wantIDs: []string{"toolu_01", "toolu_02", "toolu_04", "toolu_10", "toolu_11"},
+		},
+		{
+			name:    "missing directories are skipped",
+			paths:   []string{"/nonexistent/path", "../testdata/projects"},
+			wantIDs: []string{"toolu_01", "toolu_02", "toolu_04", "toolu_10", "toolu_11"},
+		},
+		{
+			name:      "consumer stops early",
+			paths:     []string{"../testdata/projects", "../testdata/projects"},
+			stopAfter: 2,
+			wantIDs:   []string{"toolu_01", "toolu_02"},
+		},
+		{
+			name:    "no directory exists",
+			paths:   []string{"/nonexistent/path"},
+			wantErr: fs.ErrNotExist,
+		},
+		{name: "no paths"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIDs []string
+			var gotErr error
+			for call, err := range (Transcripts{Paths: tt.paths}).Calls(context.Background()) {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				gotIDs = append(gotIDs, call.ID)
+				if len(gotIDs) == tt.stopAfter {
+					break
+				}
+			}
+
+			if !errors.Is(gotErr, tt.wantErr) || (gotErr == nil) != (tt.wantErr == nil) {
+				t.Fatalf("Calls() error = %v, want %v", gotErr, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("Calls() IDs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, err := range (Transcripts{Paths: []string{"../testdata/projects"}}).Calls(ctx) {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Calls() with a canceled context error = %v, want context.Canceled", err)
+		}
+		break
+	}
+}
@@ -3,6 +3,9 @@ package types
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,6 +18,12 @@ const (
 	ScopeGlobal Scope = iota
 	// ScopeProject indicates a project-specific MCP server.
 	ScopeProject
+	// ScopeMCPJSON indicates a server shared through a project's .mcp.json
+	// file. mcp-tidy reads these but never writes .mcp.json.
+	ScopeMCPJSON
+	// ScopeManaged indicates a server an administrator deployed through
+	// managed-mcp.json. Users can't change or remove these.
+	ScopeManaged
 )
 
 // String returns the string representation of the scope.
@@ -24,6 +33,37 @@ func (s Scope) String() string {
 		return "global"
 	case ScopeProject:
 		return "project"
+	case ScopeMCPJSON:
+		return "mcpjson"
+	case ScopeManaged:
+		return "managed"
+	default:
+		return "unknown"
+	}
+}
+
+// Status tells whether Claude Code actually starts a configured server.
+type Status int
+
+const (
+	// StatusActive indicates a server Claude Code starts.
+	StatusActive Status = iota
+	// StatusDisabled indicates a .mcp.json server turned off in a settings file.
+	StatusDisabled
+	// StatusPending indicates a .mcp.json server that is neither approved nor
+	// rejected yet; Claude Code asks before starting it.
+	StatusPending
+)
+
+// String returns the string representation of the status.
+func (s Status) String() string {
+	switch s {
+	case StatusActive:
+		return "active"
+	case StatusDisabled:
+		return "disabled"
+	case StatusPending:
+		return "pending"
 	default:
 		return "unknown"
 	}
@@ -37,6 +77,11 @@ const (
 	ServerTypeStdio ServerType = iota
 	// ServerTypeHTTP indicates an HTTP-based MCP server.
 	ServerTypeHTTP
+	// ServerTypeSSE indicates an MCP server using the SSE transport.
+	ServerTypeSSE
+	// ServerTypeUnknown indicates a type mcp-tidy doesn't know, such as a
+	// transport added after this version. MCPServer.TypeStr keeps its name.
+	ServerTypeUnknown
 )
 
 // String returns the string representation of the server type.
@@ -46,11 +91,33 @@ func (t ServerType) String() string {
 		return "stdio"
 	case ServerTypeHTTP:
 		return "http"
+	case ServerTypeSSE:
+		return "sse"
 	default:
 		return "unknown"
 	}
 }
 
+// IsRemote reports whether servers of this type are reached through a URL.
+func (t ServerType) IsRemote() bool {
+	return t == ServerTypeHTTP || t == ServerTypeSSE
+}
+
+// ParseServerType converts the "type" value of a server entry. A missing
+// type means stdio, as in Claude Code; unrecognized types are ServerTypeUnknown.
+func ParseServerType(s string) ServerType {
+	switch s {
+	case "", "stdio":
+		return ServerTypeStdio
+	case "http":
+		return ServerTypeHTTP
+	case "sse":
+		return ServerTypeSSE
+	default:
+		return ServerTypeUnknown
+	}
+}
+
 // MCPServer represents an MCP server configuration.
 type MCPServer struct {
 	Name        string            `json:"name"`
@@ -63,30 +130,118 @@ type MCPServer struct {
 	Headers     map[string]string `json:"headers,omitempty"`
 	Scope       Scope             `json:"-"`
 	ProjectPath string            `json:"-"`
+	// Status is whether Claude Code starts the server, and StatusReason
+	// names the setting that decided it, if any.
+	Status       Status `json:"-"`
+	StatusReason string `json:"-"`
+	// Client names the client whose config holds the server, such as
+	// "claude-desktop"; empty means Claude Code.
+	Client string `json:"-"`
 }
 
 // CommandString returns a human-readable representation of the server command.
-// For HTTP servers, returns the URL with [http] prefix.
+// For HTTP and SSE servers, returns the URL with a [http] or [sse] prefix.
 // For stdio servers, returns the command with arguments.
+// For unknown types, returns the URL or command prefixed with the raw type.
 func (s *MCPServer) CommandString() string {
-	if s.Type == ServerTypeHTTP {
-		return fmt.Sprintf("[http] %s", s.URL)
+	if s.Type.IsRemote() {
+		return fmt.Sprintf("[%s] %s", s.Type, s.URL)
+	}
+
+	command := s.Command
+	if len(s.Args) > 0 {
+		command = fmt.Sprintf("%s %s", s.Command, strings.Join(s.Args, " "))
+	}
+	if s.Type == ServerTypeUnknown {
+		target := command
+		if target == "" {
+			target = s.URL
+		}
+		return strings.TrimSpace(fmt.Sprintf("[%s] %s", s.TypeName(), target))
 	}
+	return command
+}
 
-	if len(s.Args) == 0 {
-		return s.Command
+// TypeName returns the name of the server's type: the raw type for unknown
+// types, so they are shown as configured rather than guessed.
+func (s *MCPServer) TypeName() string {
+	if s.Type == ServerTypeUnknown && s.TypeStr != "" {
+		return s.TypeStr
 	}
-	return fmt.Sprintf("%s %s", s.Command, strings.Join(s.Args, " "))
+	return s.Type.String()
 }
 
 // ScopeString returns the scope as a display string.
 // For global scope, returns "global".
 // For project scope, returns the project path.
+// For .mcp.json servers, returns the path of the .mcp.json file.
+// For managed servers, returns "managed".
 func (s *MCPServer) ScopeString() string {
-	if s.Scope == ScopeGlobal {
+	switch s.Scope {
+	case ScopeGlobal:
 		return "global"
+	case ScopeManaged:
+		return "managed"
+	case ScopeMCPJSON:
+		return filepath.Join(s.ProjectPath, ".mcp.json")
+	default:
+		return s.ProjectPath
 	}
-	return s.ProjectPath
+}
+
+// InProject reports whether the server belongs to a project, through
+// ~/.claude.json or the project's .mcp.json.
+func (s *MCPServer) InProject() bool {
+	return (s.Scope == ScopeProject || s.Scope == ScopeMCPJSON) && s.ProjectPath != ""
+}
+
+// Key returns an identifier that is unique across scopes and clients,
+// so servers with the same name in different scopes can be told apart.
+func (s *MCPServer) Key() string {
+	key := s.Scope.String() + ":" + s.ProjectPath + ":" + s.Name
+	if s.Scope == ScopeGlobal || s.Scope == ScopeManaged {
+		key = s.Scope.String() + ":" + s.Name
+	}
+	if s.Client != "" {
+		key = s.Client + ":" + key
+	}
+	return key
+}
+
+// ConnectionChanges describes how the connection fields (type, command, args
+// and URL) change when s is replaced by target, one "field: old → new" entry
+// per changed field. A missing type counts as stdio, as in Claude Code.
+func (s *MCPServer) ConnectionChanges(target *MCPServer) []string {
+	var changes []string
+	if from, to := s.typeOrDefault(), target.typeOrDefault(); from != to {
+		changes = append(changes, fmt.Sprintf("type: %s → %s", from, to))
+	}
+	if s.Command != target.Command {
+		changes = append(changes, fmt.Sprintf("command: %q → %q", s.Command, target.Command))
+	}
+	if !slices.Equal(s.Args, target.Args) {
+		changes = append(changes, fmt.Sprintf("args: [%s] → [%s]", strings.Join(s.Args, " "), strings.Join(target.Args, " ")))
+	}
+	if s.URL != target.URL {
+		changes = append(changes, fmt.Sprintf("url: %q → %q", s.URL, target.URL))
+	}
+	return changes
+}
+
+// typeOrDefault returns the configured type, or stdio when none is set.
+func (s *MCPServer) typeOrDefault() string {
+	if s.TypeStr == "" {
+		return ServerTypeStdio.String()
+	}
+	return s.TypeStr
+}
+
+// UnusedVerdict records whether a configured server is flagged as unused, and why.
+type UnusedVerdict struct {
+	Flagged     bool   // counts as unused
+	Protected   bool   // must never be removed
+	Unavailable bool   // usage can't be measured, as the server's client keeps no transcripts
+	Reason      string // human-readable explanation of the decision
 }
 
 // ServerStats holds usage statistics for an MCP server.
@@ -98,13 +253,28 @@ type ServerStats struct {
 }
 
 // IsUnused returns true if the server hasn't been used within the given period.
+// A non-positive period means all time, so only never-used servers are unused.
 func (s ServerStats) IsUnused(period time.Duration) bool {
 	if s.LastUsed.IsZero() {
 		return true
 	}
+	if period <= 0 {
+		return false
+	}
 	return time.Since(s.LastUsed) > period
 }
 
+// Category classifies the server by whether it is configured and used within the period.
+func (s ServerStats) Category(configured bool, period time.Duration) UsageCategory {
+	if !configured {
+		return CategoryNotConfigured
+	}
+	if s.IsUnused(period) {
+		return CategoryUnused
+	}
+	return CategoryUsed
+}
+
 // LastUsedString returns a human-readable representation of when the server was last used.
 func (s ServerStats) LastUsedString() string {
 	if s.LastUsed.IsZero() {
@@ -140,8 +310,39 @@ func (s ServerStats) LastUsedString() string {
 	return "just now"
 }
 
+// UsageCategory classifies a server by whether it is configured and whether it is used.
+type UsageCategory int
+
+const (
+	// CategoryUsed indicates a configured server that was used within the period.
+	CategoryUsed UsageCategory = iota
+	// CategoryUnused indicates a configured server that was not used within the period.
+	CategoryUnused
+	// CategoryNotConfigured indicates a server that appears in transcripts but not in any
+	// config, either because it was removed or because it comes from another source
+	// such as a plugin or .mcp.json.
+	CategoryNotConfigured
+)
+
+// String returns the string representation of the usage category.
+func (c UsageCategory) String() string {
+	switch c {
+	case CategoryUsed:
+		return "used"
+	case CategoryUnused:
+		return "unused"
+	case CategoryNotConfigured:
+		return "not-configured"
+	default:
+		return "unknown"
+	}
+}
+
 // ToolCall represents a single MCP tool invocation extracted from logs.
 type ToolCall struct {
+	// ID is the tool-use ID Claude Code gives the call (toolu_...), if
+	// known. Sources that record the same call share it.
+	ID         string
 	ServerName string
 	ToolName   string
 	Timestamp  time.Time
@@ -192,3 +393,30 @@ func ParsePeriod(s string) Period {
 		return Period30Days
 	}
 }
+
+// ParsePeriodDuration parses a period of any length: "all", a number of days
+// or weeks such as "7d", "30d" or "12w", or a Go duration such as "36h".
+// "all" returns 0, meaning no time limit. It is the grammar of --period and
+// of the period settings in policy files.
+func ParsePeriodDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "all" {
+		return 0, nil
+	}
+
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count <= 0 {
+				return 0, fmt.Errorf("invalid period %q (expected e.g. 7d, 30d, 12w or all)", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid period %q (expected e.g. 7d, 30d, 12w or all)", s)
+	}
+	return d, nil
+}
@@ -23,6 +23,16 @@ func TestScope_String(t *testing.T) {
 			scope: ScopeProject,
 			want:  "project",
 		},
+		{
+			name:  "mcpjson scope",
+			scope: ScopeMCPJSON,
+			want:  "mcpjson",
+		},
+		{
+			name:  "managed scope",
+			scope: ScopeManaged,
+			want:  "managed",
+		},
 	}
 
 	for _, tt := range tests {
@@ -51,6 +61,16 @@ func TestServerType_String(t *testing.T) {
 			serverType: ServerTypeHTTP,
 			want:       "http",
 		},
+		{
+			name:       "sse type",
+			serverType: ServerTypeSSE,
+			want:       "sse",
+		},
+		{
+			name:       "unknown type",
+			serverType: ServerTypeUnknown,
+			want:       "unknown",
+		},
 	}
 
 	for _, tt := range tests {
@@ -78,6 +98,25 @@ func TestMCPServer_CommandString(t *testing.T) {
 			},
 			want: "[http] https://mcp.context7.com/mcp",
 		},
+		{
+			name: "sse server returns url",
+			server: MCPServer{
+				Name: "events",
+				Type: ServerTypeSSE,
+				URL:  "http://localhost:8080/sse",
+			},
+			want: "[sse] http://localhost:8080/sse",
+		},
+		{
+			name: "unknown type keeps the raw type",
+			server: MCPServer{
+				Name:    "future",
+				Type:    ServerTypeUnknown,
+				TypeStr: "websocket",
+				URL:     "wss://example.com/mcp",
+			},
+			want: "[websocket] wss://example.com/mcp",
+		},
 		{
 			name: "stdio server returns command with args",
 			server: MCPServer{
@@ -132,6 +171,15 @@ func TestMCPServer_ScopeString(t *testing.T) {
 			},
 			want: "/Users/xxx/github/my-project",
 		},
+		{
+			name: "mcpjson scope names the file",
+			server: MCPServer{
+				Name:        "github",
+				Scope:       ScopeMCPJSON,
+				ProjectPath: "/Users/xxx/github/my-project",
+			},
+			want: "/Users/xxx/github/my-project/.mcp.json",
+		},
 	}
 
 	for _, tt := range tests {
@@ -144,6 +192,137 @@ func TestMCPServer_ScopeString(t *testing.T) {
 	}
 }
 
+func TestMCPServer_Key(t *testing.T) {
+	tests := []struct {
+		name   string
+		server MCPServer
+		want   string
+	}{
+		{
+			name:   "global server",
+			server: MCPServer{Name: "context7", Scope: ScopeGlobal},
+			want:   "global:context7",
+		},
+		{
+			name:   "project server includes path",
+			server: MCPServer{Name: "context7", Scope: ScopeProject, ProjectPath: "/work/app"},
+			want:   "project:/work/app:context7",
+		},
+		{
+			name:   "mcpjson server is distinct from a project server",
+			server: MCPServer{Name: "context7", Scope: ScopeMCPJSON, ProjectPath: "/work/app"},
+			want:   "mcpjson:/work/app:context7",
+		},
+		{
+			name:   "managed server",
+			server: MCPServer{Name: "context7", Scope: ScopeManaged},
+			want:   "managed:context7",
+		},
+		{
+			name:   "server of another client",
+			server: MCPServer{Name: "context7", Scope: ScopeGlobal, Client: "claude-desktop"},
+			want:   "claude-desktop:global:context7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.server.Key()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("MCPServer.Key() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseServerType(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ServerType
+	}{
+		{in: "", want: ServerTypeStdio},
+		{in: "stdio", want: ServerTypeStdio},
+		{in: "http", want: ServerTypeHTTP},
+		{in: "sse", want: ServerTypeSSE},
+		{in: "websocket", want: ServerTypeUnknown},
+		{in: "HTTP", want: ServerTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := ParseServerType(tt.in); got != tt.want {
+			t.Errorf("ParseServerType(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMCPServer_TypeName(t *testing.T) {
+	tests := []struct {
+		server MCPServer
+		want   string
+	}{
+		{server: MCPServer{Type: ServerTypeStdio}, want: "stdio"},
+		{server: MCPServer{Type: ServerTypeSSE, TypeStr: "sse"}, want: "sse"},
+		{server: MCPServer{Type: ServerTypeUnknown, TypeStr: "websocket"}, want: "websocket"},
+		{server: MCPServer{Type: ServerTypeUnknown}, want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.server.TypeName(); got != tt.want {
+			t.Errorf("TypeName() of %+v = %q, want %q", tt.server, got, tt.want)
+		}
+	}
+}
+
+func TestMCPServer_ConnectionChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		from MCPServer
+		to   MCPServer
+		want []string
+	}{
+		{
+			name: "identical",
+			from: MCPServer{Name: "a", Command: "npx", Args: []string{"pkg"}},
+			to:   MCPServer{Name: "a", Command: "npx", Args: []string{"pkg"}},
+		},
+		{
+			name: "missing type counts as stdio",
+			from: MCPServer{Name: "a", Command: "npx"},
+			to:   MCPServer{Name: "a", TypeStr: "stdio", Command: "npx"},
+		},
+		{
+			name: "env is ignored",
+			from: MCPServer{Name: "a", Command: "npx", Env: map[string]string{"TOKEN": "x"}},
+			to:   MCPServer{Name: "a", Command: "npx"},
+		},
+		{
+			name: "args changed",
+			from: MCPServer{Name: "a", Command: "npx", Args: []string{"pkg@1"}},
+			to:   MCPServer{Name: "a", Command: "npx", Args: []string{"pkg@2"}},
+			want: []string{"args: [pkg@1] → [pkg@2]"},
+		},
+		{
+			name: "stdio to http",
+			from: MCPServer{Name: "a", Command: "npx"},
+			to:   MCPServer{Name: "a", TypeStr: "http", URL: "https://example.com/mcp"},
+			want: []string{
+				"type: stdio → http",
+				`command: "npx" → ""`,
+				`url: "" → "https://example.com/mcp"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.from.ConnectionChanges(&tt.to)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("MCPServer.ConnectionChanges() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestServerStats_IsUnused(t *testing.T) {
 	now := time.Now()
 	twentyNineDaysAgo := now.AddDate(0, 0, -29)
@@ -185,6 +364,16 @@ func TestServerStats_IsUnused(t *testing.T) {
 			period: 30 * 24 * time.Hour,
 			want:   true,
 		},
+		{
+			name: "any usage counts when period is all time",
+			stats: ServerStats{
+				Name:     "old-server",
+				Calls:    5,
+				LastUsed: now.AddDate(-1, 0, 0),
+			},
+			period: 0,
+			want:   false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -197,6 +386,52 @@ func TestServerStats_IsUnused(t *testing.T) {
 	}
 }
 
+func TestServerStats_Category(t *testing.T) {
+	now := time.Now()
+	period := 30 * 24 * time.Hour
+
+	tests := []struct {
+		name       string
+		stats      ServerStats
+		configured bool
+		want       UsageCategory
+	}{
+		{
+			name:       "configured and used",
+			stats:      ServerStats{Name: "context7", Calls: 10, LastUsed: now},
+			configured: true,
+			want:       CategoryUsed,
+		},
+		{
+			name:       "configured and never used",
+			stats:      ServerStats{Name: "puppeteer"},
+			configured: true,
+			want:       CategoryUnused,
+		},
+		{
+			name:       "configured and used outside period",
+			stats:      ServerStats{Name: "old", Calls: 3, LastUsed: now.AddDate(0, 0, -60)},
+			configured: true,
+			want:       CategoryUnused,
+		},
+		{
+			name:       "used but not configured",
+			stats:      ServerStats{Name: "plugin-server", Calls: 5, LastUsed: now},
+			configured: false,
+			want:       CategoryNotConfigured,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.stats.Category(tt.configured, period)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ServerStats.Category() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestServerStats_LastUsedString(t *testing.T) {
 	now := time.Now()
 
@@ -248,3 +483,67 @@ func TestServerStats_LastUsedString(t *testing.T) {
 		})
 	}
 }
+
+func TestStatus_String(t *testing.T) {
+	tests := []struct {
+		status Status
+		want   string
+	}{
+		{status: StatusActive, want: "active"},
+		{status: StatusDisabled, want: "disabled"},
+		{status: StatusPending, want: "pending"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.String(); got != tt.want {
+			t.Errorf("Status(%d).String() = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Period
+	}{
+		{input: "7d", want: Period7Days},
+		{input: "90D", want: Period90Days},
+		{input: "all", want: PeriodAll},
+		{input: "12w", want: Period30Days},
+	}
+
+	for _, tt := range tests {
+		if got := ParsePeriod(tt.input); got != tt.want {
+			t.Errorf("ParsePeriod(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParsePeriodDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "7d", want: 7 * 24 * time.Hour},
+		{input: "2w", want: 14 * 24 * time.Hour},
+		{input: "36h", want: 36 * time.Hour},
+		{input: "ALL", want: 0},
+		{input: "0d", wantErr: true},
+		{input: "-1h", wantErr: true},
+		{input: "90d", want: 90 * 24 * time.Hour},
+		{input: "monthly", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePeriodDuration(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePeriodDuration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePeriodDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
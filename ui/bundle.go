@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nnnkkk7/mcp-tidy/bundle"
+)
+
+// RenderImportPlan prints what an import does with each server.
+func RenderImportPlan(w io.Writer, plan []bundle.PlannedServer) {
+	if len(plan) == 0 {
+		fmt.Fprintln(w, "The file contains no servers.")
+		return
+	}
+
+	fmt.Fprintf(w, "\nImporting %d server(s):\n\n", len(plan))
+	for i := range plan {
+		renderPlannedServer(w, &plan[i])
+	}
+	fmt.Fprintln(w)
+}
+
+// renderPlannedServer prints the row of one server of an import plan.
+func renderPlannedServer(w io.Writer, p *bundle.PlannedServer) {
+	name := p.Server.Name
+	if p.Action == bundle.ActionRename {
+		name = fmt.Sprintf("%s (as %s)", p.OriginalName, p.Server.Name)
+	}
+	fmt.Fprintf(w, "  %s  %s %s", importMarker(p.Action), name, scopeLabel(&p.Server))
+	switch p.Action {
+	case bundle.ActionAdd, bundle.ActionRename:
+		fmt.Fprintf(w, "  %s", dimColor.Sprint(p.Server.CommandString()))
+	case bundle.ActionSkip:
+		fmt.Fprintf(w, "  %s", dimColor.Sprint("already configured differently; use --on-conflict overwrite or rename"))
+	case bundle.ActionDenied:
+		fmt.Fprintf(w, "  %s", dimColor.Sprint(p.Denied))
+	}
+	fmt.Fprintln(w)
+
+	if p.Action == bundle.ActionOverwrite {
+		for _, change := range p.Changes {
+			fmt.Fprintf(w, "        %s\n", change)
+		}
+	}
+	if len(p.Unresolved) > 0 && p.Action.Writes() {
+		fmt.Fprintf(w, "        %s\n", warningColor.Sprintf("unresolved: %s (set them in the environment or edit the config)", strings.Join(p.Unresolved, ", ")))
+	}
+}
+
+// importMarker returns the colored label of an import action.
+func importMarker(action bundle.Action) string {
+	switch action {
+	case bundle.ActionAdd:
+		return successColor.Sprint("+ add      ")
+	case bundle.ActionOverwrite:
+		return warningColor.Sprint("~ overwrite")
+	case bundle.ActionRename:
+		return successColor.Sprint("+ rename   ")
+	case bundle.ActionSkip:
+		return dimColor.Sprint("= skip     ")
+	case bundle.ActionDenied:
+		return errorColor.Sprint("✗ denied   ")
+	default:
+		return dimColor.Sprint("= unchanged")
+	}
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/nnnkkk7/mcp-tidy/check"
+)
+
+// RenderCheckResult prints the violations of a check, grouped by rule.
+func RenderCheckResult(w io.Writer, path string, result *check.Result) {
+	fmt.Fprintf(w, "Checked %d server(s) in %s against %d rule(s)\n", result.Servers, path, len(result.Rules))
+
+	if result.OK() {
+		successColor.Fprintln(w, "✓ No violations")
+		return
+	}
+
+	for _, rule := range result.Rules {
+		first := true
+		for _, v := range result.Violations {
+			if v.Rule != rule {
+				continue
+			}
+			if first {
+				fmt.Fprintf(w, "\n── %s ──\n", rule)
+				first = false
+			}
+			line := "  " + warningColor.Sprint("✗ ")
+			if v.Server != "" {
+				line += fmt.Sprintf("%s [%s]: ", v.Server, locationLabel(v.Scope, v.Project))
+			}
+			fmt.Fprintln(w, line+v.Message)
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d violation(s)\n", len(result.Violations))
+}
+
+// locationLabel returns the project path for project servers and the scope otherwise.
+func locationLabel(scope, project string) string {
+	if project != "" {
+		return project
+	}
+	return scope
+}
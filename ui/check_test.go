@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/nnnkkk7/mcp-tidy/check"
+)
+
+func TestRenderCheckResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  check.Result
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "no violations",
+			result:  check.Result{Rules: []string{check.RuleMaxServers}, Servers: 2},
+			want:    []string{"Checked 2 server(s) in .mcp.json against 1 rule(s)", "No violations"},
+			notWant: []string{"violation(s)"},
+		},
+		{
+			name: "violations grouped by rule",
+			result: check.Result{
+				Rules:   []string{check.RuleMaxServers, check.RuleBannedHost},
+				Servers: 12,
+				Violations: []check.Violation{
+					{Rule: check.RuleMaxServers, Message: "12 servers configured, maximum is 10"},
+					{Rule: check.RuleBannedHost, Server: "internal", Scope: "project", Project: "/work/app", Message: `host "mcp.corp.example.com" is banned (*.example.com)`},
+				},
+			},
+			want: []string{
+				"── max-servers ──",
+				"12 servers configured, maximum is 10",
+				"── banned-host ──",
+				"internal [/work/app]: host",
+				"2 violation(s)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			RenderCheckResult(&buf, ".mcp.json", &tt.result)
+			output := buf.String()
+
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("output missing %q\nGot:\n%s", want, output)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(output, notWant) {
+					t.Errorf("output should not contain %q\nGot:\n%s", notWant, output)
+				}
+			}
+		})
+	}
+}
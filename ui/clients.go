@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// clientColumnWidth is the width of a client column in the client overview.
+const clientColumnWidth = 16
+
+// RenderClientOverview renders which clients define each server, one column
+// per client. A cell shows ✓, with the number of definitions when the client
+// has several (e.g. global and in a project), and ≠ when a definition
+// connects differently than the server's first definition.
+func RenderClientOverview(w io.Writer, servers []types.MCPServer, clients []config.Client) {
+	if len(servers) == 0 {
+		fmt.Fprintln(w, "No MCP servers configured.")
+		return
+	}
+
+	byName := make(map[string][]types.MCPServer)
+	for i := range servers {
+		byName[servers[i].Name] = append(byName[servers[i].Name], servers[i])
+	}
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "\nMCP Servers by Client (%d servers in %d clients)\n", len(names), len(clients))
+	fmt.Fprintln(w, strings.Repeat("─", tableWidth))
+	header := fmt.Sprintf("  %-14s", "NAME")
+	for _, client := range clients {
+		header += " " + padRight(strings.ToUpper(client.Title()), clientColumnWidth)
+	}
+	fmt.Fprintln(w, strings.TrimRight(header, " "))
+
+	differs := false
+	for _, name := range names {
+		row := fmt.Sprintf("  %-14s", name)
+		first := firstDefinition(byName[name], clients)
+		for _, client := range clients {
+			count, changed := 0, false
+			definitions := byName[name]
+			for i := range definitions {
+				if clientName(&definitions[i]) != client.Name() {
+					continue
+				}
+				count++
+				changed = changed || len(first.ConnectionChanges(&definitions[i])) > 0
+			}
+
+			cell := dimColor.Sprint(padRight("-", clientColumnWidth))
+			if count > 0 {
+				text := "✓"
+				if count > 1 {
+					text += fmt.Sprintf(" %d", count)
+				}
+				if changed {
+					text += " ≠"
+					differs = true
+				}
+				cell = successColor.Sprint(padRight(text, clientColumnWidth))
+			}
+			row += " " + cell
+		}
+		fmt.Fprintln(w, row)
+	}
+
+	if differs {
+		fmt.Fprintf(w, "\n%s\n", dimColor.Sprint("≠ connects differently than in the first client defining it (see 'mcp-tidy list')"))
+	}
+	fmt.Fprintln(w)
+}
+
+// firstDefinition returns the definition of the first client, in the order
+// of clients, that defines the server.
+func firstDefinition(definitions []types.MCPServer, clients []config.Client) *types.MCPServer {
+	for _, client := range clients {
+		for i := range definitions {
+			if clientName(&definitions[i]) == client.Name() {
+				return &definitions[i]
+			}
+		}
+	}
+	return &definitions[0]
+}
+
+// padRight pads s with spaces to width characters.
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+var (
+	diffAddColor    = color.New(color.FgGreen)
+	diffRemoveColor = color.New(color.FgRed)
+	diffHunkColor   = color.New(color.FgCyan)
+	diffHeaderColor = color.New(color.Bold)
+)
+
+// RenderDiff prints a unified diff with added lines in green, removed lines
+// in red and hunk headers in cyan.
+func RenderDiff(w io.Writer, patch string) {
+	if patch == "" {
+		return
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(patch, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			diffHeaderColor.Fprintln(w, line)
+		case strings.HasPrefix(line, "@@"):
+			diffHunkColor.Fprintln(w, line)
+		case strings.HasPrefix(line, "+"):
+			diffAddColor.Fprintln(w, line)
+		case strings.HasPrefix(line, "-"):
+			diffRemoveColor.Fprintln(w, line)
+		default:
+			fmt.Fprintln(w, line)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/nnnkkk7/mcp-tidy/manifest"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// RenderDrift prints the differences between a manifest and the config.
+func RenderDrift(w io.Writer, manifestPath string, drifts []manifest.Drift) {
+	if len(drifts) == 0 {
+		successColor.Fprintf(w, "✓ Config matches %s\n", manifestPath)
+		return
+	}
+
+	fmt.Fprintf(w, "Drift from %s (%d difference(s))\n\n", manifestPath, len(drifts))
+
+	for i := range drifts {
+		d := &drifts[i]
+		var marker string
+		switch d.Kind {
+		case manifest.KindMissing:
+			marker = successColor.Sprint("+ missing")
+		case manifest.KindExtra:
+			marker = warningColor.Sprint("- extra  ")
+		default:
+			marker = warningColor.Sprint("~ changed")
+		}
+
+		fmt.Fprintf(w, "  %s  %s %s", marker, d.Server.Name, scopeLabel(&d.Server))
+		if d.Kind != manifest.KindChanged {
+			fmt.Fprintf(w, "  %s", dimColor.Sprint(d.Server.CommandString()))
+		}
+		fmt.Fprintln(w)
+		for _, change := range d.Changes {
+			fmt.Fprintf(w, "        %s\n", change)
+		}
+	}
+}
+
+// RenderApplySummary prints the servers written to and removed from the config.
+func RenderApplySummary(w io.Writer, written, removed []types.MCPServer) {
+	fmt.Fprintln(w)
+	for i := range written {
+		successColor.Fprintf(w, "✓ Written: %s %s\n", written[i].Name, scopeLabel(&written[i]))
+	}
+	for i := range removed {
+		successColor.Fprintf(w, "✓ Removed: %s %s\n", removed[i].Name, scopeLabel(&removed[i]))
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+	"github.com/nnnkkk7/mcp-tidy/journal"
+)
+
+// RenderHistory prints journal entries, oldest first.
+func RenderHistory(w io.Writer, entries []journal.Entry, undoneBy map[int]int) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No operations recorded.")
+		return
+	}
+
+	for i := range entries {
+		e := &entries[i]
+		fmt.Fprintf(w, "#%-3d %s  %s", e.ID, dimColor.Sprint(e.Time.Local().Format("2006-01-02 15:04:05")), e.Command)
+		switch {
+		case undoneBy[e.ID] > 0:
+			fmt.Fprintf(w, "  %s", warningColor.Sprintf("(undone by #%d)", undoneBy[e.ID]))
+		case e.UndoOf > 0:
+			fmt.Fprintf(w, "  %s", dimColor.Sprintf("(undo of #%d)", e.UndoOf))
+		}
+		fmt.Fprintln(w)
+		RenderServerChanges(w, e.Changes)
+	}
+}
+
+// RenderServerChanges prints one line per changed server entry.
+func RenderServerChanges(w io.Writer, changes []config.ServerChange) {
+	for i := range changes {
+		var marker string
+		switch changes[i].Kind() {
+		case "added":
+			marker = successColor.Sprint("+ added  ")
+		case "removed":
+			marker = warningColor.Sprint("- removed")
+		default:
+			marker = warningColor.Sprint("~ changed")
+		}
+		if changes[i].Scope == config.SettingScope {
+			fmt.Fprintf(w, "      %s  %s [setting]\n", marker, changes[i].Name)
+			continue
+		}
+		server := changes[i].Server()
+		fmt.Fprintf(w, "      %s  %s %s\n", marker, server.Name, scopeLabel(&server))
+	}
+}
@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// ProfileServers holds the servers configured in one profile and, for stats,
+// their usage.
+type ProfileServers struct {
+	Profile  string
+	Servers  []types.MCPServer
+	Stats    map[string]types.ServerStats   // keyed by server name
+	Verdicts map[string]types.UnusedVerdict // keyed by MCPServer.Key()
+}
+
+// profileRows returns every server of every profile, ordered by profile,
+// then name, then scope.
+func profileRows(profiles []ProfileServers) ([]*ProfileServers, []types.MCPServer) {
+	var owners []*ProfileServers
+	var servers []types.MCPServer
+	for i := range profiles {
+		sorted := make([]types.MCPServer, len(profiles[i].Servers))
+		copy(sorted, profiles[i].Servers)
+		sort.SliceStable(sorted, func(a, b int) bool {
+			if sorted[a].Name != sorted[b].Name {
+				return sorted[a].Name < sorted[b].Name
+			}
+			return sorted[a].ScopeString() < sorted[b].ScopeString()
+		})
+		for range sorted {
+			owners = append(owners, &profiles[i])
+		}
+		servers = append(servers, sorted...)
+	}
+	return owners, servers
+}
+
+// RenderProfileServerTable renders the servers of several profiles in one
+// table with a profile column.
+func RenderProfileServerTable(w io.Writer, profiles []ProfileServers) {
+	owners, servers := profileRows(profiles)
+	if len(servers) == 0 {
+		fmt.Fprintf(w, "No MCP servers configured in %d profile(s).\n", len(profiles))
+		return
+	}
+
+	fmt.Fprintf(w, "\nMCP Servers (%d configured in %d profiles)\n", len(servers), len(profiles))
+	fmt.Fprintln(w, strings.Repeat("─", tableWidth))
+	fmt.Fprintf(w, "  %-10s %-14s %-26s %s\n", "PROFILE", "NAME", "SCOPE", "COMMAND")
+
+	for i := range servers {
+		fmt.Fprintf(w, "  %-10s %-14s %-26s %s%s\n",
+			owners[i].Profile, servers[i].Name, shortenPath(servers[i].ScopeString(), 26), truncate(servers[i].CommandString(), 30),
+			statusLabel(&servers[i]))
+	}
+	fmt.Fprintln(w)
+}
+
+// RenderProfileStatsTable renders the usage of the servers of several
+// profiles in one table with a profile column. Each profile's servers are
+// matched to the calls in that profile's transcripts.
+func RenderProfileStatsTable(w io.Writer, profiles []ProfileServers, period time.Duration) {
+	owners, servers := profileRows(profiles)
+	if len(servers) == 0 {
+		fmt.Fprintf(w, "No MCP servers configured in %d profile(s).\n", len(profiles))
+		return
+	}
+
+	maxCalls, totalCalls := 0, 0
+	for i := range profiles {
+		for _, s := range profiles[i].Stats {
+			maxCalls = max(maxCalls, s.Calls)
+			totalCalls += s.Calls
+		}
+	}
+
+	if period > 0 {
+		fmt.Fprintf(w, "\nMCP Server Usage Statistics (last %d days, %d profiles)\n", int(period.Hours()/24), len(profiles))
+	} else {
+		fmt.Fprintf(w, "\nMCP Server Usage Statistics (all time, %d profiles)\n", len(profiles))
+	}
+	fmt.Fprintln(w, strings.Repeat("─", tableWidth))
+	fmt.Fprintf(w, "  %-10s %-14s %-20s %6s   %-14s %s\n", "PROFILE", "NAME", "SCOPE", "CALLS", "LAST USED", "USAGE")
+
+	for i := range servers {
+		stat, ok := owners[i].Stats[servers[i].Name]
+		if !ok {
+			stat = types.ServerStats{Name: servers[i].Name}
+		}
+		line := fmt.Sprintf("  %-10s %-14s %-20s %6d   %-14s %s",
+			owners[i].Profile, servers[i].Name, shortenPath(servers[i].ScopeString(), 20),
+			stat.Calls, stat.LastUsedString(), RenderUsageBar(stat.Calls, maxCalls, barWidth))
+		line += statusLabel(&servers[i])
+
+		verdict, ok := owners[i].Verdicts[servers[i].Key()]
+		if !ok {
+			verdict = types.UnusedVerdict{Flagged: stat.IsUnused(period)}
+		}
+		switch {
+		case verdict.Protected:
+			line += "  " + successColor.Sprint("🔒 protected")
+		case verdict.Flagged:
+			line += "  " + warningColor.Sprint("⚠️ unused")
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "\nTotal tool calls: %d\n\n", totalCalls)
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/nnnkkk7/mcp-tidy/selection"
 	"github.com/nnnkkk7/mcp-tidy/types"
 )
 
@@ -48,11 +49,17 @@ func SelectServersPrompt(servers []types.MCPServer, stats map[string]types.Serve
 
 // SelectServersPromptWithReader is testable version with custom reader/writer.
 func SelectServersPromptWithReader(r io.Reader, w io.Writer, servers []types.MCPServer, stats map[string]types.ServerStats) []int {
+	return selectServersPrompt(r, w, servers, stats, nil)
+}
+
+// selectServersPrompt is SelectServersPromptWithReader with policy verdicts
+// (keyed by MCPServer.Key()) deciding which servers are marked unused.
+func selectServersPrompt(r io.Reader, w io.Writer, servers []types.MCPServer, stats map[string]types.ServerStats, verdicts map[string]types.UnusedVerdict) []int {
 	if len(servers) == 0 {
 		return nil
 	}
 
-	fmt.Fprintln(w, "\n? Select servers to remove (enter numbers separated by spaces, or 'all'):")
+	fmt.Fprintln(w, "\n? Select servers to remove:")
 
 	for i := range servers {
 		stat, ok := stats[servers[i].Name]
@@ -64,50 +71,50 @@ func SelectServersPromptWithReader(r io.Reader, w io.Writer, servers []types.MCP
 				usageInfo = fmt.Sprintf("(%d calls, %s)", stat.Calls, stat.LastUsedString())
 			}
 		}
-
-		// Show scope to distinguish servers with same name
-		scope := dimColor.Sprint("[global]")
-		if servers[i].Scope == types.ScopeProject {
-			projectPath := servers[i].ProjectPath
-			if len(projectPath) > 30 {
-				projectPath = "..." + projectPath[len(projectPath)-27:]
-			}
-			scope = dimColor.Sprintf("[%s]", projectPath)
+		if verdict, ok := verdicts[servers[i].Key()]; ok && verdict.Flagged && stat.Calls > 0 {
+			usageInfo += warningColor.Sprintf(" ⚠️ unused (%s)", verdict.Reason)
 		}
 
-		fmt.Fprintf(w, "  [%d] %s %s %s\n", i+1, servers[i].Name, scope, usageInfo)
+		fmt.Fprintf(w, "  [%d] %s %s %s\n", i+1, servers[i].Name, scopeLabel(&servers[i]), usageInfo)
 	}
-
-	fmt.Fprint(w, "\nEnter selection: ")
+	fmt.Fprintf(w, "\n%s\n", dimColor.Sprintf("Enter %s", selection.Help))
 
 	reader := bufio.NewReader(r)
-	input, err := reader.ReadString('\n')
-	if err != nil {
-		return nil
-	}
-
-	input = strings.TrimSpace(strings.ToLower(input))
-	if input == "" {
-		return nil
-	}
+	for {
+		fmt.Fprint(w, "\nEnter selection: ")
 
-	if input == "all" {
-		result := make([]int, len(servers))
-		for i := range servers {
-			result[i] = i
+		input, err := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" {
+			return nil
 		}
-		return result
-	}
 
-	var selected []int
-	for _, part := range strings.Fields(input) {
-		var idx int
-		if _, err := fmt.Sscanf(part, "%d", &idx); err == nil {
-			if idx >= 1 && idx <= len(servers) {
-				selected = append(selected, idx-1)
+		selected, parseErr := selection.Parse(input, servers, stats, verdicts)
+		if parseErr != nil {
+			warningColor.Fprintf(w, "✗ %v\n", parseErr)
+			if err != nil {
+				return nil
 			}
+			continue
 		}
+
+		fmt.Fprintf(w, "\nSelected %d server(s):\n", len(selected))
+		for _, idx := range selected {
+			fmt.Fprintf(w, "  - %s %s\n", servers[idx].Name, scopeLabel(&servers[idx]))
+		}
+		return selected
 	}
+}
 
-	return selected
+// scopeLabel returns the scope of a server as a dimmed label, so servers with
+// the same name can be told apart.
+func scopeLabel(server *types.MCPServer) string {
+	if server.Scope != types.ScopeProject {
+		return dimColor.Sprint("[global]")
+	}
+	projectPath := server.ProjectPath
+	if len(projectPath) > 30 {
+		projectPath = "..." + projectPath[len(projectPath)-27:]
+	}
+	return dimColor.Sprintf("[%s]", projectPath)
 }
@@ -130,16 +130,41 @@ func TestSelectServersPromptWithReader(t *testing.T) {
 			wantLen: 0,
 		},
 		{
-			name: "invalid number ignored",
+			name: "invalid number re-prompts",
 			servers: []types.MCPServer{
 				{Name: "context7", Scope: types.ScopeGlobal},
 				{Name: "serena", Scope: types.ScopeProject, ProjectPath: "/path"},
 				{Name: "puppeteer", Scope: types.ScopeGlobal},
 			},
 			stats:       map[string]types.ServerStats{},
-			input:       "1 99 2\n",
+			input:       "1 99 2\n1 2\n",
 			wantIndices: []int{0, 1},
 			wantLen:     2,
+			wantOutput:  []string{"out of range", "Selected 2 server(s)"},
+			minCount:    map[string]int{"Enter selection": 2},
+		},
+		{
+			name: "invalid input at end of input returns nil",
+			servers: []types.MCPServer{
+				{Name: "context7", Scope: types.ScopeGlobal},
+			},
+			stats:      map[string]types.ServerStats{},
+			input:      "nope\n",
+			wantLen:    0,
+			wantOutput: []string{`no server matches "nope"`},
+		},
+		{
+			name: "shows resolved servers",
+			servers: []types.MCPServer{
+				{Name: "context7", Scope: types.ScopeGlobal},
+				{Name: "puppeteer", Scope: types.ScopeGlobal},
+				{Name: "puppeteer-extra", Scope: types.ScopeProject, ProjectPath: "/path"},
+			},
+			stats:       map[string]types.ServerStats{},
+			input:       "puppeteer*\n",
+			wantIndices: []int{1, 2},
+			wantLen:     2,
+			wantOutput:  []string{"Selected 2 server(s)", "- puppeteer [global]", "- puppeteer-extra [/path]"},
 		},
 		{
 			name:    "empty servers returns nil",
@@ -0,0 +1,573 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/nnnkkk7/mcp-tidy/selection"
+	"github.com/nnnkkk7/mcp-tidy/types"
+	"golang.org/x/term"
+)
+
+const (
+	defaultTermWidth  = 80
+	defaultTermHeight = 24
+	detailPaneHeight  = 9
+	chromeHeight      = 5 // title, blank line, separator, blank line, help line
+)
+
+var cursorColor = color.New(color.ReverseVideo)
+
+// SelectServers lets the user pick servers to remove.
+// It uses the full-screen selector when stdin and stdout are terminals, and
+// falls back to the line-based SelectServersPrompt otherwise.
+// verdicts (keyed by MCPServer.Key()) decide which servers are marked unused.
+// The returned bool reports whether the selection was already confirmed by the user.
+func SelectServers(servers []types.MCPServer, stats map[string]types.ServerStats, verdicts map[string]types.UnusedVerdict) ([]int, bool) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return selectServersPrompt(os.Stdin, os.Stdout, servers, stats, verdicts), false
+	}
+
+	selected, err := SelectServersTUI(os.Stdin, os.Stdout, servers, stats, verdicts)
+	if err != nil {
+		return selectServersPrompt(os.Stdin, os.Stdout, servers, stats, verdicts), false
+	}
+	return selected, len(selected) > 0
+}
+
+// SelectServersTUI runs the full-screen selector on the given terminal.
+// Returns the indices of the confirmed servers, or nil if the user quit.
+func SelectServersTUI(in *os.File, out io.Writer, servers []types.MCPServer, stats map[string]types.ServerStats, verdicts map[string]types.UnusedVerdict) ([]int, error) {
+	fd := int(in.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable raw mode: %w", err)
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	width, height, err := term.GetSize(fd)
+	if err != nil {
+		width, height = defaultTermWidth, defaultTermHeight
+	}
+
+	// Use the alternate screen so the selector leaves no trace in scrollback.
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+
+	m := newSelectorModel(servers, stats, verdicts)
+	return runSelector(in, out, m, width, height), nil
+}
+
+// runSelector drives the selector model with keys read from r until the user
+// confirms or quits, redrawing the screen on w after every key.
+func runSelector(r io.Reader, w io.Writer, m *selectorModel, width, height int) []int {
+	reader := bufio.NewReader(r)
+	for {
+		fmt.Fprint(w, "\x1b[H\x1b[2J")
+		fmt.Fprint(w, strings.ReplaceAll(m.view(width, height), "\n", "\r\n"))
+
+		k, err := readKey(reader)
+		if err != nil {
+			return nil
+		}
+		m.handleKey(k)
+
+		if m.canceled {
+			return nil
+		}
+		if m.done {
+			return m.selectedIndices()
+		}
+	}
+}
+
+// keyKind identifies a decoded key press.
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyUp
+	keyDown
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyCtrlC
+	keyUnknown
+)
+
+// key is a single decoded key press.
+type key struct {
+	kind keyKind
+	r    rune
+}
+
+// readKey decodes one key press from raw terminal input.
+func readKey(r *bufio.Reader) (key, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return key{}, err
+	}
+
+	switch b {
+	case 0x03:
+		return key{kind: keyCtrlC}, nil
+	case '\r', '\n':
+		return key{kind: keyEnter}, nil
+	case 0x7f, 0x08:
+		return key{kind: keyBackspace}, nil
+	case 0x1b:
+		return readEscapeSequence(r)
+	}
+
+	if b < utf8.RuneSelf {
+		if b < 0x20 {
+			return key{kind: keyUnknown}, nil
+		}
+		return key{kind: keyRune, r: rune(b)}, nil
+	}
+
+	if err := r.UnreadByte(); err != nil {
+		return key{}, err
+	}
+	ru, _, err := r.ReadRune()
+	if err != nil {
+		return key{}, err
+	}
+	return key{kind: keyRune, r: ru}, nil
+}
+
+// readEscapeSequence decodes the rest of an escape sequence.
+// A lone ESC (nothing buffered after it) is reported as keyEscape.
+func readEscapeSequence(r *bufio.Reader) (key, error) {
+	if r.Buffered() == 0 {
+		return key{kind: keyEscape}, nil
+	}
+
+	b, err := r.ReadByte()
+	if err != nil {
+		return key{}, err
+	}
+	if b != '[' && b != 'O' {
+		return key{kind: keyEscape}, nil
+	}
+
+	b, err = r.ReadByte()
+	if err != nil {
+		return key{}, err
+	}
+	switch b {
+	case 'A':
+		return key{kind: keyUp}, nil
+	case 'B':
+		return key{kind: keyDown}, nil
+	default:
+		return key{kind: keyUnknown}, nil
+	}
+}
+
+// selectorSort is the sort order of the selector list.
+type selectorSort int
+
+const (
+	sortByCalls selectorSort = iota
+	sortByLastUsed
+	sortByName
+)
+
+// String returns the display name of the sort order.
+func (s selectorSort) String() string {
+	switch s {
+	case sortByLastUsed:
+		return "last used"
+	case sortByName:
+		return "name"
+	default:
+		return "calls"
+	}
+}
+
+// selectorModel holds the state of the full-screen server selector.
+type selectorModel struct {
+	servers   []types.MCPServer
+	stats     map[string]types.ServerStats
+	verdicts  map[string]types.UnusedVerdict
+	visible   []int // indices into servers, filtered and sorted
+	selected  map[int]bool
+	cursor    int // position in visible
+	offset    int // first visible row of the list
+	filter    string
+	filtering bool
+	sortMode  selectorSort
+	confirm   bool
+	done      bool
+	canceled  bool
+}
+
+func newSelectorModel(servers []types.MCPServer, stats map[string]types.ServerStats, verdicts map[string]types.UnusedVerdict) *selectorModel {
+	m := &selectorModel{
+		servers:  servers,
+		stats:    stats,
+		verdicts: verdicts,
+		selected: make(map[int]bool),
+	}
+	m.refresh()
+	return m
+}
+
+// handleKey updates the model for a single key press.
+func (m *selectorModel) handleKey(k key) {
+	if k.kind == keyCtrlC {
+		m.canceled = true
+		return
+	}
+
+	switch {
+	case m.confirm:
+		m.handleConfirmKey(k)
+	case m.filtering:
+		m.handleFilterKey(k)
+	default:
+		m.handleListKey(k)
+	}
+}
+
+func (m *selectorModel) handleConfirmKey(k key) {
+	switch {
+	case k.kind == keyEnter, k.kind == keyRune && (k.r == 'y' || k.r == 'Y'):
+		m.done = true
+	case k.kind == keyEscape, k.kind == keyRune && (k.r == 'n' || k.r == 'N'):
+		m.confirm = false
+	}
+}
+
+func (m *selectorModel) handleFilterKey(k key) {
+	switch k.kind {
+	case keyEnter:
+		m.filtering = false
+	case keyEscape:
+		m.filtering = false
+		m.filter = ""
+		m.refresh()
+	case keyBackspace:
+		if m.filter != "" {
+			_, size := utf8.DecodeLastRuneInString(m.filter)
+			m.filter = m.filter[:len(m.filter)-size]
+			m.refresh()
+		}
+	case keyRune:
+		m.filter += string(k.r)
+		m.refresh()
+	}
+}
+
+func (m *selectorModel) handleListKey(k key) {
+	switch k.kind {
+	case keyUp:
+		m.moveCursor(-1)
+	case keyDown:
+		m.moveCursor(1)
+	case keyEnter:
+		if len(m.selected) > 0 {
+			m.confirm = true
+		}
+	case keyEscape:
+		m.canceled = true
+	case keyRune:
+		m.handleListRune(k.r)
+	}
+}
+
+func (m *selectorModel) handleListRune(r rune) {
+	switch r {
+	case 'k':
+		m.moveCursor(-1)
+	case 'j':
+		m.moveCursor(1)
+	case ' ':
+		if len(m.visible) > 0 {
+			idx := m.visible[m.cursor]
+			if m.selected[idx] {
+				delete(m.selected, idx)
+			} else {
+				m.selected[idx] = true
+			}
+		}
+	case 'a':
+		m.toggleAllVisible()
+	case '/':
+		m.filtering = true
+	case 's':
+		m.sortMode = (m.sortMode + 1) % 3
+		m.refresh()
+	case 'q':
+		m.canceled = true
+	}
+}
+
+// toggleAllVisible selects every visible server, or clears them if all are selected.
+func (m *selectorModel) toggleAllVisible() {
+	allSelected := true
+	for _, idx := range m.visible {
+		if !m.selected[idx] {
+			allSelected = false
+			break
+		}
+	}
+	for _, idx := range m.visible {
+		if allSelected {
+			delete(m.selected, idx)
+		} else {
+			m.selected[idx] = true
+		}
+	}
+}
+
+func (m *selectorModel) moveCursor(delta int) {
+	m.cursor += delta
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	if m.cursor >= len(m.visible) {
+		m.cursor = len(m.visible) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// refresh recomputes the visible rows after a filter or sort change.
+func (m *selectorModel) refresh() {
+	current := -1
+	if m.cursor < len(m.visible) {
+		current = m.visible[m.cursor]
+	}
+
+	filter := strings.ToLower(m.filter)
+	m.visible = m.visible[:0]
+	for i := range m.servers {
+		if filter == "" ||
+			strings.Contains(strings.ToLower(m.servers[i].Name), filter) ||
+			strings.Contains(strings.ToLower(m.servers[i].ScopeString()), filter) {
+			m.visible = append(m.visible, i)
+		}
+	}
+
+	sort.SliceStable(m.visible, func(a, b int) bool {
+		sa, sb := m.stats[m.servers[m.visible[a]].Name], m.stats[m.servers[m.visible[b]].Name]
+		switch m.sortMode {
+		case sortByLastUsed:
+			return sa.LastUsed.After(sb.LastUsed)
+		case sortByName:
+			return m.servers[m.visible[a]].Name < m.servers[m.visible[b]].Name
+		default:
+			return sa.Calls > sb.Calls
+		}
+	})
+
+	// Keep the cursor on the same server when it is still visible.
+	m.cursor = 0
+	for pos, idx := range m.visible {
+		if idx == current {
+			m.cursor = pos
+			break
+		}
+	}
+}
+
+// selectedIndices returns the selected server indices in their original order.
+func (m *selectorModel) selectedIndices() []int {
+	result := make([]int, 0, len(m.selected))
+	for idx := range m.selected {
+		result = append(result, idx)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// view renders the current screen.
+func (m *selectorModel) view(width, height int) string {
+	if m.confirm {
+		return m.confirmView(width)
+	}
+
+	var b strings.Builder
+
+	title := fmt.Sprintf("Select servers to remove (%d/%d selected, sort: %s)", len(m.selected), len(m.servers), m.sortMode)
+	fmt.Fprintln(&b, truncate(title, width))
+	switch {
+	case m.filtering:
+		fmt.Fprintf(&b, "Filter: /%s█\n", m.filter)
+	case m.filter != "":
+		fmt.Fprintf(&b, "Filter: /%s\n", m.filter)
+	default:
+		fmt.Fprintln(&b)
+	}
+
+	listHeight := height - detailPaneHeight - chromeHeight
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	m.scrollTo(listHeight)
+
+	for row := 0; row < listHeight; row++ {
+		pos := m.offset + row
+		if pos >= len(m.visible) {
+			fmt.Fprintln(&b)
+			continue
+		}
+		line := truncate(m.rowText(m.visible[pos]), width)
+		if pos == m.cursor {
+			line = cursorColor.Sprint(line)
+		}
+		fmt.Fprintln(&b, line)
+	}
+
+	fmt.Fprintln(&b, strings.Repeat("─", min(width, tableWidth)))
+	m.writeDetail(&b, width)
+	fmt.Fprintln(&b)
+	fmt.Fprint(&b, dimColor.Sprint(truncate("↑/↓ move · space toggle · a all · / filter · s sort · enter confirm · q quit", width)))
+
+	return b.String()
+}
+
+// scrollTo adjusts the list offset so the cursor stays on screen.
+func (m *selectorModel) scrollTo(listHeight int) {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+listHeight {
+		m.offset = m.cursor - listHeight + 1
+	}
+}
+
+// rowText returns the list row for a server.
+func (m *selectorModel) rowText(idx int) string {
+	server := &m.servers[idx]
+	check := "[ ]"
+	if m.selected[idx] {
+		check = "[x]"
+	}
+
+	usage := "never used"
+	if stat, ok := m.stats[server.Name]; ok && stat.Calls > 0 {
+		usage = fmt.Sprintf("%d calls, %s", stat.Calls, stat.LastUsedString())
+	}
+	if selection.IsUnused(server, m.stats, m.verdicts) {
+		usage += "  ⚠ unused"
+	}
+
+	return fmt.Sprintf(" %s %-20s %-30s %s", check, server.Name, shortenPath(server.ScopeString(), 30), usage)
+}
+
+// writeDetail writes the detail pane for the server under the cursor.
+func (m *selectorModel) writeDetail(b *strings.Builder, width int) {
+	if len(m.visible) == 0 {
+		fmt.Fprintln(b, "No servers match the filter.")
+		for i := 1; i < detailPaneHeight; i++ {
+			fmt.Fprintln(b)
+		}
+		return
+	}
+
+	server := &m.servers[m.visible[m.cursor]]
+	stat := m.stats[server.Name]
+
+	lines := []string{
+		fmt.Sprintf("Name:    %s", server.Name),
+		fmt.Sprintf("Scope:   %s", server.ScopeString()),
+		fmt.Sprintf("Type:    %s", server.TypeName()),
+		fmt.Sprintf("Command: %s", server.CommandString()),
+		fmt.Sprintf("Env:     %s", envKeys(server.Env)),
+		fmt.Sprintf("Usage:   %d calls, last used %s", stat.Calls, stat.LastUsedString()),
+	}
+	if verdict, ok := m.verdicts[server.Key()]; ok && verdict.Reason != "" {
+		lines = append(lines, fmt.Sprintf("Policy:  %s", verdict.Reason))
+	}
+	lines = append(lines, toolLines(stat.Tools, detailPaneHeight-len(lines))...)
+
+	for i := 0; i < detailPaneHeight; i++ {
+		if i < len(lines) {
+			fmt.Fprintln(b, truncate(lines[i], width))
+		} else {
+			fmt.Fprintln(b)
+		}
+	}
+}
+
+// confirmView renders the confirmation screen.
+func (m *selectorModel) confirmView(width int) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Remove %d server(s)?\n\n", len(m.selected))
+	for _, idx := range m.selectedIndices() {
+		fmt.Fprintln(&b, truncate(fmt.Sprintf("  - %s (%s)", m.servers[idx].Name, m.servers[idx].ScopeString()), width))
+	}
+	fmt.Fprintln(&b)
+	fmt.Fprint(&b, dimColor.Sprint("y/enter remove · n/esc back · ctrl+c quit"))
+
+	return b.String()
+}
+
+// envKeys returns the sorted env variable names; values are never shown.
+func envKeys(env map[string]string) string {
+	if len(env) == 0 {
+		return "(none)"
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+// toolLines returns per-tool call counts, most used first, limited to maxLines.
+func toolLines(tools map[string]int, maxLines int) []string {
+	if len(tools) == 0 || maxLines <= 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if tools[names[i]] != tools[names[j]] {
+			return tools[names[i]] > tools[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	lines := []string{"Tools:"}
+	for _, name := range names {
+		if len(lines) == maxLines {
+			lines[len(lines)-1] = fmt.Sprintf("  ... and %d more", len(names)-maxLines+2)
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %-30s %d", name, tools[name]))
+	}
+	return lines
+}
+
+// shortenPath shortens a path to at most n characters by trimming its start.
+func shortenPath(path string, n int) string {
+	if len(path) <= n {
+		return path
+	}
+	return "..." + path[len(path)-n+3:]
+}
+
+// truncate cuts s to at most n runes.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n])
+}
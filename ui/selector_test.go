@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func selectorFixture() ([]types.MCPServer, map[string]types.ServerStats) {
+	now := time.Now()
+	servers := []types.MCPServer{
+		{Name: "puppeteer", Scope: types.ScopeGlobal, Command: "npx", Args: []string{"-y", "@anthropic/server-puppeteer"}},
+		{Name: "context7", Scope: types.ScopeGlobal, Type: types.ServerTypeHTTP, URL: "https://mcp.context7.com/mcp"},
+		{
+			Name: "serena", Scope: types.ScopeProject, ProjectPath: "/work/project", Command: "uvx",
+			Env: map[string]string{"SERENA_TOKEN": "secret-value", "LOG_LEVEL": "debug"},
+		},
+	}
+	stats := map[string]types.ServerStats{
+		"context7": {Name: "context7", Calls: 100, LastUsed: now.Add(-48 * time.Hour), Tools: map[string]int{"query-docs": 60, "resolve-library-id": 40}},
+		"serena":   {Name: "serena", Calls: 20, LastUsed: now, Tools: map[string]int{"find_symbol": 20}},
+	}
+	return servers, stats
+}
+
+func TestRunSelector(t *testing.T) {
+	servers, stats := selectorFixture()
+
+	tests := []struct {
+		name  string
+		input string
+		want  []int
+	}{
+		{
+			name:  "toggle first row (most calls) and confirm",
+			input: " \ry",
+			want:  []int{1},
+		},
+		{
+			name:  "move down with arrow key and confirm with enter",
+			input: "\x1b[B \r\r",
+			want:  []int{2},
+		},
+		{
+			name:  "filter then toggle",
+			input: "/pup\r \ry",
+			want:  []int{0},
+		},
+		{
+			name:  "select all visible",
+			input: "a\ry",
+			want:  []int{0, 1, 2},
+		},
+		{
+			name:  "back out of confirm screen and deselect",
+			input: " \rn \r",
+			want:  nil,
+		},
+		{
+			name:  "quit returns nothing",
+			input: " q",
+			want:  nil,
+		},
+		{
+			name:  "ctrl-c returns nothing",
+			input: " \r\x03",
+			want:  nil,
+		},
+		{
+			name:  "enter without selection does nothing",
+			input: "\rq",
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newSelectorModel(servers, stats, nil)
+			got := runSelector(strings.NewReader(tt.input), &bytes.Buffer{}, m, 100, 30)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("runSelector() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSelectorModel_Sort(t *testing.T) {
+	servers, stats := selectorFixture()
+	m := newSelectorModel(servers, stats, nil)
+
+	tests := []struct {
+		sortMode selectorSort
+		want     []int
+	}{
+		{sortByCalls, []int{1, 2, 0}},
+		{sortByLastUsed, []int{2, 1, 0}},
+		{sortByName, []int{1, 0, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortMode.String(), func(t *testing.T) {
+			m.sortMode = tt.sortMode
+			m.refresh()
+			if diff := cmp.Diff(tt.want, m.visible); diff != "" {
+				t.Errorf("visible order mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSelectorModel_View(t *testing.T) {
+	servers, stats := selectorFixture()
+	m := newSelectorModel(servers, stats, nil)
+
+	// Move the cursor to serena (second by calls).
+	m.handleKey(key{kind: keyDown})
+	view := m.view(120, 30)
+
+	for _, want := range []string{"Select servers to remove", "uvx", "LOG_LEVEL, SERENA_TOKEN", "find_symbol", "/work/project"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q\nGot:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "secret-value") {
+		t.Errorf("view should not contain env values\nGot:\n%s", view)
+	}
+
+	m.handleKey(key{kind: keyRune, r: ' '})
+	m.handleKey(key{kind: keyEnter})
+	view = m.view(120, 30)
+	if !strings.Contains(view, "Remove 1 server(s)?") || !strings.Contains(view, "serena") {
+		t.Errorf("confirm view mismatch\nGot:\n%s", view)
+	}
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/nnnkkk7/mcp-tidy/config"
+)
+
+// RenderSyncPlan prints what syncing does with each server in one target client.
+func RenderSyncPlan(w io.Writer, title, configPath string, plan []config.SyncedServer) {
+	fmt.Fprintf(w, "\n%s (%s):\n\n", title, configPath)
+	for i := range plan {
+		p := &plan[i]
+		var marker string
+		switch p.Action {
+		case config.SyncAdd:
+			marker = successColor.Sprint("+ add        ")
+		case config.SyncUpdate:
+			marker = warningColor.Sprint("~ update     ")
+		case config.SyncUnsupported:
+			marker = errorColor.Sprint("✗ unsupported")
+		default:
+			marker = dimColor.Sprint("= unchanged  ")
+		}
+
+		fmt.Fprintf(w, "  %s  %s", marker, p.Server.Name)
+		switch p.Action {
+		case config.SyncAdd:
+			fmt.Fprintf(w, "  %s", dimColor.Sprint(p.Server.CommandString()))
+		case config.SyncUnsupported:
+			fmt.Fprintf(w, "  %s", dimColor.Sprint(p.Reason))
+		}
+		fmt.Fprintln(w)
+
+		if p.Action == config.SyncUpdate {
+			for _, change := range p.Changes {
+				fmt.Fprintf(w, "          %s\n", change)
+			}
+		}
+		if p.Action.Writes() {
+			for _, note := range p.Notes {
+				fmt.Fprintf(w, "          %s\n", warningColor.Sprintf("note: %s", note))
+			}
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/nnnkkk7/mcp-tidy/config"
 	"github.com/nnnkkk7/mcp-tidy/types"
 )
 
@@ -42,7 +43,14 @@ func RenderServerTable(w io.Writer, servers []types.MCPServer) {
 
 	fmt.Fprintf(w, "\nMCP Servers (%d configured)\n", len(servers))
 	fmt.Fprintln(w, strings.Repeat("─", tableWidth))
-	fmt.Fprintf(w, "  %-14s %-36s %s\n", "NAME", "SCOPE", "COMMAND")
+
+	// The client column is only shown when servers of other clients are listed
+	withClient := hasOtherClients(servers)
+	if withClient {
+		fmt.Fprintf(w, "  %-14s %-15s %-26s %s\n", "NAME", "CLIENT", "SCOPE", "COMMAND")
+	} else {
+		fmt.Fprintf(w, "  %-14s %-36s %s\n", "NAME", "SCOPE", "COMMAND")
+	}
 
 	for i := range sorted {
 		scope := sorted[i].ScopeString()
@@ -55,61 +63,234 @@ func RenderServerTable(w io.Writer, servers []types.MCPServer) {
 			command = command[:37] + "..."
 		}
 
-		fmt.Fprintf(w, "  %-14s %-36s %s\n", sorted[i].Name, scope, command)
+		if withClient {
+			fmt.Fprintf(w, "  %-14s %-15s %-26s %s%s\n", sorted[i].Name, clientName(&sorted[i]), shortenPath(scope, 26), command, statusLabel(&sorted[i]))
+		} else {
+			fmt.Fprintf(w, "  %-14s %-36s %s%s\n", sorted[i].Name, scope, command, statusLabel(&sorted[i]))
+		}
 	}
 	fmt.Fprintln(w)
 }
 
+// hasOtherClients reports whether any server belongs to a client other than Claude Code.
+func hasOtherClients(servers []types.MCPServer) bool {
+	for i := range servers {
+		if servers[i].Client != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// clientName returns the name of the client a server belongs to.
+func clientName(server *types.MCPServer) string {
+	if server.Client == "" {
+		return config.ClientClaudeCode
+	}
+	return server.Client
+}
+
+// clientTitle returns the title of the client a server belongs to, for headings.
+func clientTitle(server *types.MCPServer) string {
+	client, err := config.ParseClient(server.Client)
+	if err != nil {
+		return server.Client
+	}
+	return client.Title()
+}
+
+// statusLabel marks a server Claude Code doesn't start, with a leading
+// separator; it is empty for active servers.
+func statusLabel(server *types.MCPServer) string {
+	switch server.Status {
+	case types.StatusDisabled:
+		return "  " + warningColor.Sprint("⊘ disabled")
+	case types.StatusPending:
+		return "  " + dimColor.Sprint("? pending approval")
+	default:
+		return ""
+	}
+}
+
 // RenderStatsTable renders a table of server usage statistics.
 // If servers is provided, stats are grouped by scope (global/project).
 func RenderStatsTable(w io.Writer, stats []types.ServerStats, period time.Duration, servers ...[]types.MCPServer) {
+	var configured []types.MCPServer
+	if len(servers) > 0 {
+		configured = servers[0]
+	}
+	RenderStatsTableWithVerdicts(w, stats, period, configured, nil)
+}
+
+// RenderStatsTableWithVerdicts renders a table of server usage statistics grouped
+// by scope, marking servers according to verdicts (keyed by MCPServer.Key()) and
+// showing the reason for each. Servers without a verdict are flagged when they
+// are unused within period. If servers is empty, a simple list is rendered.
+func RenderStatsTableWithVerdicts(w io.Writer, stats []types.ServerStats, period time.Duration, servers []types.MCPServer, verdicts map[string]types.UnusedVerdict) {
 	if len(stats) == 0 {
 		fmt.Fprintln(w, "No usage data found.")
 		return
 	}
 
-	// Build stats map for quick lookup
-	statsMap := make(map[string]types.ServerStats)
+	v := &statsView{
+		statsMap: make(map[string]types.ServerStats),
+		period:   period,
+		verdicts: verdicts,
+	}
 	for _, s := range stats {
-		statsMap[s.Name] = s
+		v.statsMap[s.Name] = s
 	}
 
 	// Find max calls for bar scaling
-	maxCalls := 0
 	totalCalls := 0
 	for _, s := range stats {
-		if s.Calls > maxCalls {
-			maxCalls = s.Calls
+		if s.Calls > v.maxCalls {
+			v.maxCalls = s.Calls
 		}
 		totalCalls += s.Calls
 	}
 
-	periodDays := int(period.Hours() / 24)
-	fmt.Fprintf(w, "\nMCP Server Usage Statistics (last %d days)\n", periodDays)
+	if period > 0 {
+		fmt.Fprintf(w, "\nMCP Server Usage Statistics (last %d days)\n", int(period.Hours()/24))
+	} else {
+		fmt.Fprintln(w, "\nMCP Server Usage Statistics (all time)")
+	}
 	fmt.Fprintln(w, strings.Repeat("─", tableWidth))
 
 	// If servers provided, render grouped by scope
-	if len(servers) > 0 && len(servers[0]) > 0 {
-		renderGroupedStats(w, servers[0], statsMap, maxCalls, period)
-	} else {
-		// Fallback to simple list (backwards compatibility)
-		renderSimpleStats(w, stats, maxCalls, period)
+	if len(servers) > 0 {
+		v.renderGroupedStats(w, servers)
+		measurable := v.measurable(servers)
+		renderNotConfiguredStats(w, stats, measurable, v.maxCalls)
+		fmt.Fprintf(w, "\nTotal tool calls: %d\n", totalCalls)
+		v.renderCategorySummary(w, stats, measurable, len(servers)-len(measurable))
+		fmt.Fprintln(w)
+		return
 	}
 
+	// Fallback to simple list (backwards compatibility)
+	renderSimpleStats(w, stats, v.maxCalls, period)
 	fmt.Fprintf(w, "\nTotal tool calls: %d\n\n", totalCalls)
 }
 
-// renderGroupedStats renders stats grouped by scope (global/project).
-func renderGroupedStats(w io.Writer, servers []types.MCPServer, statsMap map[string]types.ServerStats, maxCalls int, period time.Duration) {
+// statsView holds what is needed to render stats rows for configured servers.
+type statsView struct {
+	statsMap map[string]types.ServerStats
+	maxCalls int
+	period   time.Duration
+	verdicts map[string]types.UnusedVerdict
+}
+
+// verdict returns the verdict for a server, falling back to the period check.
+func (v *statsView) verdict(server *types.MCPServer) types.UnusedVerdict {
+	if verdict, ok := v.verdicts[server.Key()]; ok {
+		return verdict
+	}
+	return types.UnusedVerdict{Flagged: v.statsMap[server.Name].IsUnused(v.period)}
+}
+
+// measurable returns the servers whose usage is available. The calls of the
+// others can't be told apart from calls to a server of the same name.
+func (v *statsView) measurable(servers []types.MCPServer) []types.MCPServer {
+	var result []types.MCPServer
+	for i := range servers {
+		if !v.verdict(&servers[i]).Unavailable {
+			result = append(result, servers[i])
+		}
+	}
+	return result
+}
+
+// renderNotConfiguredStats renders servers that appear in transcripts but not in any config.
+func renderNotConfiguredStats(w io.Writer, stats []types.ServerStats, servers []types.MCPServer, maxCalls int) {
+	configured := configuredNames(servers)
+
+	var notConfigured []types.ServerStats
+	for _, s := range stats {
+		if !configured[s.Name] {
+			notConfigured = append(notConfigured, s)
+		}
+	}
+	if len(notConfigured) == 0 {
+		return
+	}
+
+	sort.Slice(notConfigured, func(i, j int) bool {
+		return notConfigured[i].Calls > notConfigured[j].Calls
+	})
+
+	fmt.Fprintf(w, "\n%s\n", dimColor.Sprint("── Not configured (removed, or from plugins / .mcp.json) ──"))
+	fmt.Fprintf(w, "  %-14s %6s   %-14s %s\n", "NAME", "CALLS", "LAST USED", "USAGE")
+	for _, s := range notConfigured {
+		bar := RenderUsageBar(s.Calls, maxCalls, barWidth)
+		fmt.Fprintf(w, "  %-14s %6d   %-14s %s\n", s.Name, s.Calls, s.LastUsedString(), bar)
+	}
+}
+
+// renderCategorySummary renders how many servers fall into each usage category.
+// A name configured in several scopes counts once, as used unless every one is flagged.
+// Servers whose usage is unavailable are only counted, if there are any.
+func (v *statsView) renderCategorySummary(w io.Writer, stats []types.ServerStats, servers []types.MCPServer, unavailable int) {
+	configured := configuredNames(servers)
+	used := make(map[string]bool)
+	for i := range servers {
+		if !v.verdict(&servers[i]).Flagged {
+			used[servers[i].Name] = true
+		}
+	}
+
+	counts := make(map[types.UsageCategory]int)
+	for name := range configured {
+		if used[name] {
+			counts[types.CategoryUsed]++
+		} else {
+			counts[types.CategoryUnused]++
+		}
+	}
+	for _, s := range stats {
+		if !configured[s.Name] {
+			counts[types.CategoryNotConfigured]++
+		}
+	}
+
+	fmt.Fprintf(w, "Configured & used: %d · Configured & unused: %d · Not configured: %d",
+		counts[types.CategoryUsed], counts[types.CategoryUnused], counts[types.CategoryNotConfigured])
+	if unavailable > 0 {
+		fmt.Fprintf(w, " · Usage unavailable: %d", unavailable)
+	}
+	fmt.Fprintln(w)
+}
+
+// configuredNames returns the set of configured server names.
+func configuredNames(servers []types.MCPServer) map[string]bool {
+	names := make(map[string]bool, len(servers))
+	for i := range servers {
+		names[servers[i].Name] = true
+	}
+	return names
+}
+
+// renderGroupedStats renders stats grouped by scope (global/managed/project),
+// and the servers of other clients grouped by client.
+func (v *statsView) renderGroupedStats(w io.Writer, servers []types.MCPServer) {
 	// Separate servers by scope
-	var globalServers []types.MCPServer
+	var globalServers, managedServers []types.MCPServer
 	projectGroups := make(map[string][]types.MCPServer)
+	clientGroups := make(map[string][]types.MCPServer)
 
 	for i := range servers {
-		if servers[i].Scope == types.ScopeGlobal {
+		switch {
+		case servers[i].Client != "":
+			// Other clients get a group each, headed by the client's title
+			clientGroups[clientTitle(&servers[i])] = append(clientGroups[clientTitle(&servers[i])], servers[i])
+		case servers[i].Scope == types.ScopeGlobal:
 			globalServers = append(globalServers, servers[i])
-		} else {
-			projectGroups[servers[i].ProjectPath] = append(projectGroups[servers[i].ProjectPath], servers[i])
+		case servers[i].Scope == types.ScopeManaged:
+			managedServers = append(managedServers, servers[i])
+		default:
+			// .mcp.json servers get their own group, headed by the file's path
+			group := servers[i].ScopeString()
+			projectGroups[group] = append(projectGroups[group], servers[i])
 		}
 	}
 
@@ -117,7 +298,14 @@ func renderGroupedStats(w io.Writer, servers []types.MCPServer, statsMap map[str
 	if len(globalServers) > 0 {
 		fmt.Fprintf(w, "\n%s\n", dimColor.Sprint("── Global ──"))
 		fmt.Fprintf(w, "  %-14s %6s   %-14s %s\n", "NAME", "CALLS", "LAST USED", "USAGE")
-		renderServerStatsRows(w, globalServers, statsMap, maxCalls, period)
+		v.renderServerStatsRows(w, globalServers)
+	}
+
+	// Render servers deployed by an administrator
+	if len(managedServers) > 0 {
+		fmt.Fprintf(w, "\n%s\n", dimColor.Sprint("── Managed ──"))
+		fmt.Fprintf(w, "  %-14s %6s   %-14s %s\n", "NAME", "CALLS", "LAST USED", "USAGE")
+		v.renderServerStatsRows(w, managedServers)
 	}
 
 	// Render project servers grouped by project
@@ -137,38 +325,67 @@ func renderGroupedStats(w io.Writer, servers []types.MCPServer, statsMap map[str
 			}
 			fmt.Fprintf(w, "\n%s\n", dimColor.Sprintf("── %s ──", displayPath))
 			fmt.Fprintf(w, "  %-14s %6s   %-14s %s\n", "NAME", "CALLS", "LAST USED", "USAGE")
-			renderServerStatsRows(w, projectServers, statsMap, maxCalls, period)
+			v.renderServerStatsRows(w, projectServers)
 		}
 	}
+
+	// Render the servers of other clients
+	titles := make([]string, 0, len(clientGroups))
+	for title := range clientGroups {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	for _, title := range titles {
+		fmt.Fprintf(w, "\n%s\n", dimColor.Sprintf("── %s ──", title))
+		fmt.Fprintf(w, "  %-14s %6s   %-14s %s\n", "NAME", "CALLS", "LAST USED", "USAGE")
+		v.renderServerStatsRows(w, clientGroups[title])
+	}
 }
 
 // renderServerStatsRows renders stats rows for a list of servers.
-func renderServerStatsRows(w io.Writer, servers []types.MCPServer, statsMap map[string]types.ServerStats, maxCalls int, period time.Duration) {
+func (v *statsView) renderServerStatsRows(w io.Writer, servers []types.MCPServer) {
 	// Sort by calls (descending)
 	sorted := make([]types.MCPServer, len(servers))
 	copy(sorted, servers)
 	sort.Slice(sorted, func(i, j int) bool {
-		si := statsMap[sorted[i].Name]
-		sj := statsMap[sorted[j].Name]
+		si := v.statsMap[sorted[i].Name]
+		sj := v.statsMap[sorted[j].Name]
 		return si.Calls > sj.Calls
 	})
 
 	for i := range sorted {
-		stat, ok := statsMap[sorted[i].Name]
+		verdict := v.verdict(&sorted[i])
+		if verdict.Unavailable {
+			line := fmt.Sprintf("  %-14s %6s   %-14s %s", sorted[i].Name, "-", "-", dimColor.Sprint("unavailable"))
+			if verdict.Protected {
+				line += "  " + successColor.Sprint("🔒 protected")
+			}
+			fmt.Fprintln(w, line+"  "+dimColor.Sprint(verdict.Reason))
+			continue
+		}
+
+		stat, ok := v.statsMap[sorted[i].Name]
 		if !ok {
 			stat = types.ServerStats{Name: sorted[i].Name}
 		}
 
-		bar := RenderUsageBar(stat.Calls, maxCalls, barWidth)
+		bar := RenderUsageBar(stat.Calls, v.maxCalls, barWidth)
 		lastUsed := stat.LastUsedString()
 
 		line := fmt.Sprintf("  %-14s %6d   %-14s %s", stat.Name, stat.Calls, lastUsed, bar)
 
-		if stat.IsUnused(period) {
-			fmt.Fprintf(w, "%s  %s\n", line, warningColor.Sprint("⚠️ unused"))
-		} else {
-			fmt.Fprintln(w, line)
+		line += statusLabel(&sorted[i])
+
+		switch {
+		case verdict.Protected:
+			line += "  " + successColor.Sprint("🔒 protected")
+		case verdict.Flagged:
+			line += "  " + warningColor.Sprint("⚠️ unused")
 		}
+		if verdict.Reason != "" {
+			line += "  " + dimColor.Sprint(verdict.Reason)
+		}
+		fmt.Fprintln(w, line)
 	}
 }
 
@@ -224,7 +441,10 @@ func RenderRemovalSummary(w io.Writer, removed []types.MCPServer) {
 	fmt.Fprintln(w)
 	for i := range removed {
 		location := "~/.claude.json"
-		if removed[i].Scope == types.ScopeProject {
+		switch {
+		case removed[i].Client != "":
+			location = clientTitle(&removed[i]) + " config"
+		case removed[i].Scope == types.ScopeProject:
 			location = fmt.Sprintf("~/.claude.json (project: %s)", removed[i].ProjectPath)
 		}
 		successColor.Fprintf(w, "✓ Removed: %s (from %s)\n", removed[i].Name, location)
@@ -242,10 +462,32 @@ func RenderDryRunSummary(w io.Writer, servers []types.MCPServer) {
 	fmt.Fprintln(w, "\n[DRY RUN] The following servers would be removed:")
 	for i := range servers {
 		location := "global"
-		if servers[i].Scope == types.ScopeProject {
+		switch {
+		case servers[i].Client != "":
+			location = clientTitle(&servers[i])
+		case servers[i].Scope == types.ScopeProject:
 			location = servers[i].ProjectPath
 		}
 		fmt.Fprintf(w, "  - %s (%s)\n", servers[i].Name, location)
 	}
 	fmt.Fprintln(w, "\nRun without --dry-run to actually remove these servers.")
 }
+
+// RenderClaudeWarning warns about running processes that may overwrite
+// changes to the config.
+func RenderClaudeWarning(w io.Writer, configPath string, processes []config.Process) {
+	if len(processes) == 0 {
+		return
+	}
+
+	labels := make([]string, 0, len(processes))
+	for i := range processes {
+		label := fmt.Sprintf("%s (pid %d)", processes[i].Name, processes[i].PID)
+		if processes[i].HoldsConfig {
+			label += ", has the config open"
+		}
+		labels = append(labels, label)
+	}
+	warningColor.Fprintf(w, "⚠ Running processes may overwrite changes to %s: %s\n", configPath, strings.Join(labels, "; "))
+	warningColor.Fprintln(w, "  Quit Claude Code first, or it may restore removed servers from memory.")
+}
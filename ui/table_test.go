@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/config"
 	"github.com/nnnkkk7/mcp-tidy/types"
 )
 
@@ -32,6 +34,14 @@ func TestRenderServerTable(t *testing.T) {
 			},
 			want: []string{"context7", "global", "[http]"},
 		},
+		{
+			name: "sse and unknown types",
+			servers: []types.MCPServer{
+				{Name: "events", Type: types.ServerTypeSSE, URL: "http://localhost:8080/sse", Scope: types.ScopeGlobal},
+				{Name: "future", Type: types.ServerTypeUnknown, TypeStr: "websocket", URL: "wss://example.com/mcp", Scope: types.ScopeGlobal},
+			},
+			want: []string{"[sse] http://localhost:8080/sse", "[websocket] wss://example.com/mcp"},
+		},
 		{
 			name: "multiple servers",
 			servers: []types.MCPServer{
@@ -52,6 +62,14 @@ func TestRenderServerTable(t *testing.T) {
 			},
 			want: []string{"context7", "serena", "global", "/Users/xxx/github/my-project"},
 		},
+		{
+			name: "servers of several clients",
+			servers: []types.MCPServer{
+				{Name: "context7", Type: types.ServerTypeHTTP, URL: "https://mcp.context7.com/mcp", Scope: types.ScopeGlobal},
+				{Name: "filesystem", Type: types.ServerTypeStdio, Command: "npx", Scope: types.ScopeGlobal, Client: "claude-desktop"},
+			},
+			want: []string{"CLIENT", "claude-code", "claude-desktop"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -191,6 +209,19 @@ func TestRenderStatsTable(t *testing.T) {
 			},
 			want: []string{"175"},
 		},
+		{
+			name: "shows used but not configured servers separately",
+			stats: []types.ServerStats{
+				{Name: "context7", Calls: 100, LastUsed: now},
+				{Name: "plugin-server", Calls: 7, LastUsed: now},
+			},
+			servers: []types.MCPServer{
+				{Name: "context7", Scope: types.ScopeGlobal},
+				{Name: "idle", Scope: types.ScopeGlobal},
+			},
+			want:          []string{"Not configured", "plugin-server", "Configured & used: 1", "Configured & unused: 1", "Not configured: 1"},
+			expectedOrder: []string{"Global", "context7", "Not configured", "plugin-server"},
+		},
 		// Order tests
 		{
 			name: "global before projects",
@@ -273,6 +304,38 @@ func TestRenderStatsTable(t *testing.T) {
 	}
 }
 
+func TestRenderStatsTableWithVerdicts(t *testing.T) {
+	now := time.Now()
+	stats := []types.ServerStats{
+		{Name: "context7", Calls: 2, LastUsed: now},
+		{Name: "github", Calls: 0},
+	}
+	servers := []types.MCPServer{
+		{Name: "context7", Scope: types.ScopeGlobal},
+		{Name: "github", Scope: types.ScopeGlobal},
+	}
+	verdicts := map[string]types.UnusedVerdict{
+		"global:context7": {Flagged: true, Reason: "2 calls in 30d, below minimum of 5"},
+		"global:github":   {Protected: true, Reason: "protected by policy (github)"},
+	}
+
+	var buf bytes.Buffer
+	RenderStatsTableWithVerdicts(&buf, stats, 30*24*time.Hour, servers, verdicts)
+	output := buf.String()
+
+	for _, want := range []string{
+		"⚠️ unused",
+		"2 calls in 30d, below minimum of 5",
+		"🔒 protected",
+		"protected by policy (github)",
+		"Configured & used: 1 · Configured & unused: 1",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q\nGot:\n%s", want, output)
+		}
+	}
+}
+
 func TestRenderUsageBar(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -411,3 +474,77 @@ func TestRenderDryRunSummary(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderProfileTables(t *testing.T) {
+	profiles := []ProfileServers{
+		{
+			Profile: "personal",
+			Servers: []types.MCPServer{{Name: "puppeteer", Command: "npx", Scope: types.ScopeGlobal}},
+			Stats:   map[string]types.ServerStats{"puppeteer": {Name: "puppeteer", Calls: 7, LastUsed: time.Now()}},
+		},
+		{
+			Profile:  "work",
+			Servers:  []types.MCPServer{{Name: "github", Command: "npx", Scope: types.ScopeGlobal}},
+			Verdicts: map[string]types.UnusedVerdict{"global:github": {Flagged: true}},
+		},
+	}
+
+	var buf bytes.Buffer
+	RenderProfileServerTable(&buf, profiles)
+	for _, want := range []string{"PROFILE", "2 configured in 2 profiles", "personal   puppeteer", "work       github"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("RenderProfileServerTable() output missing %q\nGot:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	RenderProfileStatsTable(&buf, profiles, 30*24*time.Hour)
+	output := buf.String()
+	if !strings.Contains(output, "2 profiles") || !strings.Contains(output, "Total tool calls: 7") {
+		t.Errorf("RenderProfileStatsTable() output missing header or total\nGot:\n%s", output)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "puppeteer") && strings.Contains(line, "unused") {
+			t.Errorf("used server flagged as unused: %q", line)
+		}
+		if strings.Contains(line, "github") && !strings.Contains(line, "unused") {
+			t.Errorf("flagged server not marked as unused: %q", line)
+		}
+	}
+}
+
+func TestRenderClientOverview(t *testing.T) {
+	servers := []types.MCPServer{
+		{Name: "context7", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://mcp.context7.com/mcp", Scope: types.ScopeGlobal},
+		{Name: "context7", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://mcp.context7.com/mcp", Scope: types.ScopeGlobal, Client: config.ClientCursor},
+		{Name: "context7", Type: types.ServerTypeHTTP, TypeStr: "http", URL: "https://mcp.context7.com/v2", Scope: types.ScopeGlobal, Client: config.ClientVSCode},
+		{Name: "fetch", Command: "uvx", Scope: types.ScopeGlobal, Client: config.ClientVSCode},
+		{Name: "fetch", Command: "uvx", Scope: types.ScopeProject, ProjectPath: "/work/app", Client: config.ClientVSCode},
+	}
+
+	var buf bytes.Buffer
+	RenderClientOverview(&buf, servers, config.Clients())
+	output := buf.String()
+
+	for _, want := range []string{"2 servers in 6 clients", "CLAUDE CODE", "VS CODE", "≠ connects differently"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("RenderClientOverview() output missing %q\nGot:\n%s", want, output)
+		}
+	}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "context7":
+			if diff := cmp.Diff([]string{"context7", "✓", "-", "✓", "✓", "≠", "-", "-"}, fields); diff != "" {
+				t.Errorf("context7 row mismatch (-want +got):\n%s", diff)
+			}
+		case "fetch":
+			if diff := cmp.Diff([]string{"fetch", "-", "-", "-", "✓", "2", "-", "-"}, fields); diff != "" {
+				t.Errorf("fetch row mismatch (-want +got):\n%s", diff)
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+	"github.com/nnnkkk7/mcp-tidy/config"
+)
+
+var errorColor = color.New(color.FgRed)
+
+// RenderValidation prints the issues found in a config file.
+func RenderValidation(w io.Writer, path string, issues []config.Issue) {
+	if len(issues) == 0 {
+		successColor.Fprintf(w, "✓ %s is valid\n", path)
+		return
+	}
+
+	errs := len(config.Errors(issues))
+	fmt.Fprintf(w, "%s: %d error(s), %d warning(s)\n\n", path, errs, len(issues)-errs)
+	for i := range issues {
+		if issues[i].Severity == config.SeverityError {
+			errorColor.Fprint(w, "  ✗ error    ")
+		} else {
+			warningColor.Fprint(w, "  ⚠ warning  ")
+		}
+		fmt.Fprintf(w, "%s: %s\n", issues[i].Path, issues[i].Message)
+	}
+}
+
+// RenderLoadIssues prints a short notice about errors found while loading
+// the config, pointing to the validate command for details.
+func RenderLoadIssues(w io.Writer, path string, issues []config.Issue) {
+	errs := config.Errors(issues)
+	if len(errs) == 0 {
+		return
+	}
+
+	for i := range errs {
+		warningColor.Fprintf(w, "⚠ %s: %s\n", path, errs[i].String())
+	}
+	dimColor.Fprintln(w, "  Run 'mcp-tidy validate' for details.")
+}
@@ -0,0 +1,252 @@
+// Package usagelog records MCP tool calls as Claude Code makes them, from a
+// PostToolUse or PostToolUseFailure hook, and reads them back as a usage
+// source. Unlike
+// transcripts, the log keeps the calls of sessions that were deleted or
+// cleaned up.
+package usagelog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/nnnkkk7/mcp-tidy/transcript"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+// FileName is the name of the usage log in the mcp-tidy config directory.
+const FileName = "usage.jsonl"
+
+// MaxSize is the size at which the log is rotated: its records move to the
+// log path followed by ".1", replacing the ones there, and a new log is
+// started. The log and its rotated copy are read together, so the log keeps
+// the last MaxSize to twice MaxSize of records, tens of thousands of calls.
+const MaxSize = 10 << 20
+
+// Record is one MCP tool call, stored as a line of JSON. Empty fields are
+// left out to keep the log small.
+type Record struct {
+	Time time.Time `json:"time"`
+	// ID is the tool-use ID of the call, shared with the transcript.
+	ID     string `json:"id,omitempty"`
+	Server string `json:"server"`
+	Tool   string `json:"tool"`
+	// Project is the working directory of the session.
+	Project string `json:"project,omitempty"`
+	Session string `json:"session,omitempty"`
+	// DurationMS is how long the call took, when Claude Code reports it.
+	DurationMS int64 `json:"ms,omitempty"`
+	Error      bool  `json:"error,omitempty"`
+	// Root is the transcript directory of the session (e.g.
+	// ~/.claude/projects), which tells the profiles sharing the log apart.
+	Root string `json:"root,omitempty"`
+}
+
+// DefaultPath returns the default usage log path, in the user config
+// directory (e.g. ~/.config/mcp-tidy/usage.jsonl on Linux).
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		homeDir, _ := os.UserHomeDir()
+		dir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(dir, "mcp-tidy", FileName)
+}
+
+// HookInput is the JSON Claude Code passes to a PostToolUse or
+// PostToolUseFailure hook on stdin.
+// Only the fields that are recorded are decoded.
+type HookInput struct {
+	SessionID      string          `json:"session_id"`
+	TranscriptPath string          `json:"transcript_path"`
+	Cwd            string          `json:"cwd"`
+	HookEventName  string          `json:"hook_event_name"`
+	ToolName       string          `json:"tool_name"`
+	ToolUseID      string          `json:"tool_use_id"`
+	ToolResponse   json.RawMessage `json:"tool_response"`
+	Error          string          `json:"error"`
+	DurationMS     int64           `json:"duration_ms"`
+}
+
+// ReadHookInput decodes the hook JSON from r.
+func ReadHookInput(r io.Reader) (*HookInput, error) {
+	var in HookInput
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return nil, fmt.Errorf("failed to parse hook input: %w", err)
+	}
+	return &in, nil
+}
+
+// Record returns the record of the call made at t, or false if the tool is
+// not an MCP tool.
+func (in *HookInput) Record(t time.Time) (*Record, bool) {
+	server, tool, ok := transcript.ExtractServerName(in.ToolName)
+	if !ok {
+		return nil, false
+	}
+	r := &Record{
+		Time:       t.UTC(),
+		ID:         in.ToolUseID,
+		Server:     server,
+		Tool:       tool,
+		Project:    in.Cwd,
+		Session:    in.SessionID,
+		DurationMS: in.DurationMS,
+		Error:      in.failed(),
+	}
+	if in.TranscriptPath != "" {
+		r.Root = filepath.Dir(filepath.Dir(in.TranscriptPath))
+	}
+	return r, true
+}
+
+// failed reports whether the call failed: a PostToolUseFailure event, or a
+// tool response flagged as an MCP error result.
+func (in *HookInput) failed() bool {
+	if in.HookEventName == "PostToolUseFailure" || in.Error != "" {
+		return true
+	}
+	var response struct {
+		IsError      bool `json:"isError"`
+		IsErrorSnake bool `json:"is_error"`
+	}
+	if json.Unmarshal(in.ToolResponse, &response) != nil {
+		return false
+	}
+	return response.IsError || response.IsErrorSnake
+}
+
+// Append adds a record to the log, creating it if needed, and rotates the
+// log once it reaches MaxSize. Each record is written with a single append,
+// so hooks running at the same time don't interleave their lines.
+func Append(path string, record *Record) error {
+	return appendRecord(path, record, MaxSize)
+}
+
+func appendRecord(path string, record *Record, maxSize int64) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage record: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create usage log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open usage log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write usage log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to stat usage log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write usage log: %w", err)
+	}
+
+	// Another hook may have rotated the log since it was opened; rotating
+	// again would replace the records it just rotated
+	if info.Size() < maxSize {
+		return nil
+	}
+	if current, err := os.Stat(path); err != nil || !os.SameFile(info, current) {
+		return nil
+	}
+	if err := os.Rename(path, rotatedPath(path)); err != nil {
+		return fmt.Errorf("failed to rotate usage log: %w", err)
+	}
+	return nil
+}
+
+// rotatedPath returns the path the records of a full log move to.
+func rotatedPath(path string) string {
+	return path + ".1"
+}
+
+// Log reads the usage log as a usage source.
+type Log struct {
+	Path string
+	// Roots limits the calls to sessions whose transcripts are in one of
+	// these directories. Records without a root are always included.
+	// Nil means every call.
+	Roots []string
+}
+
+// Name describes the source for messages.
+func (l Log) Name() string {
+	return "usage log " + l.Path
+}
+
+// Calls yields the calls of the log, those of its rotated copy first. A
+// missing log yields an error wrapping fs.ErrNotExist. Corrupted lines, such
+// as one cut off by a crash, are skipped.
+func (l Log) Calls(ctx context.Context) iter.Seq2[types.ToolCall, error] {
+	return func(yield func(types.ToolCall, error) bool) {
+		roots := make([]string, 0, len(l.Roots))
+		for _, root := range l.Roots {
+			roots = append(roots, filepath.Clean(root))
+		}
+
+		found := false
+		for _, path := range []string{rotatedPath(l.Path), l.Path} {
+			f, err := os.Open(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				yield(types.ToolCall{}, fmt.Errorf("failed to open usage log: %w", err))
+				return
+			}
+			found = true
+			ok := l.scan(ctx, f, roots, yield)
+			_ = f.Close()
+			if !ok {
+				return
+			}
+		}
+		if !found {
+			yield(types.ToolCall{}, fmt.Errorf("failed to open usage log: %w", fs.ErrNotExist))
+		}
+	}
+}
+
+// scan yields the calls of one log file. It reports whether to go on.
+func (l Log) scan(ctx context.Context, r io.Reader, roots []string, yield func(types.ToolCall, error) bool) bool {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			yield(types.ToolCall{}, err)
+			return false
+		}
+		var record Record
+		if json.Unmarshal(scanner.Bytes(), &record) != nil || record.Server == "" {
+			continue
+		}
+		if l.Roots != nil && record.Root != "" && !slices.Contains(roots, filepath.Clean(record.Root)) {
+			continue
+		}
+		call := types.ToolCall{ID: record.ID, ServerName: record.Server, ToolName: record.Tool, Timestamp: record.Time}
+		if !yield(call, nil) {
+			return false
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		yield(types.ToolCall{}, fmt.Errorf("failed to read usage log: %w", err))
+		return false
+	}
+	return true
+}
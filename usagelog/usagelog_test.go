@@ -0,0 +1,203 @@
+package usagelog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nnnkkk7/mcp-tidy/types"
+)
+
+func TestHookInput_Record(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		input  string
+		want   *Record
+		wantOK bool
+	}{
+		{
+			name: "mcp tool call",
+			input: `{"session_id":"abc","transcript_path":"/home/me/.claude/projects/-home-me-app/abc.jsonl","cwd":"/home/me/app",
+				"hook_event_name":"PostToolUse","tool_name":"mcp__context7__query-docs","tool_input":{"q":"x"},
+				"tool_response":[{"type":"text","text":"ok"}],"tool_use_id":"toolu_01","duration_ms":120}`,
+			want: &Record{
+				Time: now, ID: "toolu_01", Server: "context7", Tool: "query-docs", Project: "/home/me/app",
+				Session: "abc", DurationMS: 120, Root: "/home/me/.claude/projects",
+			},
+			wantOK: true,
+		},
+		{
+			name:   "error result",
+			input:  `{"tool_name":"mcp__github__create_issue","tool_response":{"isError":true,"content":[]}}`,
+			want:   &Record{Time: now, Server: "github", Tool: "create_issue", Error: true},
+			wantOK: true,
+		},
+		{
+			name:   "failure event",
+			input:  `{"hook_event_name":"PostToolUseFailure","tool_name":"mcp__github__create_issue","error":"timeout"}`,
+			want:   &Record{Time: now, Server: "github", Tool: "create_issue", Error: true},
+			wantOK: true,
+		},
+		{
+			name:  "other tool",
+			input: `{"tool_name":"Read","tool_use_id":"toolu_02"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := ReadHookInput(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ReadHookInput() error = %v", err)
+			}
+			got, ok := in.Record(now)
+			if ok != tt.wantOK {
+				t.Fatalf("Record() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Record() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := ReadHookInput(strings.NewReader("not json")); err == nil {
+		t.Error("ReadHookInput() of invalid JSON error = nil, want an error")
+	}
+}
+
+func TestLog_Calls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp-tidy", FileName)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	records := []Record{
+		{Time: now, ID: "toolu_01", Server: "context7", Tool: "query-docs", Root: "/home/me/.claude/projects"},
+		{Time: now, ID: "toolu_02", Server: "serena", Tool: "find_symbol", Root: "/home/me/.claude-work/projects"},
+		{Time: now, Server: "github", Tool: "create_issue"},
+	}
+	for i := range records {
+		if err := Append(path, &records[i]); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	// A line cut off by a crash is skipped
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"time":"2026-01-02T03:04:05Z","server":"cont`); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat usage log: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("usage log permissions = %o, want 600", perm)
+	}
+
+	tests := []struct {
+		name  string
+		roots []string
+		want  []types.ToolCall
+	}{
+		{
+			name: "every call",
+			want: []types.ToolCall{
+				{ID: "toolu_01", ServerName: "context7", ToolName: "query-docs", Timestamp: now},
+				{ID: "toolu_02", ServerName: "serena", ToolName: "find_symbol", Timestamp: now},
+				{ServerName: "github", ToolName: "create_issue", Timestamp: now},
+			},
+		},
+		{
+			name:  "calls of a profile",
+			roots: []string{"/home/me/.claude/projects/"},
+			want: []types.ToolCall{
+				{ID: "toolu_01", ServerName: "context7", ToolName: "query-docs", Timestamp: now},
+				{ServerName: "github", ToolName: "create_issue", Timestamp: now},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []types.ToolCall
+			for call, err := range (Log{Path: path, Roots: tt.roots}).Calls(context.Background()) {
+				if err != nil {
+					t.Fatalf("Calls() error = %v", err)
+				}
+				got = append(got, call)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Calls() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAppend_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	record := func(id string) *Record {
+		return &Record{Time: now, ID: id, Server: "context7", Tool: "query-docs"}
+	}
+	line, err := json.Marshal(record("toolu_01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The log is rotated every two records; the third rotation replaces the
+	// records of the first
+	for _, id := range []string{"toolu_01", "toolu_02", "toolu_03", "toolu_04", "toolu_05"} {
+		if err := appendRecord(path, record(id), int64(2*(len(line)+1))); err != nil {
+			t.Fatalf("appendRecord() error = %v", err)
+		}
+	}
+
+	var gotIDs []string
+	for call, err := range (Log{Path: path}).Calls(context.Background()) {
+		if err != nil {
+			t.Fatalf("Calls() error = %v", err)
+		}
+		gotIDs = append(gotIDs, call.ID)
+	}
+	if diff := cmp.Diff([]string{"toolu_03", "toolu_04", "toolu_05"}, gotIDs); diff != "" {
+		t.Errorf("Calls() after rotation IDs mismatch (-want +got):\n%s", diff)
+	}
+
+	// Only the rotated copy is left right after a rotation
+	if err := appendRecord(path, record("toolu_06"), int64(2*(len(line)+1))); err != nil {
+		t.Fatalf("appendRecord() error = %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("log after rotation error = %v, want fs.ErrNotExist", err)
+	}
+	gotIDs = nil
+	for call, err := range (Log{Path: path}).Calls(context.Background()) {
+		if err != nil {
+			t.Fatalf("Calls() of the rotated copy error = %v", err)
+		}
+		gotIDs = append(gotIDs, call.ID)
+	}
+	if diff := cmp.Diff([]string{"toolu_05", "toolu_06"}, gotIDs); diff != "" {
+		t.Errorf("Calls() of the rotated copy IDs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLog_Calls_NotExist(t *testing.T) {
+	for _, err := range (Log{Path: filepath.Join(t.TempDir(), FileName)}).Calls(context.Background()) {
+		if !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Calls() error = %v, want fs.ErrNotExist", err)
+		}
+	}
+}